@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Roles carried in access tokens
+const (
+	RoleVolunteer    = "volunteer"
+	RoleOrganization = "organization"
+	RoleAdmin        = "admin"
+)
+
+// ErrInvalidToken is returned when an access token cannot be verified
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Principal identifies the authenticated caller of a request
+type Principal struct {
+	ID    uint   `json:"id"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// Claims is the JWT payload of an access token
+type Claims struct {
+	UserID uint   `json:"uid"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// TokenManager issues and verifies access and refresh tokens
+type TokenManager struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewTokenManager creates a TokenManager that signs access tokens with secret (HS256)
+func NewTokenManager(secret []byte, accessTTL, refreshTTL time.Duration) *TokenManager {
+	return &TokenManager{
+		secret:     secret,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// IssueAccessToken signs a short-lived access token for the principal
+func (m *TokenManager) IssueAccessToken(p Principal) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.accessTTL)
+
+	claims := Claims{
+		UserID: p.ID,
+		Email:  p.Email,
+		Role:   p.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   p.Email,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// ParseAccessToken verifies the signature and expiry of an access token and returns its principal
+func (m *TokenManager) ParseAccessToken(tokenString string) (*Principal, error) {
+	var claims Claims
+	token, err := jwt.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (interface{}, error) {
+		return m.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	if claims.Email == "" || claims.Role == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return &Principal{ID: claims.UserID, Email: claims.Email, Role: claims.Role}, nil
+}
+
+// NewRefreshToken generates an opaque refresh token. Only the returned hash should be persisted.
+func (m *TokenManager) NewRefreshToken() (token string, hash string, expiresAt time.Time, err error) {
+	buf := make([]byte, 32)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", time.Time{}, err
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashRefreshToken(token), time.Now().Add(m.refreshTTL), nil
+}
+
+// AccessTTL returns the lifetime of issued access tokens
+func (m *TokenManager) AccessTTL() time.Duration {
+	return m.accessTTL
+}
+
+// HashRefreshToken returns the SHA-256 digest under which a refresh token is stored
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
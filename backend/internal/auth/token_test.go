@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueAndParseAccessToken(t *testing.T) {
+	tokens := NewTokenManager([]byte("test-secret"), time.Minute, time.Hour)
+
+	token, expiresAt, err := tokens.IssueAccessToken(Principal{ID: 7, Email: "test@volunteer.com", Role: RoleVolunteer})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.True(t, expiresAt.After(time.Now()))
+
+	principal, err := tokens.ParseAccessToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), principal.ID)
+	assert.Equal(t, "test@volunteer.com", principal.Email)
+	assert.Equal(t, RoleVolunteer, principal.Role)
+}
+
+func TestParseAccessTokenWrongSecret(t *testing.T) {
+	issuer := NewTokenManager([]byte("secret-one"), time.Minute, time.Hour)
+	verifier := NewTokenManager([]byte("secret-two"), time.Minute, time.Hour)
+
+	token, _, err := issuer.IssueAccessToken(Principal{ID: 1, Email: "test@org.com", Role: RoleOrganization})
+	assert.NoError(t, err)
+
+	_, err = verifier.ParseAccessToken(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestParseAccessTokenExpired(t *testing.T) {
+	tokens := NewTokenManager([]byte("test-secret"), -time.Minute, time.Hour)
+
+	token, _, err := tokens.IssueAccessToken(Principal{ID: 1, Email: "test@org.com", Role: RoleOrganization})
+	assert.NoError(t, err)
+
+	_, err = tokens.ParseAccessToken(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestNewRefreshToken(t *testing.T) {
+	tokens := NewTokenManager([]byte("test-secret"), time.Minute, time.Hour)
+
+	token, hash, expiresAt, err := tokens.NewRefreshToken()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.Equal(t, HashRefreshToken(token), hash)
+	assert.NotEqual(t, token, hash, "Refresh token should not be stored in plain text")
+	assert.True(t, expiresAt.After(time.Now().Add(59*time.Minute)))
+
+	other, _, _, _ := tokens.NewRefreshToken()
+	assert.NotEqual(t, token, other)
+}
@@ -0,0 +1,403 @@
+// Package config loads the server settings from an optional YAML or TOML file and from
+// environment variables, which take precedence over the file.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every setting of the server
+type Config struct {
+	Server   Server   `yaml:"server" toml:"server"`
+	Database Database `yaml:"database" toml:"database"`
+	CORS     CORS     `yaml:"cors" toml:"cors"`
+	Auth     Auth     `yaml:"auth" toml:"auth"`
+	Admin    Admin    `yaml:"admin" toml:"admin"`
+	Tracing  Tracing  `yaml:"tracing" toml:"tracing"`
+}
+
+// Server holds the address the server listens on and its timeouts. On SIGTERM or interrupt,
+// the server stops accepting connections and waits up to ShutdownTimeout for the requests in
+// flight to finish.
+type Server struct {
+	Host string `yaml:"host" toml:"host" env:"HOST"`
+	Port int    `yaml:"port" toml:"port" env:"PORT"`
+
+	ReadTimeout     Duration `yaml:"read_timeout" toml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout    Duration `yaml:"write_timeout" toml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout     Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+}
+
+// Addr returns the host:port address to listen on
+func (s Server) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// Database drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Database holds the connection and pool settings. Driver selects PostgreSQL, configured with
+// the connection fields, or SQLite, which keeps the database in the file at Path. URL, when
+// set, is used as the connection string instead of either.
+type Database struct {
+	Driver   string `yaml:"driver" toml:"driver" env:"DB_DRIVER"`
+	URL      string `yaml:"url" toml:"url" env:"DATABASE_URL"`
+	Host     string `yaml:"host" toml:"host" env:"DB_HOST"`
+	Port     int    `yaml:"port" toml:"port" env:"DB_PORT"`
+	User     string `yaml:"user" toml:"user" env:"DB_USER"`
+	Password string `yaml:"password" toml:"password" env:"DB_PASSWORD"`
+	Name     string `yaml:"name" toml:"name" env:"DB_NAME"`
+	SSLMode  string `yaml:"sslmode" toml:"sslmode" env:"DB_SSLMODE"`
+	TimeZone string `yaml:"timezone" toml:"timezone" env:"DB_TIMEZONE"`
+	Path     string `yaml:"path" toml:"path" env:"DB_PATH"`
+
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+}
+
+// DSN returns the connection string for the driver
+func (d Database) DSN() string {
+	if d.URL != "" {
+		return d.URL
+	}
+	if d.Driver == DriverSQLite {
+		// Foreign keys are off in SQLite unless enabled on every connection. Immediate
+		// transactions take the write lock up front, so concurrent ones wait for each other
+		// instead of failing when they start writing.
+		return "file:" + d.Path + "?_foreign_keys=on&_busy_timeout=5000&_txlock=immediate"
+	}
+
+	params := []struct{ key, value string }{
+		{"host", d.Host},
+		{"port", strconv.Itoa(d.Port)},
+		{"user", d.User},
+		{"password", d.Password},
+		{"dbname", d.Name},
+		{"sslmode", d.SSLMode},
+		{"TimeZone", d.TimeZone},
+	}
+
+	var parts []string
+	for _, param := range params {
+		if param.value != "" {
+			parts = append(parts, param.key+"="+quoteDSNValue(param.value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteDSNValue quotes a key=value connection string value when it contains spaces, quotes or
+// backslashes
+func quoteDSNValue(value string) string {
+	if !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// CORS holds the origins allowed to call the API from a browser
+type CORS struct {
+	AllowedOrigins   []string `yaml:"allowed_origins" toml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowCredentials bool     `yaml:"allow_credentials" toml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+}
+
+// Auth holds the password hashing and token settings. Without a JWT secret, the server signs
+// tokens with a random key that does not survive a restart.
+type Auth struct {
+	JWTSecret       string   `yaml:"jwt_secret" toml:"jwt_secret" env:"JWT_SECRET"`
+	AccessTokenTTL  Duration `yaml:"access_token_ttl" toml:"access_token_ttl" env:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL Duration `yaml:"refresh_token_ttl" toml:"refresh_token_ttl" env:"REFRESH_TOKEN_TTL"`
+	BcryptCost      int      `yaml:"bcrypt_cost" toml:"bcrypt_cost" env:"BCRYPT_COST"`
+}
+
+// Admin holds the credentials of the admin account created on first startup
+type Admin struct {
+	Email    string `yaml:"email" toml:"email" env:"ADMIN_EMAIL"`
+	Password string `yaml:"password" toml:"password" env:"ADMIN_PASSWORD"`
+}
+
+// Tracing exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+// Tracing selects where OpenTelemetry spans are sent: nowhere, standard output, the file at
+// File as JSON lines, or the OTLP/HTTP collector at Endpoint. SampleRatio is the share of traces
+// started by the server that are recorded; traces started by a caller follow its decision.
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER"`
+	File        string  `yaml:"file" toml:"file" env:"TRACING_FILE"`
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint" env:"TRACING_ENDPOINT"`
+	ServiceName string  `yaml:"service_name" toml:"service_name" env:"TRACING_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// Duration is a time.Duration written as a string such as "15m" or "168h"
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText parses the duration with time.ParseDuration
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+// MarshalText formats the duration with time.Duration.String
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// minSecretLength is the minimum length of a configured JWT secret, in bytes
+const minSecretLength = 32
+
+// Default returns the settings used for anything the file and the environment leave unset
+func Default() Config {
+	return Config{
+		Server: Server{
+			Port:            8080,
+			ReadTimeout:     Duration{15 * time.Second},
+			WriteTimeout:    Duration{30 * time.Second},
+			IdleTimeout:     Duration{2 * time.Minute},
+			ShutdownTimeout: Duration{30 * time.Second},
+		},
+		Database: Database{
+			Driver:          DriverPostgres,
+			Host:            "localhost",
+			Port:            5432,
+			User:            "postgres",
+			Name:            "Helperhub",
+			SSLMode:         "prefer",
+			TimeZone:        "Asia/Shanghai",
+			Path:            "helperhub.db",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: Duration{30 * time.Minute},
+			ConnMaxIdleTime: Duration{5 * time.Minute},
+		},
+		CORS: CORS{
+			AllowedOrigins: []string{"http://localhost:5173"},
+		},
+		Auth: Auth{
+			AccessTokenTTL:  Duration{15 * time.Minute},
+			RefreshTokenTTL: Duration{7 * 24 * time.Hour},
+			BcryptCost:      bcrypt.DefaultCost,
+		},
+		Tracing: Tracing{
+			Exporter:    ExporterNone,
+			File:        "traces.jsonl",
+			Endpoint:    "http://localhost:4318/v1/traces",
+			ServiceName: "helperhub",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Load returns the default settings overridden by the file at path, when path is not empty,
+// and then by the environment. The file is YAML or TOML depending on its extension.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := loadFile(&cfg, path); err != nil {
+			return nil, err
+		}
+	}
+	if err := loadEnv(reflect.ValueOf(&cfg).Elem()); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(cfg)
+	case ".toml":
+		err = toml.NewDecoder(bytes.NewReader(content)).DisallowUnknownFields().Decode(cfg)
+	default:
+		return fmt.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv sets every field with an env tag whose environment variable is set
+func loadEnv(v reflect.Value) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct && field.Type() != reflect.TypeOf(Duration{}) {
+			if err := loadEnv(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := v.Type().Field(i).Tag.Get("env")
+		value, ok := os.LookupEnv(name)
+		if name == "" || !ok {
+			continue
+		}
+		if err := setField(field, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func setField(field reflect.Value, value string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+	case int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		field.SetInt(int64(n))
+	case float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetFloat(f)
+	case bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		field.SetBool(b)
+	case []string:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	case Duration:
+		var d Duration
+		if err := d.UnmarshalText([]byte(value)); err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(d))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
+
+// Validate returns every problem with the settings, joined into one error
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server port %d is out of range", c.Server.Port)
+	check(c.Server.ReadTimeout.Duration > 0, "server read_timeout must be positive")
+	check(c.Server.WriteTimeout.Duration > 0, "server write_timeout must be positive")
+	check(c.Server.IdleTimeout.Duration > 0, "server idle_timeout must be positive")
+	check(c.Server.ShutdownTimeout.Duration > 0, "server shutdown_timeout must be positive")
+
+	db := c.Database
+	switch {
+	case db.Driver != DriverPostgres && db.Driver != DriverSQLite:
+		check(false, "database driver %q must be %q or %q", db.Driver, DriverPostgres, DriverSQLite)
+	case db.URL != "":
+	case db.Driver == DriverSQLite:
+		check(db.Path != "", "database path is required for SQLite")
+	default:
+		check(db.Host != "", "database host is required")
+		check(db.Name != "", "database name is required")
+		check(db.Port > 0 && db.Port <= 65535, "database port %d is out of range", db.Port)
+	}
+	check(db.MaxOpenConns >= 0, "database max_open_conns cannot be negative")
+	check(db.MaxIdleConns >= 0, "database max_idle_conns cannot be negative")
+	check(db.MaxOpenConns == 0 || db.MaxIdleConns <= db.MaxOpenConns,
+		"database max_idle_conns (%d) cannot exceed max_open_conns (%d)", db.MaxIdleConns, db.MaxOpenConns)
+	check(db.ConnMaxLifetime.Duration >= 0, "database conn_max_lifetime cannot be negative")
+	check(db.ConnMaxIdleTime.Duration >= 0, "database conn_max_idle_time cannot be negative")
+
+	check(len(c.CORS.AllowedOrigins) > 0, "at least one CORS origin is required")
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			// Browsers reject credentialed responses that allow every origin
+			check(!c.CORS.AllowCredentials, "CORS origin \"*\" cannot be combined with allow_credentials")
+			continue
+		}
+		u, err := url.Parse(origin)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.Path == "",
+			"CORS origin %q must be \"*\" or a scheme and host such as https://example.com", origin)
+	}
+
+	auth := c.Auth
+	check(auth.JWTSecret == "" || len(auth.JWTSecret) >= minSecretLength, "JWT secret must be at least %d bytes", minSecretLength)
+	check(auth.AccessTokenTTL.Duration > 0, "access token TTL must be positive")
+	check(auth.RefreshTokenTTL.Duration > auth.AccessTokenTTL.Duration, "refresh token TTL must be longer than the access token TTL")
+	check(auth.BcryptCost >= bcrypt.MinCost && auth.BcryptCost <= bcrypt.MaxCost,
+		"bcrypt cost %d must be between %d and %d", auth.BcryptCost, bcrypt.MinCost, bcrypt.MaxCost)
+
+	check((c.Admin.Email == "") == (c.Admin.Password == ""), "admin email and password must be set together")
+
+	tracing := c.Tracing
+	switch tracing.Exporter {
+	case ExporterNone, ExporterStdout:
+	case ExporterFile:
+		check(tracing.File != "", "tracing file is required for the file exporter")
+	case ExporterOTLP:
+		u, err := url.Parse(tracing.Endpoint)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"tracing endpoint %q must be an http or https URL", tracing.Endpoint)
+	default:
+		check(false, "tracing exporter %q must be %q, %q, %q or %q", tracing.Exporter, ExporterNone, ExporterStdout, ExporterFile, ExporterOTLP)
+	}
+	check(tracing.ServiceName != "", "tracing service_name is required")
+	check(tracing.SampleRatio >= 0 && tracing.SampleRatio <= 1, "tracing sample_ratio %g must be between 0 and 1", tracing.SampleRatio)
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// unsetEnv removes environment variables for the duration of a test, such as DB_DRIVER when
+// the tests run on SQLite
+func unsetEnv(t *testing.T, names ...string) {
+	for _, name := range names {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	unsetEnv(t, "DB_DRIVER", "DATABASE_URL")
+	cfg, err := Load("")
+	require.NoError(t, err)
+
+	assert.Equal(t, ":8080", cfg.Server.Addr())
+	assert.Equal(t, "host=localhost port=5432 user=postgres dbname=Helperhub sslmode=prefer TimeZone=Asia/Shanghai", cfg.Database.DSN())
+	assert.Equal(t, 15*time.Minute, cfg.Auth.AccessTokenTTL.Duration)
+	assert.False(t, cfg.CORS.AllowCredentials)
+}
+
+func TestLoadFile(t *testing.T) {
+	unsetEnv(t, "DB_DRIVER", "DATABASE_URL")
+	files := map[string]string{
+		"config.yaml": `
+server:
+  port: 9000
+database:
+  password: it's secret
+  max_open_conns: 10
+  conn_max_lifetime: 1h
+cors:
+  allowed_origins: [https://helperhub.example]
+auth:
+  refresh_token_ttl: 24h
+  bcrypt_cost: 12
+`,
+		"config.toml": `
+[server]
+port = 9000
+
+[database]
+password = "it's secret"
+max_open_conns = 10
+conn_max_lifetime = "1h"
+
+[cors]
+allowed_origins = ["https://helperhub.example"]
+
+[auth]
+refresh_token_ttl = "24h"
+bcrypt_cost = 12
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := Load(writeFile(t, name, content))
+			require.NoError(t, err)
+
+			assert.Equal(t, 9000, cfg.Server.Port)
+			assert.Contains(t, cfg.Database.DSN(), `password='it\'s secret'`)
+			assert.Equal(t, 10, cfg.Database.MaxOpenConns)
+			assert.Equal(t, 5, cfg.Database.MaxIdleConns)
+			assert.Equal(t, time.Hour, cfg.Database.ConnMaxLifetime.Duration)
+			assert.Equal(t, []string{"https://helperhub.example"}, cfg.CORS.AllowedOrigins)
+			assert.Equal(t, 24*time.Hour, cfg.Auth.RefreshTokenTTL.Duration)
+			assert.Equal(t, 15*time.Minute, cfg.Auth.AccessTokenTTL.Duration)
+			assert.Equal(t, 12, cfg.Auth.BcryptCost)
+		})
+	}
+}
+
+func TestLoadExample(t *testing.T) {
+	unsetEnv(t, "DB_DRIVER", "DATABASE_URL")
+	cfg, err := Load("../../config.example.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, Default(), *cfg)
+}
+
+func TestLoadEnv(t *testing.T) {
+	path := writeFile(t, "config.yaml", "server:\n  port: 9000\ndatabase:\n  name: fromfile\n")
+	t.Setenv("PORT", "9090")
+	t.Setenv("DATABASE_URL", "postgres://helperhub@db/helperhub")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example, https://b.example")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	t.Setenv("ACCESS_TOKEN_TTL", "5m")
+	t.Setenv("ADMIN_EMAIL", "admin@helperhub.com")
+	t.Setenv("ADMIN_PASSWORD", "password")
+	t.Setenv("TRACING_SAMPLE_RATIO", "0.25")
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	// The environment wins over the file, which wins over the defaults
+	assert.Equal(t, 9090, cfg.Server.Port)
+	assert.Equal(t, "fromfile", cfg.Database.Name)
+	assert.Equal(t, "postgres://helperhub@db/helperhub", cfg.Database.DSN())
+	assert.Equal(t, []string{"https://a.example", "https://b.example"}, cfg.CORS.AllowedOrigins)
+	assert.True(t, cfg.CORS.AllowCredentials)
+	assert.Equal(t, 5*time.Minute, cfg.Auth.AccessTokenTTL.Duration)
+	assert.Equal(t, "admin@helperhub.com", cfg.Admin.Email)
+	assert.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", "/tmp/helperhub.db")
+	t.Setenv("DB_NAME", "")
+
+	cfg, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, "file:/tmp/helperhub.db?_foreign_keys=on&_busy_timeout=5000&_txlock=immediate", cfg.Database.DSN())
+}
+
+func TestLoadInvalid(t *testing.T) {
+	t.Run("Bad Env Value", func(t *testing.T) {
+		t.Setenv("DB_MAX_OPEN_CONNS", "many")
+		_, err := Load("")
+		assert.ErrorContains(t, err, "DB_MAX_OPEN_CONNS")
+	})
+
+	t.Run("Unknown File Setting", func(t *testing.T) {
+		_, err := Load(writeFile(t, "config.yaml", "server:\n  prot: 9000\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Unknown File Type", func(t *testing.T) {
+		_, err := Load(writeFile(t, "config.json", "{}"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Missing File", func(t *testing.T) {
+		_, err := Load(filepath.Join(t.TempDir(), "config.yaml"))
+		assert.Error(t, err)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]func(*Config){
+		"Port":                   func(c *Config) { c.Server.Port = 70000 },
+		"Write Timeout":          func(c *Config) { c.Server.WriteTimeout = Duration{} },
+		"Database Name":          func(c *Config) { c.Database.Name = "" },
+		"Database Driver":        func(c *Config) { c.Database.Driver = "mysql" },
+		"SQLite Path":            func(c *Config) { c.Database.Driver, c.Database.Path = DriverSQLite, "" },
+		"Idle Above Open":        func(c *Config) { c.Database.MaxIdleConns = 50 },
+		"Wildcard Credentials":   func(c *Config) { c.CORS.AllowedOrigins, c.CORS.AllowCredentials = []string{"*"}, true },
+		"Origin With Path":       func(c *Config) { c.CORS.AllowedOrigins = []string{"http://localhost:5173/app"} },
+		"Short Secret":           func(c *Config) { c.Auth.JWTSecret = "secret" },
+		"Refresh Before Access":  func(c *Config) { c.Auth.RefreshTokenTTL = Duration{time.Minute} },
+		"Bcrypt Cost":            func(c *Config) { c.Auth.BcryptCost = 40 },
+		"Admin Without Password": func(c *Config) { c.Admin.Email = "admin@helperhub.com" },
+		"Tracing Exporter":       func(c *Config) { c.Tracing.Exporter = "jaeger" },
+		"Tracing Endpoint":       func(c *Config) { c.Tracing.Exporter, c.Tracing.Endpoint = ExporterOTLP, "localhost:4318" },
+		"Sample Ratio":           func(c *Config) { c.Tracing.SampleRatio = 1.5 },
+	}
+
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := Default()
+			change(&cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+
+	t.Run("Every Problem", func(t *testing.T) {
+		cfg := Default()
+		cfg.Server.Port = 0
+		cfg.Auth.BcryptCost = 1
+		err := cfg.Validate()
+		assert.ErrorContains(t, err, "server port")
+		assert.ErrorContains(t, err, "bcrypt cost")
+	})
+
+	t.Run("Wildcard", func(t *testing.T) {
+		cfg := Default()
+		cfg.CORS.AllowedOrigins = []string{"*"}
+		assert.NoError(t, cfg.Validate())
+	})
+}
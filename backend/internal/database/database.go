@@ -0,0 +1,112 @@
+// Package database picks the GORM dialector for the configured database driver. SQLite
+// connections get the SQL functions the queries rely on that SQLite does not have built in.
+package database
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"math"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/prathamrao021/HelperHub/internal/config"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// sqliteDriver is the name of the SQLite driver with the functions registered
+const sqliteDriver = "sqlite3_helperhub"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{ConnectHook: registerFunctions})
+}
+
+// Dialector returns the dialector that opens the configured database
+func Dialector(cfg config.Database) gorm.Dialector {
+	if cfg.Driver == config.DriverSQLite {
+		return sqlite.New(sqlite.Config{DriverName: sqliteDriver, DSN: cfg.DSN()})
+	}
+	return postgres.Open(cfg.DSN())
+}
+
+// IsSQLite reports whether db is a SQLite database
+func IsSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == "sqlite"
+}
+
+// registerFunctions adds the math functions used to compute distances, and search_rank, to a
+// new connection
+func registerFunctions(conn *sqlite3.SQLiteConn) error {
+	functions := map[string]interface{}{
+		"radians":     unary(func(x float64) float64 { return x * math.Pi / 180 }),
+		"sin":         unary(math.Sin),
+		"cos":         unary(math.Cos),
+		"asin":        unary(math.Asin),
+		"sqrt":        unary(math.Sqrt),
+		"power":       power,
+		"search_rank": searchRank,
+	}
+	for name, impl := range functions {
+		if err := conn.RegisterFunc(name, impl, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// number converts a SQL argument to a float64, reporting false for NULL
+func number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// unary wraps a math function into a SQL function that is NULL for NULL arguments, as in
+// PostgreSQL
+func unary(f func(float64) float64) func(interface{}) interface{} {
+	return func(value interface{}) interface{} {
+		x, ok := number(value)
+		if !ok {
+			return nil
+		}
+		return f(x)
+	}
+}
+
+func power(base, exponent interface{}) interface{} {
+	x, xOK := number(base)
+	y, yOK := number(exponent)
+	if !xOK || !yOK {
+		return nil
+	}
+	return math.Pow(x, y)
+}
+
+// searchRank scores a full-text match from matchinfo(table, 'pcx'), counting the matches of
+// each phrase in each column times the weight of the column
+func searchRank(matchinfo []byte, weights ...float64) float64 {
+	values := make([]uint32, len(matchinfo)/4)
+	for i := range values {
+		values[i] = binary.NativeEndian.Uint32(matchinfo[i*4:])
+	}
+	if len(values) < 2 {
+		return 0
+	}
+
+	phrases, columns := int(values[0]), int(values[1])
+	rank := 0.0
+	for p := 0; p < phrases; p++ {
+		for c := 0; c < columns && c < len(weights); c++ {
+			i := 2 + 3*(p*columns+c)
+			if i < len(values) {
+				rank += weights[c] * float64(values[i])
+			}
+		}
+	}
+	return rank
+}
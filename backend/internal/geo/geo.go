@@ -0,0 +1,80 @@
+// Package geo turns free-text locations into coordinates and measures the distance between
+// them. Geocoders are pluggable; StaticGeocoder answers from a fixed list of places and needs
+// no network access, which makes it suitable for tests and offline deployments.
+package geo
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+)
+
+// EarthRadiusKm is the mean radius of the Earth
+const EarthRadiusKm = 6371.0
+
+// ErrNotFound is returned when a geocoder does not know a location
+var ErrNotFound = errors.New("location not found")
+
+// Point is a position in decimal degrees
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Geocoder looks up the coordinates of a free-text location
+type Geocoder interface {
+	Geocode(ctx context.Context, location string) (Point, error)
+}
+
+// StaticGeocoder geocodes from a fixed list of places. A location that is not listed as a
+// whole is looked up again without its leading comma separated parts, so that
+// "12 Main St, Gainesville, FL" resolves to "Gainesville, FL".
+type StaticGeocoder struct {
+	places map[string]Point
+}
+
+// NewStaticGeocoder creates a geocoder for the given places. Place names are matched
+// case-insensitively.
+func NewStaticGeocoder(places map[string]Point) *StaticGeocoder {
+	normalized := make(map[string]Point, len(places))
+	for name, point := range places {
+		normalized[normalizeLocation(name)] = point
+	}
+	return &StaticGeocoder{places: normalized}
+}
+
+// Geocode implements Geocoder
+func (g *StaticGeocoder) Geocode(ctx context.Context, location string) (Point, error) {
+	parts := strings.Split(normalizeLocation(location), ",")
+	for i := range parts {
+		name := strings.TrimSpace(strings.Join(parts[i:], ","))
+		if point, ok := g.places[name]; ok && name != "" {
+			return point, nil
+		}
+	}
+	return Point{}, ErrNotFound
+}
+
+// normalizeLocation lowercases a location and tidies the spacing around its parts
+func normalizeLocation(location string) string {
+	parts := strings.Split(strings.ToLower(location), ",")
+	for i, part := range parts {
+		parts[i] = strings.Join(strings.Fields(part), " ")
+	}
+	return strings.Join(parts, ",")
+}
+
+// DistanceKm returns the great-circle distance between two points
+func DistanceKm(a Point, b Point) float64 {
+	lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+	dLat := radians(b.Latitude - a.Latitude)
+	dLng := radians(b.Longitude - a.Longitude)
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * EarthRadiusKm * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
@@ -0,0 +1,37 @@
+package geo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticGeocoder(t *testing.T) {
+	geocoder := NewStaticGeocoder(map[string]Point{
+		"Gainesville, FL": {Latitude: 29.6516, Longitude: -82.3248},
+	})
+
+	point, err := geocoder.Geocode(context.Background(), "gainesville,  fl")
+	assert.NoError(t, err)
+	assert.Equal(t, 29.6516, point.Latitude)
+
+	// Street addresses resolve to their city
+	point, err = geocoder.Geocode(context.Background(), "1 University Ave, Gainesville, FL")
+	assert.NoError(t, err)
+	assert.Equal(t, -82.3248, point.Longitude)
+
+	_, err = geocoder.Geocode(context.Background(), "Orlando, FL")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = geocoder.Geocode(context.Background(), "")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDistanceKm(t *testing.T) {
+	gainesville := Places["Gainesville, FL"]
+	orlando := Places["Orlando, FL"]
+
+	assert.InDelta(t, 154, DistanceKm(gainesville, orlando), 5)
+	assert.InDelta(t, DistanceKm(gainesville, orlando), DistanceKm(orlando, gainesville), 1e-9)
+	assert.Equal(t, 0.0, DistanceKm(gainesville, gainesville))
+}
@@ -0,0 +1,25 @@
+package geo
+
+// Places is a small built-in list of US cities for StaticGeocoder, used when no other
+// geocoder is configured. Each city is listed with its state abbreviation.
+var Places = map[string]Point{
+	"Atlanta, GA":       {Latitude: 33.7490, Longitude: -84.3880},
+	"Austin, TX":        {Latitude: 30.2672, Longitude: -97.7431},
+	"Boston, MA":        {Latitude: 42.3601, Longitude: -71.0589},
+	"Chicago, IL":       {Latitude: 41.8781, Longitude: -87.6298},
+	"Dallas, TX":        {Latitude: 32.7767, Longitude: -96.7970},
+	"Denver, CO":        {Latitude: 39.7392, Longitude: -104.9903},
+	"Gainesville, FL":   {Latitude: 29.6516, Longitude: -82.3248},
+	"Houston, TX":       {Latitude: 29.7604, Longitude: -95.3698},
+	"Jacksonville, FL":  {Latitude: 30.3322, Longitude: -81.6557},
+	"Los Angeles, CA":   {Latitude: 34.0522, Longitude: -118.2437},
+	"Miami, FL":         {Latitude: 25.7617, Longitude: -80.1918},
+	"New York, NY":      {Latitude: 40.7128, Longitude: -74.0060},
+	"Orlando, FL":       {Latitude: 28.5383, Longitude: -81.3792},
+	"Philadelphia, PA":  {Latitude: 39.9526, Longitude: -75.1652},
+	"Phoenix, AZ":       {Latitude: 33.4484, Longitude: -112.0740},
+	"San Francisco, CA": {Latitude: 37.7749, Longitude: -122.4194},
+	"Seattle, WA":       {Latitude: 47.6062, Longitude: -122.3321},
+	"Tampa, FL":         {Latitude: 27.9506, Longitude: -82.4572},
+	"Washington, DC":    {Latitude: 38.9072, Longitude: -77.0369},
+}
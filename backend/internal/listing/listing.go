@@ -0,0 +1,484 @@
+// Package listing parses the sorting, filtering and pagination options of list endpoints and
+// applies them to GORM queries, or to slices in memory. Every endpoint declares the fields it
+// can be sorted and filtered by in a Spec, so clients only ever reach whitelisted columns.
+//
+// Query parameters:
+//
+//	sort=-created_at,id          fields to sort by, "-" for descending
+//	filter=status:eq:Accepted    repeatable; ops are eq, ne, lt, lte, gt, gte, in (values split by |) and like
+//	limit=20                     page size
+//	offset=40                    offset pagination
+//	cursor=...                   cursor pagination, from the next_cursor of the previous page
+//
+// Pages are cursor based unless an offset is given or the sort includes a field that cursors
+// cannot track, such as a computed or nullable one.
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Default and maximum page sizes, used when a Spec does not set its own
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+const dateLayout = "2006-01-02"
+
+// Type tells how to parse the filter values and cursor positions of a field
+type Type int
+
+// Field types
+const (
+	String Type = iota
+	Number
+	Bool
+	Date // YYYY-MM-DD
+	Time // RFC 3339
+)
+
+// Field is a column clients can sort and filter by
+type Field struct {
+	Column   string // Column in the query, such as "applications.created_at"
+	Type     Type
+	Computed bool // An alias from the select list: it can be sorted by, but not filtered or paged with a cursor
+	Nullable bool // Sorted with NULLs last, and not paged with a cursor
+}
+
+// Spec declares the fields of a list endpoint. Field names are the names of the fields in the
+// response, which is how cursors find the position of the last item of a page.
+type Spec struct {
+	Fields       map[string]Field
+	DefaultSort  string // Sort used when the request has none, such as "-created_at"
+	Key          string // Unique, non-null field that breaks ties between equal sort values
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// With returns a copy of the spec with one more field
+func (s Spec) With(name string, field Field) Spec {
+	fields := make(map[string]Field, len(s.Fields)+1)
+	for n, f := range s.Fields {
+		fields[n] = f
+	}
+	fields[name] = field
+	s.Fields = fields
+	return s
+}
+
+// Sort orders a list by one field
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Filter keeps the rows whose field compares to the values with the operator
+type Filter struct {
+	Field  string
+	Op     string
+	Values []interface{}
+}
+
+var filterOperators = map[string]string{
+	"eq":   "=",
+	"ne":   "<>",
+	"lt":   "<",
+	"lte":  "<=",
+	"gt":   ">",
+	"gte":  ">=",
+	"in":   "IN",
+	"like": "LIKE",
+}
+
+// Options are the parsed list options of a request
+type Options struct {
+	Sort    []Sort
+	Filters []Filter
+	Limit   int
+	Offset  int
+	After   []interface{} // Sort values of the last item of the previous page, from the cursor
+
+	spec       Spec
+	sortString string
+	useOffset  bool
+}
+
+// cursor is the decoded form of a cursor query parameter. The sort is kept so a cursor is not
+// used with a different order than the one it was made for.
+type cursor struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+}
+
+// Parse reads the list options from query parameters. Errors describe what is wrong with the
+// request and are meant to be returned to the client.
+func Parse(values url.Values, spec Spec) (Options, error) {
+	options := Options{spec: spec}
+
+	sortString := values.Get("sort")
+	if sortString == "" {
+		sortString = spec.DefaultSort
+	}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(sortString, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		sort := Sort{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if _, ok := spec.Fields[sort.Field]; !ok {
+			return options, fmt.Errorf("cannot sort by %q", sort.Field)
+		}
+		if seen[sort.Field] {
+			continue
+		}
+		seen[sort.Field] = true
+		options.Sort = append(options.Sort, sort)
+	}
+	if spec.Key != "" && !seen[spec.Key] {
+		options.Sort = append(options.Sort, Sort{Field: spec.Key})
+	}
+	options.sortString = sortString
+
+	for _, expression := range values["filter"] {
+		filter, err := parseFilter(expression, spec)
+		if err != nil {
+			return options, err
+		}
+		options.Filters = append(options.Filters, filter)
+	}
+
+	defaultLimit, maxLimit := spec.DefaultLimit, spec.MaxLimit
+	if defaultLimit == 0 {
+		defaultLimit = DefaultLimit
+	}
+	if maxLimit == 0 {
+		maxLimit = MaxLimit
+	}
+	options.Limit = defaultLimit
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return options, errors.New("limit must be a positive number")
+		}
+		options.Limit = min(n, maxLimit)
+	}
+
+	offset, cursorString := values.Get("offset"), values.Get("cursor")
+	if offset != "" && cursorString != "" {
+		return options, errors.New("use either offset or cursor, not both")
+	}
+	options.useOffset = offset != "" || !options.cursorable()
+	if offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			return options, errors.New("offset must be zero or a positive number")
+		}
+		options.Offset = n
+	}
+	if cursorString != "" {
+		if options.useOffset {
+			return options, errors.New("cursor cannot be used with this sort, use offset instead")
+		}
+		after, err := options.decodeCursor(cursorString)
+		if err != nil {
+			return options, err
+		}
+		options.After = after
+	}
+
+	return options, nil
+}
+
+func parseFilter(expression string, spec Spec) (Filter, error) {
+	parts := strings.SplitN(expression, ":", 3)
+	if len(parts) != 3 {
+		return Filter{}, fmt.Errorf("filter %q must look like field:op:value", expression)
+	}
+	name, op, raw := parts[0], parts[1], parts[2]
+
+	field, ok := spec.Fields[name]
+	if !ok || field.Computed {
+		return Filter{}, fmt.Errorf("cannot filter by %q", name)
+	}
+	if _, ok := filterOperators[op]; !ok {
+		return Filter{}, fmt.Errorf("unknown filter operator %q", op)
+	}
+	if op == "like" && field.Type != String {
+		return Filter{}, fmt.Errorf("like only works on text fields, not %q", name)
+	}
+
+	rawValues := []string{raw}
+	if op == "in" {
+		rawValues = strings.Split(raw, "|")
+	}
+	filter := Filter{Field: name, Op: op}
+	for _, r := range rawValues {
+		value, err := parseValue(field.Type, r)
+		if err != nil {
+			return Filter{}, fmt.Errorf("invalid value %q for %q", r, name)
+		}
+		filter.Values = append(filter.Values, value)
+	}
+	return filter, nil
+}
+
+// parseValue converts a query parameter to the Go value of a field type
+func parseValue(t Type, raw string) (interface{}, error) {
+	switch t {
+	case Number:
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return n, nil
+		}
+		return strconv.ParseFloat(raw, 64)
+	case Bool:
+		return strconv.ParseBool(raw)
+	case Date:
+		return time.Parse(dateLayout, raw)
+	case Time:
+		return time.Parse(time.RFC3339Nano, raw)
+	default:
+		return raw, nil
+	}
+}
+
+// arg returns the query argument for a value of the field. Dates are passed as YYYY-MM-DD
+// text, which PostgreSQL reads as a date and which compares correctly with the dates SQLite
+// stores as text.
+func (f Field) arg(value interface{}) interface{} {
+	if t, ok := value.(time.Time); ok && f.Type == Date {
+		return t.Format(dateLayout)
+	}
+	return value
+}
+
+// cursorable reports whether the sort only has fields a cursor can track
+func (o Options) cursorable() bool {
+	for _, s := range o.Sort {
+		field := o.spec.Fields[s.Field]
+		if field.Computed || field.Nullable {
+			return false
+		}
+	}
+	return len(o.Sort) > 0
+}
+
+// SortsBy reports whether the list is sorted by the field
+func (o Options) SortsBy(name string) bool {
+	for _, s := range o.Sort {
+		if s.Field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter narrows a query by the filters of the request. The query it returns can be used both
+// to count the rows and, through Paginate, to fetch the page.
+func (o Options) Filter(query *gorm.DB) *gorm.DB {
+	for _, f := range o.Filters {
+		field := o.spec.Fields[f.Field]
+		switch f.Op {
+		case "in":
+			values := make([]interface{}, len(f.Values))
+			for i, value := range f.Values {
+				values[i] = field.arg(value)
+			}
+			query = query.Where(field.Column+" IN ?", values)
+		case "like":
+			query = query.Where("LOWER("+field.Column+") LIKE LOWER(?)", "%"+f.Values[0].(string)+"%")
+		default:
+			query = query.Where(field.Column+" "+filterOperators[f.Op]+" ?", field.arg(f.Values[0]))
+		}
+	}
+	return query.Session(&gorm.Session{})
+}
+
+// Paginate sorts a query and limits it to the page. It fetches one row more than the limit,
+// which tells Page whether there is a next page.
+func (o Options) Paginate(query *gorm.DB) *gorm.DB {
+	if len(o.After) > 0 {
+		condition, args := o.afterCondition()
+		query = query.Where(condition, args...)
+	}
+
+	for _, s := range o.Sort {
+		field := o.spec.Fields[s.Field]
+		order := field.Column + " ASC"
+		if s.Desc {
+			order = field.Column + " DESC"
+		}
+		if field.Nullable {
+			order += " NULLS LAST"
+		}
+		query = query.Order(order)
+	}
+
+	query = query.Limit(o.Limit + 1)
+	if o.useOffset && o.Offset > 0 {
+		query = query.Offset(o.Offset)
+	}
+	return query
+}
+
+// Find counts the rows of a query that pass the filters and fetches the page of them into
+// items, which Page then trims to the limit
+func (o Options) Find(query *gorm.DB, items interface{}) (int64, error) {
+	query = o.Filter(query)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, o.Paginate(query).Find(items).Error
+}
+
+// afterCondition keeps the rows that sort after the cursor:
+// (a > x) OR (a = x AND b > y) OR (a = x AND b = y AND c > z) ...
+func (o Options) afterCondition() (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+	for i, s := range o.Sort {
+		var parts []string
+		for j := 0; j < i; j++ {
+			field := o.spec.Fields[o.Sort[j].Field]
+			parts = append(parts, field.Column+" = ?")
+			args = append(args, field.arg(o.After[j]))
+		}
+		op := " > ?"
+		if s.Desc {
+			op = " < ?"
+		}
+		field := o.spec.Fields[s.Field]
+		parts = append(parts, field.Column+op)
+		args = append(args, field.arg(o.After[i]))
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+// Page is the response envelope of a list endpoint
+type Page struct {
+	Data       interface{} `json:"data"`
+	Total      int64       `json:"total"`
+	Limit      int         `json:"limit"`
+	Offset     *int        `json:"offset,omitempty"` // Set for offset pagination
+	NextCursor string      `json:"next_cursor,omitempty"`
+	Next       string      `json:"next,omitempty"` // Path and query of the next page
+}
+
+// Page builds the envelope for the items fetched with Paginate. items must be a slice of the
+// response type, whose JSON field names match the field names of the spec.
+func (o Options) Page(items interface{}, total int64, requestURL *url.URL) Page {
+	list := reflect.ValueOf(items)
+	hasMore := list.Len() > o.Limit
+	if hasMore {
+		list = list.Slice(0, o.Limit)
+	}
+
+	page := Page{Data: list.Interface(), Total: total, Limit: o.Limit}
+	if o.useOffset {
+		offset := o.Offset
+		page.Offset = &offset
+	}
+	if !hasMore {
+		return page
+	}
+
+	next := requestURL.Query()
+	if o.useOffset {
+		next.Del("cursor")
+		next.Set("offset", strconv.Itoa(o.Offset+o.Limit))
+	} else {
+		page.NextCursor = o.encodeCursor(list.Index(list.Len() - 1))
+		next.Del("offset")
+		next.Set("cursor", page.NextCursor)
+	}
+	page.Next = requestURL.Path + "?" + next.Encode()
+	return page
+}
+
+func (o Options) encodeCursor(item reflect.Value) string {
+	c := cursor{Sort: o.sortString}
+	for _, s := range o.Sort {
+		value, _ := fieldByJSONName(item, s.Field)
+		raw, _ := json.Marshal(value.Interface())
+		c.Values = append(c.Values, raw)
+	}
+	encoded, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func (o Options) decodeCursor(s string) ([]interface{}, error) {
+	invalid := errors.New("invalid cursor")
+
+	decoded, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, invalid
+	}
+	var c cursor
+	if err := json.Unmarshal(decoded, &c); err != nil {
+		return nil, invalid
+	}
+	if c.Sort != o.sortString || len(c.Values) != len(o.Sort) {
+		return nil, errors.New("cursor was made for a different sort")
+	}
+
+	values := make([]interface{}, 0, len(c.Values))
+	for i, raw := range c.Values {
+		field := o.spec.Fields[o.Sort[i].Field]
+		text := string(raw)
+		if field.Type != Number && field.Type != Bool {
+			if err := json.Unmarshal(raw, &text); err != nil {
+				return nil, invalid
+			}
+		}
+		value, err := parseValue(field.Type, text)
+		if err != nil {
+			return nil, invalid
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// fieldByJSONName finds the field of a struct, or of the structs it embeds, with the given JSON
+// name. Names are compared case-insensitively, since some responses spell them "created_At".
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			if found, ok := fieldByJSONName(v.Field(i), name); ok {
+				return found, true
+			}
+			continue
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "" {
+			tag = field.Name
+		}
+		if strings.EqualFold(tag, name) {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
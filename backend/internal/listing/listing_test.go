@@ -0,0 +1,224 @@
+package listing
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var testSpec = Spec{
+	Fields: map[string]Field{
+		"id":         {Column: "items.id", Type: Number},
+		"name":       {Column: "items.name", Type: String},
+		"active":     {Column: "items.active", Type: Bool},
+		"start_date": {Column: "items.start_date", Type: Date},
+		"created_at": {Column: "items.created_at", Type: Time},
+		"score":      {Column: "score", Type: Number, Computed: true},
+	},
+	DefaultSort: "-created_at",
+	Key:         "id",
+}
+
+type testItem struct {
+	ID         uint      `json:"id"`
+	Name       string    `json:"name"`
+	Created_At time.Time `json:"created_At"`
+}
+
+func parse(t *testing.T, query string) Options {
+	values, err := url.ParseQuery(query)
+	require.NoError(t, err)
+	options, err := Parse(values, testSpec)
+	require.NoError(t, err)
+	return options
+}
+
+func dryRun(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func TestParse(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		options := parse(t, "")
+		assert.Equal(t, []Sort{{Field: "created_at", Desc: true}, {Field: "id"}}, options.Sort)
+		assert.Equal(t, DefaultLimit, options.Limit)
+		assert.Empty(t, options.Filters)
+	})
+
+	t.Run("Sort And Limit", func(t *testing.T) {
+		options := parse(t, "sort=name,-id&limit=500")
+		assert.Equal(t, []Sort{{Field: "name"}, {Field: "id", Desc: true}}, options.Sort)
+		assert.Equal(t, MaxLimit, options.Limit)
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		options := parse(t, "filter=name:like:ann&filter=id:in:1|2&filter=active:eq:true&filter=start_date:gte:2025-03-01")
+		require.Len(t, options.Filters, 4)
+		assert.Equal(t, []interface{}{"ann"}, options.Filters[0].Values)
+		assert.Equal(t, []interface{}{int64(1), int64(2)}, options.Filters[1].Values)
+		assert.Equal(t, []interface{}{true}, options.Filters[2].Values)
+		assert.Equal(t, []interface{}{time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}, options.Filters[3].Values)
+	})
+
+	for _, query := range []string{
+		"sort=password",
+		"filter=password:eq:x",
+		"filter=score:gt:1",
+		"filter=name:between:a",
+		"filter=name",
+		"filter=id:like:1",
+		"filter=id:eq:one",
+		"filter=start_date:eq:tomorrow",
+		"limit=0",
+		"offset=-1",
+		"offset=10&cursor=abc",
+		"cursor=abc",
+		"sort=score&cursor=abc",
+	} {
+		t.Run("Invalid "+query, func(t *testing.T) {
+			values, err := url.ParseQuery(query)
+			require.NoError(t, err)
+			_, err = Parse(values, testSpec)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	db := dryRun(t)
+
+	t.Run("First Page", func(t *testing.T) {
+		options := parse(t, "filter=name:eq:Ann&limit=2")
+		stmt := options.Paginate(options.Filter(db.Table("items"))).Find(&[]testItem{}).Statement
+		assert.Equal(t,
+			"SELECT * FROM \"items\" WHERE items.name = $1 ORDER BY items.created_at DESC,items.id ASC LIMIT $2",
+			stmt.SQL.String())
+	})
+
+	t.Run("Offset", func(t *testing.T) {
+		options := parse(t, "sort=-score&offset=4")
+		stmt := options.Paginate(db.Table("items")).Find(&[]testItem{}).Statement
+		assert.Equal(t, "SELECT * FROM \"items\" ORDER BY score DESC,items.id ASC LIMIT $1 OFFSET $2", stmt.SQL.String())
+		assert.Equal(t, []interface{}{21, 4}, stmt.Vars)
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		first := parse(t, "sort=name&limit=1")
+		page := first.Page([]testItem{{ID: 3, Name: "Ann"}, {ID: 4, Name: "Bob"}}, 2, &url.URL{Path: "/items", RawQuery: "sort=name&limit=1"})
+		require.NotEmpty(t, page.NextCursor)
+
+		next := parse(t, "sort=name&limit=1&cursor="+page.NextCursor)
+		assert.Equal(t, []interface{}{"Ann", int64(3)}, next.After)
+
+		stmt := next.Paginate(db.Table("items")).Find(&[]testItem{}).Statement
+		assert.Equal(t,
+			"SELECT * FROM \"items\" WHERE ((items.name > $1) OR (items.name = $2 AND items.id > $3)) ORDER BY items.name ASC,items.id ASC LIMIT $4",
+			stmt.SQL.String())
+		assert.Equal(t, []interface{}{"Ann", "Ann", int64(3), 2}, stmt.Vars)
+	})
+
+	t.Run("Cursor For Another Sort", func(t *testing.T) {
+		first := parse(t, "sort=name&limit=1")
+		page := first.Page([]testItem{{ID: 3}, {ID: 4}}, 2, &url.URL{Path: "/items"})
+
+		_, err := Parse(url.Values{"sort": {"-name"}, "cursor": {page.NextCursor}}, testSpec)
+		assert.Error(t, err)
+	})
+}
+
+func TestPage(t *testing.T) {
+	created := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+	items := []testItem{{ID: 1, Created_At: created}, {ID: 2, Created_At: created}, {ID: 3, Created_At: created}}
+	requestURL := &url.URL{Path: "/items", RawQuery: "limit=2&filter=name:like:a"}
+
+	t.Run("Cursor", func(t *testing.T) {
+		options := parse(t, requestURL.RawQuery)
+		page := options.Page(items, 7, requestURL)
+
+		assert.Equal(t, items[:2], page.Data)
+		assert.Equal(t, int64(7), page.Total)
+		assert.Equal(t, 2, page.Limit)
+		assert.Nil(t, page.Offset)
+		assert.Contains(t, page.Next, "/items?cursor="+page.NextCursor)
+		assert.Contains(t, page.Next, "filter=name%3Alike%3Aa")
+
+		next := parse(t, "limit=2&cursor="+page.NextCursor)
+		assert.Equal(t, []interface{}{created, int64(2)}, next.After)
+	})
+
+	t.Run("Offset", func(t *testing.T) {
+		options := parse(t, "limit=2&offset=2")
+		page := options.Page(items, 7, &url.URL{Path: "/items", RawQuery: "limit=2&offset=2"})
+
+		require.NotNil(t, page.Offset)
+		assert.Equal(t, 2, *page.Offset)
+		assert.Empty(t, page.NextCursor)
+		assert.Equal(t, "/items?limit=2&offset=4", page.Next)
+	})
+
+	t.Run("Last Page", func(t *testing.T) {
+		options := parse(t, "limit=5")
+		page := options.Page(items, 3, requestURL)
+
+		assert.Equal(t, items, page.Data)
+		assert.Empty(t, page.NextCursor)
+		assert.Empty(t, page.Next)
+	})
+}
+
+func TestApply(t *testing.T) {
+	created := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+	items := []testItem{
+		{ID: 1, Name: "Cleo", Created_At: created},
+		{ID: 2, Name: "Ann", Created_At: created.Add(time.Hour)},
+		{ID: 3, Name: "Bob", Created_At: created},
+		{ID: 4, Name: "Anna", Created_At: created.Add(-time.Hour)},
+	}
+	ids := func(page interface{}) []uint {
+		var ids []uint
+		for _, item := range page.([]testItem) {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+
+	t.Run("Sort", func(t *testing.T) {
+		page, total := parse(t, "").Apply(items)
+		assert.Equal(t, int64(4), total)
+		assert.Equal(t, []uint{2, 1, 3, 4}, ids(page))
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		page, total := parse(t, "filter=name:like:AN&filter=id:in:2|3|4&sort=id").Apply(items)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, []uint{2, 4}, ids(page))
+
+		page, _ = parse(t, "filter=created_at:gte:2025-03-01T12:30:00Z&filter=id:ne:1").Apply(items)
+		assert.Equal(t, []uint{2, 3}, ids(page))
+	})
+
+	t.Run("Pages Match Paginate", func(t *testing.T) {
+		first := parse(t, "sort=name&limit=2")
+		page, total := first.Apply(items)
+		// One extra item tells Page there is a next page
+		assert.Equal(t, []uint{2, 4, 3}, ids(page))
+
+		next := parse(t, "sort=name&limit=2&cursor="+first.Page(page, total, &url.URL{Path: "/items"}).NextCursor)
+		page, _ = next.Apply(items)
+		assert.Equal(t, []uint{3, 1}, ids(page))
+
+		page, _ = parse(t, "sort=name&limit=2&offset=3").Apply(items)
+		assert.Equal(t, []uint{1}, ids(page))
+	})
+}
@@ -0,0 +1,192 @@
+package listing
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Apply filters, sorts and pages a slice in memory, the way Filter and Paginate do a query.
+// Fields are read from the items by their JSON name, as Page does. It returns a new slice of
+// the page, with one item more than the limit when there is a next page, and the number of
+// items that pass the filters.
+func (o Options) Apply(items interface{}) (interface{}, int64) {
+	list := reflect.ValueOf(items)
+
+	var kept []reflect.Value
+	for i := 0; i < list.Len(); i++ {
+		if o.matches(list.Index(i)) {
+			kept = append(kept, list.Index(i))
+		}
+	}
+	total := int64(len(kept))
+
+	sort.SliceStable(kept, func(i, j int) bool {
+		return o.compareItems(kept[i], kept[j]) < 0
+	})
+
+	if len(o.After) > 0 {
+		var after []reflect.Value
+		for _, item := range kept {
+			if o.isAfterCursor(item) {
+				after = append(after, item)
+			}
+		}
+		kept = after
+	}
+	if o.useOffset {
+		kept = kept[min(o.Offset, len(kept)):]
+	}
+	kept = kept[:min(o.Limit+1, len(kept))]
+
+	page := reflect.MakeSlice(list.Type(), 0, len(kept))
+	for _, item := range kept {
+		page = reflect.Append(page, item)
+	}
+	return page.Interface(), total
+}
+
+func (o Options) matches(item reflect.Value) bool {
+	for _, f := range o.Filters {
+		value := fieldValue(item, f.Field)
+		// Like SQL, comparisons with NULL never hold
+		if value == nil {
+			return false
+		}
+
+		switch f.Op {
+		case "in":
+			found := false
+			for _, v := range f.Values {
+				found = found || compareValues(value, v) == 0
+			}
+			if !found {
+				return false
+			}
+		case "like":
+			if !strings.Contains(strings.ToLower(value.(string)), strings.ToLower(f.Values[0].(string))) {
+				return false
+			}
+		default:
+			c := compareValues(value, f.Values[0])
+			ok := map[string]bool{"eq": c == 0, "ne": c != 0, "lt": c < 0, "lte": c <= 0, "gt": c > 0, "gte": c >= 0}[f.Op]
+			if !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// compareItems orders two items by the sort of the options, with NULLs last
+func (o Options) compareItems(a, b reflect.Value) int {
+	for _, s := range o.Sort {
+		x, y := fieldValue(a, s.Field), fieldValue(b, s.Field)
+		var c int
+		switch {
+		case x == nil && y == nil:
+			continue
+		case x == nil:
+			return 1
+		case y == nil:
+			return -1
+		default:
+			c = compareValues(x, y)
+		}
+		if s.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// isAfterCursor reports whether an item sorts after the last item of the previous page
+func (o Options) isAfterCursor(item reflect.Value) bool {
+	for i, s := range o.Sort {
+		c := compareValues(fieldValue(item, s.Field), o.After[i])
+		if s.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c > 0
+		}
+	}
+	return false
+}
+
+// fieldValue reads a field of an item as one of the types parseValue returns: string, int64,
+// float64, bool or time.Time, or nil for a NULL
+func fieldValue(item reflect.Value, name string) interface{} {
+	v, ok := fieldByJSONName(item, name)
+	if !ok {
+		return nil
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	// Dates such as models.CustomDate are defined as time.Time
+	if timeType := reflect.TypeOf(time.Time{}); v.Type().ConvertibleTo(timeType) {
+		return v.Convert(timeType).Interface()
+	}
+	return nil
+}
+
+// compareValues compares two values of the same field type, returning -1, 0 or 1
+func compareValues(a, b interface{}) int {
+	switch x := a.(type) {
+	case string:
+		return strings.Compare(x, b.(string))
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case !x:
+			return -1
+		default:
+			return 1
+		}
+	case time.Time:
+		return x.Compare(b.(time.Time))
+	}
+
+	x, y := toFloat(a), toFloat(b)
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func toFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,190 @@
+// Package matching scores how well volunteers and opportunities fit each other. Every score
+// is made of weighted factors between 0 and 1, each with a human readable reason, so callers
+// can explain a ranking as well as produce it.
+package matching
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Factor names
+const (
+	FactorCategory = "category"
+	FactorLocation = "location"
+	FactorHours    = "hours"
+	FactorHistory  = "history"
+
+	FactorVerifiedHours  = "verified_hours"
+	FactorCompletionRate = "completion_rate"
+)
+
+// Distances in kilometers within which a location scores 1, and beyond which it scores 0
+const (
+	NearbyKm = 5
+	FarKm    = 50
+)
+
+// VerifiedHoursTarget is the number of verified hours at which a volunteer's experience scores 1
+const VerifiedHoursTarget = 40
+
+// Factor is one weighted part of a match score
+type Factor struct {
+	Name   string
+	Score  float64 // Between 0 and 1
+	Weight float64
+	Reason string
+}
+
+// Match is a combined score between 0 and 1 with the factors it was made of
+type Match struct {
+	Score   float64
+	Factors []Factor
+}
+
+// Combine weighs factors into a match. Factors with no weight are ignored.
+func Combine(factors ...Factor) Match {
+	var total, weights float64
+	for _, f := range factors {
+		total += clamp(f.Score) * f.Weight
+		weights += f.Weight
+	}
+
+	match := Match{Factors: factors}
+	if weights > 0 {
+		match.Score = round(total / weights)
+	}
+	return match
+}
+
+// Reasons returns the reasons of the factors that counted towards the match, best first
+func (m Match) Reasons() []string {
+	factors := make([]Factor, 0, len(m.Factors))
+	for _, f := range m.Factors {
+		if f.Score > 0 && f.Weight > 0 && f.Reason != "" {
+			factors = append(factors, f)
+		}
+	}
+	sort.SliceStable(factors, func(i, j int) bool {
+		return factors[i].Score*factors[i].Weight > factors[j].Score*factors[j].Weight
+	})
+
+	reasons := make([]string, 0, len(factors))
+	for _, f := range factors {
+		reasons = append(reasons, f.Reason)
+	}
+	return reasons
+}
+
+// CategoryFactor scores whether the category is one of the volunteer's categories
+func CategoryFactor(categories []string, category string, weight float64) Factor {
+	for _, c := range categories {
+		if strings.EqualFold(strings.TrimSpace(c), strings.TrimSpace(category)) {
+			return Factor{Name: FactorCategory, Score: 1, Weight: weight, Reason: fmt.Sprintf("Matches the %s category", category)}
+		}
+	}
+	return Factor{Name: FactorCategory, Score: 0, Weight: weight, Reason: fmt.Sprintf("%s is not one of the volunteer's categories", category)}
+}
+
+// LocationFactor scores how close two free-text locations are. The same location scores 1 and
+// locations that share a place name (such as the city in "Main St, Gainesville") score 0.5.
+func LocationFactor(from string, to string, weight float64) Factor {
+	if strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+		return Factor{Name: FactorLocation, Score: 0, Weight: weight, Reason: "Location unknown"}
+	}
+	if strings.EqualFold(strings.TrimSpace(from), strings.TrimSpace(to)) {
+		return Factor{Name: FactorLocation, Score: 1, Weight: weight, Reason: fmt.Sprintf("Located in %s", strings.TrimSpace(to))}
+	}
+
+	places := map[string]bool{}
+	for _, place := range placeNames(from) {
+		places[place] = true
+	}
+	for _, place := range placeNames(to) {
+		if places[place] {
+			return Factor{Name: FactorLocation, Score: 0.5, Weight: weight, Reason: fmt.Sprintf("Near %s", strings.TrimSpace(to))}
+		}
+	}
+	return Factor{Name: FactorLocation, Score: 0, Weight: weight, Reason: fmt.Sprintf("%s is away from %s", strings.TrimSpace(to), strings.TrimSpace(from))}
+}
+
+// DistanceFactor scores a distance between two geocoded locations, from 1 within NearbyKm down
+// to 0 at FarKm
+func DistanceFactor(km float64, weight float64) Factor {
+	score := clamp((FarKm - km) / (FarKm - NearbyKm))
+	return Factor{Name: FactorLocation, Score: score, Weight: weight, Reason: fmt.Sprintf("%.1f km away", km)}
+}
+
+// RemoteFactor scores a remote opportunity, which is as close as it gets
+func RemoteFactor(weight float64) Factor {
+	return Factor{Name: FactorLocation, Score: 1, Weight: weight, Reason: "Can be done remotely"}
+}
+
+// placeNames splits a location into its comma separated parts, ignoring street addresses
+func placeNames(location string) []string {
+	var names []string
+	for _, part := range strings.Split(location, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" || strings.IndexFunc(part, unicode.IsDigit) >= 0 {
+			continue
+		}
+		names = append(names, part)
+	}
+	return names
+}
+
+// HoursFactor scores whether the hours required fit in the hours the volunteer is available.
+// Volunteers who did not state their availability get a neutral score.
+func HoursFactor(available uint, required uint, weight float64) Factor {
+	switch {
+	case available == 0:
+		return Factor{Name: FactorHours, Score: 0.5, Weight: weight, Reason: "Availability not stated"}
+	case required <= available:
+		return Factor{Name: FactorHours, Score: 1, Weight: weight, Reason: fmt.Sprintf("Needs %d hours, within the %d hours available", required, available)}
+	default:
+		return Factor{Name: FactorHours, Score: float64(available) / float64(required), Weight: weight, Reason: fmt.Sprintf("Needs %d hours, more than the %d hours available", required, available)}
+	}
+}
+
+// HistoryFactor scores a volunteer's past applications: completed work for the same
+// organization scores 1 and earlier applications in the same category score 0.5
+func HistoryFactor(completedWithOrganization int64, appliedInCategory int64, organization string, category string, weight float64) Factor {
+	switch {
+	case completedWithOrganization > 0:
+		return Factor{Name: FactorHistory, Score: 1, Weight: weight, Reason: fmt.Sprintf("Completed %d opportunities with %s before", completedWithOrganization, organization)}
+	case appliedInCategory > 0:
+		return Factor{Name: FactorHistory, Score: 0.5, Weight: weight, Reason: fmt.Sprintf("Applied to %d %s opportunities before", appliedInCategory, category)}
+	default:
+		return Factor{Name: FactorHistory, Score: 0, Weight: weight, Reason: "No related past applications"}
+	}
+}
+
+// VerifiedHoursFactor scores a volunteer's experience by the hours organizations approved,
+// reaching 1 at VerifiedHoursTarget
+func VerifiedHoursFactor(hours float64, weight float64) Factor {
+	if hours <= 0 {
+		return Factor{Name: FactorVerifiedHours, Score: 0, Weight: weight, Reason: "No verified hours yet"}
+	}
+	return Factor{Name: FactorVerifiedHours, Score: math.Min(1, hours/VerifiedHoursTarget), Weight: weight, Reason: fmt.Sprintf("%g verified hours", hours)}
+}
+
+// CompletionRateFactor scores the share of a volunteer's finished applications they completed
+// rather than missed. Volunteers with no finished applications get a neutral score.
+func CompletionRateFactor(completed int64, finished int64, weight float64) Factor {
+	if finished <= 0 {
+		return Factor{Name: FactorCompletionRate, Score: 0.5, Weight: weight, Reason: "No finished applications yet"}
+	}
+	return Factor{Name: FactorCompletionRate, Score: float64(completed) / float64(finished), Weight: weight, Reason: fmt.Sprintf("Completed %d of %d accepted applications", completed, finished)}
+}
+
+func clamp(score float64) float64 {
+	return math.Max(0, math.Min(1, score))
+}
+
+// round keeps scores readable in responses
+func round(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
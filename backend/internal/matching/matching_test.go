@@ -0,0 +1,71 @@
+package matching
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombineWeighsFactors(t *testing.T) {
+	match := Combine(
+		Factor{Name: FactorCategory, Score: 1, Weight: 3},
+		Factor{Name: FactorLocation, Score: 0, Weight: 1},
+	)
+	assert.Equal(t, 0.75, match.Score)
+	assert.Len(t, match.Factors, 2)
+
+	assert.Equal(t, 0.0, Combine().Score)
+	assert.Equal(t, 1.0, Combine(Factor{Score: 4, Weight: 1}).Score)
+}
+
+func TestReasonsBestFirst(t *testing.T) {
+	match := Combine(
+		Factor{Name: FactorHours, Score: 0.5, Weight: 1, Reason: "hours"},
+		Factor{Name: FactorLocation, Score: 0, Weight: 1, Reason: "location"},
+		Factor{Name: FactorCategory, Score: 1, Weight: 1, Reason: "category"},
+	)
+	assert.Equal(t, []string{"category", "hours"}, match.Reasons())
+}
+
+func TestCategoryFactor(t *testing.T) {
+	assert.Equal(t, 1.0, CategoryFactor([]string{"Health", " education "}, "Education", 1).Score)
+	assert.Equal(t, 0.0, CategoryFactor([]string{"Health"}, "Education", 1).Score)
+	assert.Equal(t, 0.0, CategoryFactor(nil, "Education", 1).Score)
+}
+
+func TestLocationFactor(t *testing.T) {
+	assert.Equal(t, 1.0, LocationFactor("Gainesville, FL", "gainesville, fl", 1).Score)
+	assert.Equal(t, 0.5, LocationFactor("Gainesville, FL", "123 Main St, Gainesville", 1).Score)
+	assert.Equal(t, 0.0, LocationFactor("Gainesville", "Orlando", 1).Score)
+	assert.Equal(t, 0.0, LocationFactor("", "Orlando", 1).Score)
+}
+
+func TestDistanceFactor(t *testing.T) {
+	assert.Equal(t, 1.0, DistanceFactor(2, 1).Score)
+	assert.InDelta(t, 0.5, DistanceFactor((NearbyKm+FarKm)/2.0, 1).Score, 1e-9)
+	assert.Equal(t, 0.0, DistanceFactor(200, 1).Score)
+	assert.Equal(t, 1.0, RemoteFactor(1).Score)
+}
+
+func TestHoursFactor(t *testing.T) {
+	assert.Equal(t, 1.0, HoursFactor(10, 5, 1).Score)
+	assert.Equal(t, 0.5, HoursFactor(5, 10, 1).Score)
+	assert.Equal(t, 0.5, HoursFactor(0, 10, 1).Score)
+}
+
+func TestHistoryFactor(t *testing.T) {
+	assert.Equal(t, 1.0, HistoryFactor(2, 3, "Food Bank", "Health", 1).Score)
+	assert.Equal(t, 0.5, HistoryFactor(0, 1, "Food Bank", "Health", 1).Score)
+	assert.Equal(t, 0.0, HistoryFactor(0, 0, "Food Bank", "Health", 1).Score)
+}
+
+func TestVerifiedHoursFactor(t *testing.T) {
+	assert.Equal(t, 0.0, VerifiedHoursFactor(0, 1).Score)
+	assert.Equal(t, 0.5, VerifiedHoursFactor(VerifiedHoursTarget/2, 1).Score)
+	assert.Equal(t, 1.0, VerifiedHoursFactor(VerifiedHoursTarget*3, 1).Score)
+}
+
+func TestCompletionRateFactor(t *testing.T) {
+	assert.Equal(t, 0.75, CompletionRateFactor(3, 4, 1).Score)
+	assert.Equal(t, 0.5, CompletionRateFactor(0, 0, 1).Score)
+}
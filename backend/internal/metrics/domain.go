@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// domainTimeout bounds the queries made for one scrape
+const domainTimeout = 5 * time.Second
+
+var (
+	openOpportunitiesDesc = prometheus.NewDesc(namespace+"_open_opportunities",
+		"Opportunities that have not ended, are not hidden and belong to an organization that is not suspended.", nil, nil)
+	registeredVolunteersDesc = prometheus.NewDesc(namespace+"_registered_volunteers",
+		"Registered volunteers, including suspended ones.", nil, nil)
+	registeredOrganizationsDesc = prometheus.NewDesc(namespace+"_registered_organizations",
+		"Registered organizations, including suspended ones.", nil, nil)
+)
+
+// DomainCollector counts the open opportunities and the registered volunteers and
+// organizations in the database each time the metrics are scraped, so the gauges are right
+// whichever server instance changed the records
+type DomainCollector struct {
+	db *gorm.DB
+}
+
+// NewDomainCollector creates a DomainCollector reading from db
+func NewDomainCollector(db *gorm.DB) *DomainCollector {
+	return &DomainCollector{db: db}
+}
+
+// Describe sends the descriptions of the gauges
+func (d *DomainCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- openOpportunitiesDesc
+	ch <- registeredVolunteersDesc
+	ch <- registeredOrganizationsDesc
+}
+
+// Collect queries the gauges. A gauge whose query fails is reported as an invalid metric,
+// which fails the scrape rather than reporting a wrong value.
+func (d *DomainCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), domainTimeout)
+	defer cancel()
+	db := d.db.WithContext(ctx)
+
+	gauges := []struct {
+		desc  *prometheus.Desc
+		query *gorm.DB
+	}{
+		{openOpportunitiesDesc, db.Table("opportunities").
+			Joins("INNER JOIN organizations ON opportunities.organization_mail = organizations.email").
+			Where("opportunities.end_date >= ?", time.Now()).
+			Where("opportunities.hidden_at IS NULL AND organizations.suspended_at IS NULL")},
+		{registeredVolunteersDesc, db.Table("volunteers")},
+		{registeredOrganizationsDesc, db.Table("organizations")},
+	}
+
+	for _, gauge := range gauges {
+		var count int64
+		if err := gauge.query.Count(&count).Error; err != nil {
+			ch <- prometheus.NewInvalidMetric(gauge.desc, err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(gauge.desc, prometheus.GaugeValue, float64(count))
+	}
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// Every connection to :memory: is a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// observations returns the number of observations made on a histogram series
+func observations(t *testing.T, observer prometheus.Observer) uint64 {
+	var metric dto.Metric
+	require.NoError(t, observer.(prometheus.Metric).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/items/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/items/7", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/items/8", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/unknown/9", nil))
+
+	// Requests are grouped by route template, not path
+	assert.Equal(t, uint64(2), observations(t, HTTPRequestDuration.WithLabelValues("GET", "/items/:id", "204")))
+	assert.Equal(t, uint64(1), observations(t, HTTPRequestDuration.WithLabelValues("GET", "unmatched", "404")))
+}
+
+func TestGormPlugin(t *testing.T) {
+	db := openTestDB(t)
+	require.NoError(t, db.Use(GormPlugin{}))
+	require.NoError(t, db.Exec("CREATE TABLE widgets (id integer PRIMARY KEY, name text)").Error)
+
+	require.NoError(t, db.Table("widgets").Create(map[string]interface{}{"id": 1, "name": "gear"}).Error)
+	var names []string
+	require.NoError(t, db.Table("widgets").Pluck("name", &names).Error)
+	assert.Equal(t, []string{"gear"}, names)
+
+	assert.Equal(t, uint64(1), observations(t, DBQueryDuration.WithLabelValues("create", "widgets")))
+	assert.Equal(t, uint64(1), observations(t, DBQueryDuration.WithLabelValues("query", "widgets")))
+}
+
+func TestDomainCollector(t *testing.T) {
+	db := openTestDB(t)
+	for _, statement := range []string{
+		"CREATE TABLE organizations (email text PRIMARY KEY, suspended_at datetime)",
+		"CREATE TABLE volunteers (id integer PRIMARY KEY)",
+		"CREATE TABLE opportunities (id integer PRIMARY KEY, organization_mail text, end_date datetime, hidden_at datetime)",
+		"INSERT INTO organizations VALUES ('open@org.com', NULL), ('suspended@org.com', CURRENT_TIMESTAMP)",
+		"INSERT INTO volunteers VALUES (1), (2), (3)",
+	} {
+		require.NoError(t, db.Exec(statement).Error)
+	}
+	future, past := time.Now().AddDate(0, 1, 0), time.Now().AddDate(0, -1, 0)
+	require.NoError(t, db.Exec(`INSERT INTO opportunities VALUES
+		(1, 'open@org.com', ?, NULL),
+		(2, 'open@org.com', ?, NULL),
+		(3, 'open@org.com', ?, CURRENT_TIMESTAMP),
+		(4, 'suspended@org.com', ?, NULL)`, future, past, future, future).Error)
+
+	expected := `
+# HELP helperhub_open_opportunities Opportunities that have not ended, are not hidden and belong to an organization that is not suspended.
+# TYPE helperhub_open_opportunities gauge
+helperhub_open_opportunities 1
+# HELP helperhub_registered_organizations Registered organizations, including suspended ones.
+# TYPE helperhub_registered_organizations gauge
+helperhub_registered_organizations 2
+# HELP helperhub_registered_volunteers Registered volunteers, including suspended ones.
+# TYPE helperhub_registered_volunteers gauge
+helperhub_registered_volunteers 3
+`
+	assert.NoError(t, testutil.CollectAndCompare(NewDomainCollector(db), strings.NewReader(expected)))
+}
+
+func TestDomainCollectorError(t *testing.T) {
+	// Without the tables every query fails, and so does the scrape
+	collector := NewDomainCollector(openTestDB(t))
+	assert.Error(t, testutil.CollectAndCompare(collector, strings.NewReader("")))
+}
@@ -0,0 +1,375 @@
+// Package migrate applies versioned SQL migrations to the database and tracks which versions
+// have been applied in the schema_migrations table.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionMismatch is returned by Check when the database is not at the latest version
+var ErrVersionMismatch = errors.New("database schema version mismatch")
+
+// ErrNotMigrated is returned by CurrentVersion when the database has no schema_migrations table
+var ErrNotMigrated = errors.New("database has no schema_migrations table")
+
+// ErrUnversioned is returned by Up when the database has tables but no migration has been
+// applied to it, like databases created by db.AutoMigrate before migrations were introduced.
+// Their schema differs from the one the migrations build, so they have to be adopted with
+// Baseline first.
+var ErrUnversioned = errors.New("database has tables that were not created by migrations")
+
+// ErrVersioned is returned by Baseline when migrations have already been applied to the database
+var ErrVersioned = errors.New("database already has applied migrations")
+
+// ErrSchemaMismatch is returned by Baseline when the database lacks columns of the first migration
+var ErrSchemaMismatch = errors.New("database schema does not match the first migration")
+
+var (
+	// fileName matches migration files such as 0002_rename_available_hours.up.sql
+	fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+	// tableDefinition matches the CREATE TABLE statements of a migration, and columnDefinition
+	// the column lines in them, which are indented by four spaces
+	tableDefinition  = regexp.MustCompile(`(?s)CREATE TABLE (?:IF NOT EXISTS )?(\w+) \((.*?)\n\);`)
+	columnDefinition = regexp.MustCompile(`(?m)^    ([a-z_]+) `)
+)
+
+// Migration is one version of the schema, with the SQL to apply it and to revert it
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status tells whether a migration has been applied, and when
+type Status struct {
+	Migration
+	Applied_At *time.Time
+}
+
+// schemaMigration is a row of the schema_migrations table
+type schemaMigration struct {
+	Version    int `gorm:"primaryKey;autoIncrement:false"`
+	Name       string
+	Applied_At time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Load reads the migrations from the .sql files of fsys. Every version needs both an up and a
+// down file, and versions must count up from 1 without gaps.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s must be named NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, match[2])
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration %d is missing", i+1)
+		}
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d needs both an up and a down file", migration.Version)
+		}
+	}
+	return migrations, nil
+}
+
+// Migrator applies and reverts migrations on a database
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New creates a Migrator for the migrations in fsys
+func New(db *gorm.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// WithContext returns a copy of the Migrator that runs its queries with ctx
+func (m *Migrator) WithContext(ctx context.Context) *Migrator {
+	return &Migrator{db: m.db.WithContext(ctx), migrations: m.migrations}
+}
+
+// Latest returns the version the migrations bring the schema to
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// ensureTable creates the schema_migrations table when it does not exist yet
+func (m *Migrator) ensureTable() error {
+	// SQLite only reads columns declared as datetime back as times
+	timestamp := "timestamptz"
+	if m.db.Dialector.Name() == "sqlite" {
+		timestamp = "datetime"
+	}
+	return m.db.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (" +
+		"version bigint PRIMARY KEY, " +
+		"name text NOT NULL, " +
+		"applied_at " + timestamp + " NOT NULL)").Error
+}
+
+func (m *Migrator) applied() (map[int]schemaMigration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var rows []schemaMigration
+	if err := m.db.Order("version ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Version returns the latest version applied to the database, 0 when none is
+func (m *Migrator) Version() (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	version := 0
+	for v := range applied {
+		version = max(version, v)
+	}
+	return version, nil
+}
+
+// CurrentVersion returns the latest version applied to the database like Version, but only
+// reads: it returns ErrNotMigrated instead of creating the schema_migrations table when it does
+// not exist
+func (m *Migrator) CurrentVersion() (int, error) {
+	if !m.db.Migrator().HasTable(schemaMigration{}) {
+		return 0, ErrNotMigrated
+	}
+
+	var version int
+	err := m.db.Model(&schemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// Up applies the pending migrations in order and returns the ones it applied. Each migration
+// runs in a transaction together with its schema_migrations row. It returns ErrUnversioned
+// without changing anything when no migration has been applied yet but the database already
+// has tables.
+func (m *Migrator) Up() ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	if len(applied) == 0 {
+		if err := m.checkEmpty(); err != nil {
+			return nil, err
+		}
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, Applied_At: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// checkEmpty returns ErrUnversioned when the database has tables other than schema_migrations
+func (m *Migrator) checkEmpty() error {
+	tables, err := m.db.Migrator().GetTables()
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		// SQLite keeps its own bookkeeping in tables named sqlite_*
+		if table == (schemaMigration{}).TableName() || strings.HasPrefix(table, "sqlite_") {
+			continue
+		}
+		return fmt.Errorf("%w: found table %s", ErrUnversioned, table)
+	}
+	return nil
+}
+
+// Baseline adopts a database created by db.AutoMigrate before migrations were introduced and
+// records the first migration as applied, so that Up can apply the rest. It runs adopt, which
+// adds the columns the first migration expects to the existing tables, then the first
+// migration, which only creates the tables and indexes that do not exist yet. It returns
+// ErrSchemaMismatch when a column of the first migration is still missing. Everything runs in
+// one transaction, so the database is left as it was when a step fails.
+func (m *Migrator) Baseline(adopt string) error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	if len(applied) > 0 {
+		return ErrVersioned
+	}
+	if len(m.migrations) == 0 {
+		return errors.New("there is no migration to record")
+	}
+
+	first := m.migrations[0]
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if adopt != "" {
+			if err := tx.Exec(adopt).Error; err != nil {
+				return fmt.Errorf("adopting the schema: %w", err)
+			}
+		}
+		if err := tx.Exec(first.Up).Error; err != nil {
+			return fmt.Errorf("migration %d %s: %w", first.Version, first.Name, err)
+		}
+		if err := checkColumns(tx, first.Up); err != nil {
+			return err
+		}
+		return tx.Create(&schemaMigration{Version: first.Version, Name: first.Name, Applied_At: time.Now()}).Error
+	})
+}
+
+// checkColumns returns ErrSchemaMismatch when a column of a table created by the migration is
+// missing from the database
+func checkColumns(db *gorm.DB, up string) error {
+	var missing []string
+	for _, table := range tableDefinition.FindAllStringSubmatch(up, -1) {
+		for _, column := range columnDefinition.FindAllStringSubmatch(table[2], -1) {
+			if !db.Migrator().HasColumn(table[1], column[1]) {
+				missing = append(missing, table[1]+"."+column[1])
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing %s", ErrSchemaMismatch, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Down reverts the latest steps applied migrations, newest first, and returns the ones it
+// reverted
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Status lists every migration and whether it has been applied
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.Applied_At
+			status.Applied_At = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Check returns ErrVersionMismatch when the database is behind or ahead of the migrations, or
+// has not been migrated at all. It does not change the database.
+func (m *Migrator) Check() error {
+	version, err := m.CurrentVersion()
+	if err != nil && !errors.Is(err, ErrNotMigrated) {
+		return err
+	}
+	if version != m.Latest() {
+		return fmt.Errorf("%w: the database is at version %d but this build expects version %d", ErrVersionMismatch, version, m.Latest())
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package migrate
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/prathamrao021/HelperHub/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_add_index.down.sql":    {Data: []byte("DROP INDEX idx;")},
+		"0002_add_index.up.sql":      {Data: []byte("CREATE INDEX idx ON t (c);")},
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (c int);")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		"migrations.go":              {Data: []byte("package migrations")},
+	}
+
+	loaded, err := Load(fsys)
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	assert.Equal(t, Migration{Version: 1, Name: "create_table", Up: "CREATE TABLE t (c int);", Down: "DROP TABLE t;"}, loaded[0])
+	assert.Equal(t, 2, loaded[1].Version)
+	assert.Equal(t, "add_index", loaded[1].Name)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"Bad Name": {
+			"create_table.up.sql": {Data: []byte("SELECT 1;")},
+		},
+		"Missing Down": {
+			"0001_create_table.up.sql": {Data: []byte("SELECT 1;")},
+		},
+		"Gap": {
+			"0001_create_table.up.sql":   {Data: []byte("SELECT 1;")},
+			"0001_create_table.down.sql": {Data: []byte("SELECT 1;")},
+			"0003_add_index.up.sql":      {Data: []byte("SELECT 1;")},
+			"0003_add_index.down.sql":    {Data: []byte("SELECT 1;")},
+		},
+		"Name Mismatch": {
+			"0001_create_table.up.sql":    {Data: []byte("SELECT 1;")},
+			"0001_create_tables.down.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(fsys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadShippedMigrations(t *testing.T) {
+	loaded, err := Load(migrations.FS)
+	require.NoError(t, err)
+	assert.NotEmpty(t, loaded)
+	assert.Equal(t, "initial_schema", loaded[0].Name)
+
+	// Both databases go through the same versions
+	sqlite, err := Load(migrations.SQLite)
+	require.NoError(t, err)
+	require.Len(t, sqlite, len(loaded))
+	for i := range loaded {
+		assert.Equal(t, loaded[i].Name, sqlite[i].Name)
+	}
+}
+
+func TestUpRefusesUnversionedDatabase(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	require.NoError(t, err)
+	fsys := fstest.MapFS{
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (c int);")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+	}
+	migrator, err := New(db, fsys)
+	require.NoError(t, err)
+
+	// A table created without migrations, like the ones db.AutoMigrate created
+	require.NoError(t, db.Exec("CREATE TABLE volunteers (id integer PRIMARY KEY)").Error)
+
+	// Checking the version first does not make the database look migrated
+	assert.ErrorIs(t, migrator.Check(), ErrVersionMismatch)
+	applied, err := migrator.Up()
+	assert.ErrorIs(t, err, ErrUnversioned)
+	assert.Empty(t, applied)
+	assert.False(t, db.Migrator().HasTable("t"))
+
+	// An empty database is migrated
+	require.NoError(t, db.Exec("DROP TABLE volunteers").Error)
+	applied, err = migrator.Up()
+	require.NoError(t, err)
+	assert.Len(t, applied, 1)
+}
+
+func TestBaselineAdoptsUnversionedDatabase(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	require.NoError(t, err)
+	migrator, err := New(db, migrations.SQLite)
+	require.NoError(t, err)
+
+	// A table and row created without migrations
+	require.NoError(t, db.Exec("CREATE TABLE categories (id integer PRIMARY KEY AUTOINCREMENT, category text NOT NULL UNIQUE)").Error)
+	require.NoError(t, db.Exec("INSERT INTO categories (category) VALUES ('Education')").Error)
+
+	// The adopt SQL adds the missing column, and the first migration creates the missing tables
+	require.NoError(t, migrator.Baseline("ALTER TABLE categories ADD COLUMN created_at datetime;"))
+	version, err := migrator.CurrentVersion()
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+	assert.True(t, db.Migrator().HasTable("volunteers"))
+	assert.ErrorIs(t, migrator.Baseline(""), ErrVersioned)
+
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	assert.Len(t, applied, migrator.Latest()-1)
+	var categories int64
+	db.Table("categories").Count(&categories)
+	assert.Equal(t, int64(1), categories)
+}
+
+func TestBaselineChecksColumns(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	require.NoError(t, err)
+	migrator, err := New(db, migrations.SQLite)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Exec("CREATE TABLE categories (id integer PRIMARY KEY AUTOINCREMENT, category text NOT NULL UNIQUE)").Error)
+
+	// Nothing is recorded or created when a column is still missing
+	err = migrator.Baseline("")
+	assert.ErrorIs(t, err, ErrSchemaMismatch)
+	assert.ErrorContains(t, err, "categories.created_at")
+	version, err := migrator.CurrentVersion()
+	require.NoError(t, err)
+	assert.Equal(t, 0, version)
+	assert.False(t, db.Migrator().HasTable("volunteers"))
+}
+
+func TestAdoptShippedForPostgres(t *testing.T) {
+	adopt, ok := migrations.Adopt("postgres")
+	assert.True(t, ok)
+	assert.Contains(t, adopt, "search_vector")
+	_, ok = migrations.Adopt("sqlite")
+	assert.False(t, ok)
+}
+
+func TestCurrentVersionOnlyReads(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	require.NoError(t, err)
+	migrator, err := New(db, fstest.MapFS{
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (c int);")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+	})
+	require.NoError(t, err)
+
+	_, err = migrator.CurrentVersion()
+	assert.ErrorIs(t, err, ErrNotMigrated)
+	assert.ErrorIs(t, migrator.Check(), ErrVersionMismatch)
+	assert.False(t, db.Migrator().HasTable("schema_migrations"), "Checking the version must not create the table")
+
+	_, err = migrator.Up()
+	require.NoError(t, err)
+	version, err := migrator.CurrentVersion()
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+	assert.NoError(t, migrator.Check())
+
+	_, err = migrator.Down(1)
+	require.NoError(t, err)
+	version, err = migrator.CurrentVersion()
+	require.NoError(t, err)
+	assert.Equal(t, 0, version)
+}
@@ -0,0 +1,207 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/prathamrao021/HelperHub/internal/listing"
+	"github.com/prathamrao021/HelperHub/models"
+	"gorm.io/gorm"
+)
+
+// NewGorm returns stores that keep the records in the database. db must be opened with
+// TranslateError so duplicate keys and foreign key violations can be told apart.
+func NewGorm(db *gorm.DB) Stores {
+	return Stores{
+		Volunteers:    gormVolunteers{db},
+		Organizations: gormOrganizations{db},
+		Opportunities: gormOpportunities{db},
+		Applications:  gormApplications{db},
+		Categories:    gormCategories{db},
+	}
+}
+
+// translate turns GORM errors into the errors of this package
+func translate(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicate
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return ErrInvalidReference
+	}
+	return err
+}
+
+// first loads the record matching the condition into dest
+func first(ctx context.Context, db *gorm.DB, dest interface{}, query string, args ...interface{}) error {
+	return translate(db.WithContext(ctx).Where(query, args...).First(dest).Error)
+}
+
+// update writes the columns of a record that has already been created, except created_at and
+// the omitted columns, which other requests change
+func update(ctx context.Context, db *gorm.DB, record interface{}, omit ...string) error {
+	result := db.WithContext(ctx).Model(record).Select("*").Omit(append(omit, "created_at")...).Updates(record)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// remove deletes the record of model with the given ID
+func remove(ctx context.Context, db *gorm.DB, model interface{}, id uint) error {
+	result := db.WithContext(ctx).Where("id = ?", id).Delete(model)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// filterSet narrows a query to the rows whose nullable column is set when set is true, or to
+// those where it is NULL when set is false
+func filterSet(query *gorm.DB, column string, set *bool) *gorm.DB {
+	switch {
+	case set == nil:
+		return query
+	case *set:
+		return query.Where(column + " IS NOT NULL")
+	default:
+		return query.Where(column + " IS NULL")
+	}
+}
+
+type gormVolunteers struct{ db *gorm.DB }
+
+func (s gormVolunteers) Create(ctx context.Context, volunteer *models.Volunteer) error {
+	return translate(s.db.WithContext(ctx).Create(volunteer).Error)
+}
+
+func (s gormVolunteers) GetByEmail(ctx context.Context, email string) (models.Volunteer, error) {
+	var volunteer models.Volunteer
+	return volunteer, first(ctx, s.db, &volunteer, "email = ?", email)
+}
+
+func (s gormVolunteers) Update(ctx context.Context, volunteer *models.Volunteer) error {
+	return update(ctx, s.db, volunteer, "suspended_at")
+}
+
+func (s gormVolunteers) Delete(ctx context.Context, id uint) error {
+	return remove(ctx, s.db, &models.Volunteer{}, id)
+}
+
+func (s gormVolunteers) List(ctx context.Context, filter AccountFilter, options listing.Options) ([]models.Volunteer, int64, error) {
+	var volunteers []models.Volunteer
+	total, err := options.Find(filterSet(s.db.WithContext(ctx).Model(&models.Volunteer{}), "suspended_at", filter.Suspended), &volunteers)
+	return volunteers, total, err
+}
+
+type gormOrganizations struct{ db *gorm.DB }
+
+func (s gormOrganizations) Create(ctx context.Context, organization *models.Organization) error {
+	return translate(s.db.WithContext(ctx).Create(organization).Error)
+}
+
+func (s gormOrganizations) Get(ctx context.Context, id uint) (models.Organization, error) {
+	var organization models.Organization
+	return organization, first(ctx, s.db, &organization, "id = ?", id)
+}
+
+func (s gormOrganizations) GetByEmail(ctx context.Context, email string) (models.Organization, error) {
+	var organization models.Organization
+	return organization, first(ctx, s.db, &organization, "email = ?", email)
+}
+
+func (s gormOrganizations) Update(ctx context.Context, organization *models.Organization) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current models.Organization
+		if err := first(ctx, tx, &current, "id = ?", organization.ID); err != nil {
+			return err
+		}
+		if err := update(ctx, tx, organization, "suspended_at"); err != nil {
+			return err
+		}
+		if current.Email == organization.Email {
+			return nil
+		}
+		return translate(tx.Model(&models.Opportunity{}).
+			Where("organization_mail = ?", current.Email).
+			Update("organization_mail", organization.Email).Error)
+	})
+}
+
+func (s gormOrganizations) Delete(ctx context.Context, id uint) error {
+	return remove(ctx, s.db, &models.Organization{}, id)
+}
+
+func (s gormOrganizations) List(ctx context.Context, filter AccountFilter, options listing.Options) ([]models.Organization, int64, error) {
+	var organizations []models.Organization
+	total, err := options.Find(filterSet(s.db.WithContext(ctx).Model(&models.Organization{}), "suspended_at", filter.Suspended), &organizations)
+	return organizations, total, err
+}
+
+type gormOpportunities struct{ db *gorm.DB }
+
+func (s gormOpportunities) Create(ctx context.Context, opportunity *models.Opportunity) error {
+	return translate(s.db.WithContext(ctx).Create(opportunity).Error)
+}
+
+func (s gormOpportunities) Get(ctx context.Context, id uint) (models.Opportunity, error) {
+	var opportunity models.Opportunity
+	return opportunity, first(ctx, s.db, &opportunity, "id = ?", id)
+}
+
+func (s gormOpportunities) Update(ctx context.Context, opportunity *models.Opportunity) error {
+	return update(ctx, s.db, opportunity)
+}
+
+func (s gormOpportunities) Delete(ctx context.Context, id uint) error {
+	return remove(ctx, s.db, &models.Opportunity{}, id)
+}
+
+func (s gormOpportunities) List(ctx context.Context, filter OpportunityFilter, options listing.Options) ([]models.Opportunity, int64, error) {
+	var opportunities []models.Opportunity
+	total, err := options.Find(filterSet(s.db.WithContext(ctx).Model(&models.Opportunity{}), "hidden_at", filter.Hidden), &opportunities)
+	return opportunities, total, err
+}
+
+type gormApplications struct{ db *gorm.DB }
+
+func (s gormApplications) Get(ctx context.Context, id uint) (models.Application, error) {
+	var application models.Application
+	return application, first(ctx, s.db, &application, "id = ?", id)
+}
+
+func (s gormApplications) List(ctx context.Context, filter ApplicationFilter, options listing.Options) ([]models.Application, int64, error) {
+	query := s.db.WithContext(ctx).Model(&models.Application{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var applications []models.Application
+	total, err := options.Find(query, &applications)
+	return applications, total, err
+}
+
+type gormCategories struct{ db *gorm.DB }
+
+func (s gormCategories) Create(ctx context.Context, category *models.Category) error {
+	return translate(s.db.WithContext(ctx).Create(category).Error)
+}
+
+func (s gormCategories) GetByName(ctx context.Context, name string) (models.Category, error) {
+	var category models.Category
+	return category, first(ctx, s.db, &category, "category = ?", name)
+}
+
+func (s gormCategories) List(ctx context.Context, options listing.Options) ([]models.Category, int64, error) {
+	var categories []models.Category
+	total, err := options.Find(s.db.WithContext(ctx).Model(&models.Category{}), &categories)
+	return categories, total, err
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prathamrao021/HelperHub/internal/migrate"
+	"github.com/prathamrao021/HelperHub/migrations"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// openGorm returns the stores of a new SQLite database at the latest schema
+func openGorm(t *testing.T) (*gorm.DB, Stores) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{TranslateError: true})
+	require.NoError(t, err)
+	migrator, err := migrate.New(db, migrations.SQLite)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+	return db, NewGorm(db)
+}
+
+func TestUpdateKeepsSuspension(t *testing.T) {
+	ctx := context.Background()
+	_, gormStores := openGorm(t)
+
+	for name, stores := range map[string]Stores{"Gorm": gormStores, "Memory": NewMemory()} {
+		t.Run(name, func(t *testing.T) {
+			created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+			suspended := time.Now()
+			volunteer := models.Volunteer{Email: "ann@example.com", Name: "Ann", Phone: "1", Created_At: created, Suspended_At: &suspended}
+			require.NoError(t, stores.Volunteers.Create(ctx, &volunteer))
+			organization := models.Organization{Email: "org@example.com", Name: "Helpers", Created_At: created, Suspended_At: &suspended}
+			require.NoError(t, stores.Organizations.Create(ctx, &organization))
+
+			// Profile edits of copies read before the accounts were suspended
+			volunteer.Name, volunteer.Created_At, volunteer.Suspended_At = "Annie", time.Now(), nil
+			require.NoError(t, stores.Volunteers.Update(ctx, &volunteer))
+			organization.Name, organization.Created_At, organization.Suspended_At = "Helping Hands", time.Now(), nil
+			require.NoError(t, stores.Organizations.Update(ctx, &organization))
+
+			gotVolunteer, err := stores.Volunteers.GetByEmail(ctx, volunteer.Email)
+			require.NoError(t, err)
+			assert.Equal(t, "Annie", gotVolunteer.Name)
+			assert.NotNil(t, gotVolunteer.Suspended_At)
+			assert.True(t, created.Equal(gotVolunteer.Created_At))
+
+			gotOrganization, err := stores.Organizations.Get(ctx, organization.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "Helping Hands", gotOrganization.Name)
+			assert.NotNil(t, gotOrganization.Suspended_At)
+			assert.True(t, created.Equal(gotOrganization.Created_At))
+		})
+	}
+}
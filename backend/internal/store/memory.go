@@ -0,0 +1,404 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/prathamrao021/HelperHub/internal/listing"
+	"github.com/prathamrao021/HelperHub/models"
+)
+
+// NewMemory returns stores that keep the records in memory, for tests. They enforce the same
+// unique keys and cascading deletes as the database schema. They hold no applications, since
+// those are only written through GORM.
+func NewMemory() Stores {
+	m := &memory{
+		volunteers:    newTable[models.Volunteer](),
+		organizations: newTable[models.Organization](),
+		opportunities: newTable[models.Opportunity](),
+		applications:  newTable[models.Application](),
+		categories:    newTable[models.Category](),
+	}
+	return Stores{
+		Volunteers:    memoryVolunteers{m},
+		Organizations: memoryOrganizations{m},
+		Opportunities: memoryOpportunities{m},
+		Applications:  memoryApplications{m},
+		Categories:    memoryCategories{m},
+	}
+}
+
+// memory holds the tables shared by the stores of NewMemory. One lock guards all of them, so
+// cascading deletes happen at once.
+type memory struct {
+	mu            sync.Mutex
+	volunteers    *table[models.Volunteer]
+	organizations *table[models.Organization]
+	opportunities *table[models.Opportunity]
+	applications  *table[models.Application]
+	categories    *table[models.Category]
+}
+
+// table is the rows of one kind of record by ID
+type table[T any] struct {
+	rows   map[uint]T
+	lastID uint
+}
+
+func newTable[T any]() *table[T] {
+	return &table[T]{rows: map[uint]T{}}
+}
+
+// insert stores a new row, assigning it the next ID unless it already has one
+func (t *table[T]) insert(id *uint, row func() T) error {
+	if *id == 0 {
+		*id = t.lastID + 1
+	}
+	if _, ok := t.rows[*id]; ok {
+		return ErrDuplicate
+	}
+	t.lastID = max(t.lastID, *id)
+	t.rows[*id] = row()
+	return nil
+}
+
+// replace overwrites an existing row
+func (t *table[T]) replace(id uint, row T) error {
+	if _, ok := t.rows[id]; !ok {
+		return ErrNotFound
+	}
+	t.rows[id] = row
+	return nil
+}
+
+func (t *table[T]) get(id uint) (T, error) {
+	row, ok := t.rows[id]
+	if !ok {
+		return row, ErrNotFound
+	}
+	return row, nil
+}
+
+// find returns the row with the lowest ID that matches
+func (t *table[T]) find(match func(T) bool) (T, error) {
+	for _, row := range t.list() {
+		if match(row) {
+			return row, nil
+		}
+	}
+	var zero T
+	return zero, ErrNotFound
+}
+
+// exists reports whether a row other than the one with the given ID matches
+func (t *table[T]) exists(exceptID uint, match func(T) bool) bool {
+	for id, row := range t.rows {
+		if id != exceptID && match(row) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *table[T]) remove(id uint) error {
+	if _, ok := t.rows[id]; !ok {
+		return ErrNotFound
+	}
+	delete(t.rows, id)
+	return nil
+}
+
+func (t *table[T]) removeWhere(match func(T) bool) {
+	for id, row := range t.rows {
+		if match(row) {
+			delete(t.rows, id)
+		}
+	}
+}
+
+// list returns the rows ordered by ID
+func (t *table[T]) list() []T {
+	ids := make([]uint, 0, len(t.rows))
+	for id := range t.rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	rows := make([]T, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, t.rows[id])
+	}
+	return rows
+}
+
+// page applies the list options to the rows that pass the filter
+func page[T any](rows []T, keep func(T) bool, options listing.Options) ([]T, int64) {
+	var kept []T
+	for _, row := range rows {
+		if keep(row) {
+			kept = append(kept, row)
+		}
+	}
+	items, total := options.Apply(kept)
+	return items.([]T), total
+}
+
+// isSet reports whether a nullable field passes a filter such as AccountFilter.Suspended
+func isSet[T any](value *T, set *bool) bool {
+	return set == nil || *set == (value != nil)
+}
+
+type memoryVolunteers struct{ m *memory }
+
+// cloneVolunteer copies the category list, so callers never share it with the store
+func cloneVolunteer(volunteer models.Volunteer) models.Volunteer {
+	volunteer.Category_List = slices.Clone(volunteer.Category_List)
+	return volunteer
+}
+
+// conflicts reports whether another volunteer has the same email or phone
+func (s memoryVolunteers) conflicts(volunteer *models.Volunteer) bool {
+	return s.m.volunteers.exists(volunteer.ID, func(other models.Volunteer) bool {
+		return other.Email == volunteer.Email || other.Phone == volunteer.Phone
+	})
+}
+
+func (s memoryVolunteers) Create(ctx context.Context, volunteer *models.Volunteer) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if s.conflicts(volunteer) {
+		return ErrDuplicate
+	}
+	return s.m.volunteers.insert(&volunteer.ID, func() models.Volunteer { return cloneVolunteer(*volunteer) })
+}
+
+func (s memoryVolunteers) GetByEmail(ctx context.Context, email string) (models.Volunteer, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	volunteer, err := s.m.volunteers.find(func(v models.Volunteer) bool { return v.Email == email })
+	return cloneVolunteer(volunteer), err
+}
+
+func (s memoryVolunteers) Update(ctx context.Context, volunteer *models.Volunteer) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	current, err := s.m.volunteers.get(volunteer.ID)
+	if err != nil {
+		return err
+	}
+	if s.conflicts(volunteer) {
+		return ErrDuplicate
+	}
+
+	updated := cloneVolunteer(*volunteer)
+	updated.Created_At, updated.Suspended_At = current.Created_At, current.Suspended_At
+	return s.m.volunteers.replace(volunteer.ID, updated)
+}
+
+func (s memoryVolunteers) Delete(ctx context.Context, id uint) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if err := s.m.volunteers.remove(id); err != nil {
+		return err
+	}
+	s.m.applications.removeWhere(func(a models.Application) bool { return a.Volunteer_ID == id })
+	return nil
+}
+
+func (s memoryVolunteers) List(ctx context.Context, filter AccountFilter, options listing.Options) ([]models.Volunteer, int64, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	volunteers, total := page(s.m.volunteers.list(), func(v models.Volunteer) bool {
+		return isSet(v.Suspended_At, filter.Suspended)
+	}, options)
+	for i := range volunteers {
+		volunteers[i] = cloneVolunteer(volunteers[i])
+	}
+	return volunteers, total, nil
+}
+
+type memoryOrganizations struct{ m *memory }
+
+// conflicts reports whether another organization has the same email or name
+func (s memoryOrganizations) conflicts(organization *models.Organization) bool {
+	return s.m.organizations.exists(organization.ID, func(other models.Organization) bool {
+		return other.Email == organization.Email || other.Name == organization.Name
+	})
+}
+
+func (s memoryOrganizations) Create(ctx context.Context, organization *models.Organization) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if s.conflicts(organization) {
+		return ErrDuplicate
+	}
+	return s.m.organizations.insert(&organization.ID, func() models.Organization { return *organization })
+}
+
+func (s memoryOrganizations) Get(ctx context.Context, id uint) (models.Organization, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	return s.m.organizations.get(id)
+}
+
+func (s memoryOrganizations) GetByEmail(ctx context.Context, email string) (models.Organization, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	return s.m.organizations.find(func(o models.Organization) bool { return o.Email == email })
+}
+
+func (s memoryOrganizations) Update(ctx context.Context, organization *models.Organization) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	current, err := s.m.organizations.get(organization.ID)
+	if err != nil {
+		return err
+	}
+	if s.conflicts(organization) {
+		return ErrDuplicate
+	}
+	for id, opportunity := range s.m.opportunities.rows {
+		if opportunity.Organization_mail == current.Email {
+			opportunity.Organization_mail = organization.Email
+			s.m.opportunities.rows[id] = opportunity
+		}
+	}
+
+	updated := *organization
+	updated.Created_At, updated.Suspended_At = current.Created_At, current.Suspended_At
+	return s.m.organizations.replace(organization.ID, updated)
+}
+
+func (s memoryOrganizations) Delete(ctx context.Context, id uint) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	return s.m.organizations.remove(id)
+}
+
+func (s memoryOrganizations) List(ctx context.Context, filter AccountFilter, options listing.Options) ([]models.Organization, int64, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	organizations, total := page(s.m.organizations.list(), func(o models.Organization) bool {
+		return isSet(o.Suspended_At, filter.Suspended)
+	}, options)
+	return organizations, total, nil
+}
+
+type memoryOpportunities struct{ m *memory }
+
+func (s memoryOpportunities) Create(ctx context.Context, opportunity *models.Opportunity) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	return s.m.opportunities.insert(&opportunity.ID, func() models.Opportunity { return *opportunity })
+}
+
+func (s memoryOpportunities) Get(ctx context.Context, id uint) (models.Opportunity, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	return s.m.opportunities.get(id)
+}
+
+func (s memoryOpportunities) Update(ctx context.Context, opportunity *models.Opportunity) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	current, err := s.m.opportunities.get(opportunity.ID)
+	if err != nil {
+		return err
+	}
+
+	updated := *opportunity
+	updated.Created_At = current.Created_At
+	return s.m.opportunities.replace(opportunity.ID, updated)
+}
+
+func (s memoryOpportunities) Delete(ctx context.Context, id uint) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if err := s.m.opportunities.remove(id); err != nil {
+		return err
+	}
+	s.m.applications.removeWhere(func(a models.Application) bool { return a.Opportunity_ID == id })
+	return nil
+}
+
+func (s memoryOpportunities) List(ctx context.Context, filter OpportunityFilter, options listing.Options) ([]models.Opportunity, int64, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	opportunities, total := page(s.m.opportunities.list(), func(o models.Opportunity) bool {
+		return isSet(o.Hidden_At, filter.Hidden)
+	}, options)
+	return opportunities, total, nil
+}
+
+type memoryApplications struct{ m *memory }
+
+// add stores an application as it is. Applications are only written through GORM, so this is
+// how the tests of the package put them in.
+func (s memoryApplications) add(application *models.Application) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	return s.m.applications.insert(&application.ID, func() models.Application { return *application })
+}
+
+func (s memoryApplications) Get(ctx context.Context, id uint) (models.Application, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	return s.m.applications.get(id)
+}
+
+func (s memoryApplications) List(ctx context.Context, filter ApplicationFilter, options listing.Options) ([]models.Application, int64, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	applications, total := page(s.m.applications.list(), func(a models.Application) bool {
+		return filter.Status == "" || a.Status == filter.Status
+	}, options)
+	return applications, total, nil
+}
+
+type memoryCategories struct{ m *memory }
+
+func (s memoryCategories) Create(ctx context.Context, category *models.Category) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if s.m.categories.exists(category.ID, func(other models.Category) bool { return other.Category == category.Category }) {
+		return ErrDuplicate
+	}
+	return s.m.categories.insert(&category.ID, func() models.Category { return *category })
+}
+
+func (s memoryCategories) GetByName(ctx context.Context, name string) (models.Category, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	return s.m.categories.find(func(c models.Category) bool { return c.Category == name })
+}
+
+func (s memoryCategories) List(ctx context.Context, options listing.Options) ([]models.Category, int64, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	categories, total := page(s.m.categories.list(), func(models.Category) bool { return true }, options)
+	return categories, total, nil
+}
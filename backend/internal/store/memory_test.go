@@ -0,0 +1,161 @@
+package store
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prathamrao021/HelperHub/internal/listing"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSpec = listing.Spec{
+	Fields: map[string]listing.Field{
+		"id":   {Column: "id", Type: listing.Number},
+		"name": {Column: "name", Type: listing.String},
+	},
+	DefaultSort: "id",
+	Key:         "id",
+}
+
+func options(t *testing.T, query string) listing.Options {
+	values, err := url.ParseQuery(query)
+	require.NoError(t, err)
+	options, err := listing.Parse(values, testSpec)
+	require.NoError(t, err)
+	return options
+}
+
+func TestMemoryVolunteers(t *testing.T) {
+	ctx := context.Background()
+	volunteers := NewMemory().Volunteers
+
+	ann := models.Volunteer{Email: "ann@example.com", Name: "Ann", Phone: "1", Category_List: models.StringList{"Education"}}
+	require.NoError(t, volunteers.Create(ctx, &ann))
+	assert.Equal(t, uint(1), ann.ID)
+
+	t.Run("Duplicate", func(t *testing.T) {
+		duplicate := models.Volunteer{Email: "ann@example.com", Phone: "2"}
+		assert.ErrorIs(t, volunteers.Create(ctx, &duplicate), ErrDuplicate)
+	})
+
+	t.Run("Copies", func(t *testing.T) {
+		got, err := volunteers.GetByEmail(ctx, "ann@example.com")
+		require.NoError(t, err)
+		got.Category_List[0] = "Health"
+
+		again, err := volunteers.GetByEmail(ctx, "ann@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, models.StringList{"Education"}, again.Category_List)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		bob := models.Volunteer{Email: "bob@example.com", Name: "Bob", Phone: "2"}
+		require.NoError(t, volunteers.Create(ctx, &bob))
+
+		bob.Phone = "1"
+		assert.ErrorIs(t, volunteers.Update(ctx, &bob), ErrDuplicate)
+
+		bob.Phone, bob.Name = "3", "Robert"
+		require.NoError(t, volunteers.Update(ctx, &bob))
+		got, err := volunteers.GetByEmail(ctx, "bob@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "Robert", got.Name)
+
+		missing := models.Volunteer{ID: 99, Email: "missing@example.com", Phone: "99"}
+		assert.ErrorIs(t, volunteers.Update(ctx, &missing), ErrNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		suspended := true
+		now := time.Now()
+		carl := models.Volunteer{Email: "carl@example.com", Name: "Carl", Phone: "4", Suspended_At: &now}
+		require.NoError(t, volunteers.Create(ctx, &carl))
+
+		list, total, err := volunteers.List(ctx, AccountFilter{Suspended: &suspended}, options(t, ""))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, "Carl", list[0].Name)
+
+		list, total, err = volunteers.List(ctx, AccountFilter{}, options(t, "sort=-name&limit=1"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		require.Len(t, list, 2) // One more than the limit, as there is a next page
+		assert.Equal(t, "Robert", list[0].Name)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, volunteers.Delete(ctx, ann.ID))
+		_, err := volunteers.GetByEmail(ctx, "ann@example.com")
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.ErrorIs(t, volunteers.Delete(ctx, ann.ID), ErrNotFound)
+	})
+}
+
+func TestMemoryOrganizationEmailChange(t *testing.T) {
+	ctx := context.Background()
+	stores := NewMemory()
+
+	organization := models.Organization{Email: "old@org.com", Name: "Helpers"}
+	require.NoError(t, stores.Organizations.Create(ctx, &organization))
+	opportunity := models.Opportunity{Organization_mail: "old@org.com", Title: "Tutoring"}
+	require.NoError(t, stores.Opportunities.Create(ctx, &opportunity))
+	other := models.Opportunity{Organization_mail: "other@org.com", Title: "Cleanup"}
+	require.NoError(t, stores.Opportunities.Create(ctx, &other))
+
+	organization.Email = "new@org.com"
+	require.NoError(t, stores.Organizations.Update(ctx, &organization))
+
+	moved, err := stores.Opportunities.Get(ctx, opportunity.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new@org.com", moved.Organization_mail)
+	unchanged, err := stores.Opportunities.Get(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "other@org.com", unchanged.Organization_mail)
+}
+
+func TestMemoryApplications(t *testing.T) {
+	ctx := context.Background()
+	stores := NewMemory()
+	applications := stores.Applications.(memoryApplications)
+
+	volunteer := models.Volunteer{Email: "ann@example.com", Phone: "1"}
+	require.NoError(t, stores.Volunteers.Create(ctx, &volunteer))
+	opportunity := models.Opportunity{Title: "Tutoring"}
+	require.NoError(t, stores.Opportunities.Create(ctx, &opportunity))
+
+	application := models.Application{Volunteer_ID: volunteer.ID, Opportunity_ID: opportunity.ID, Status: "Pending"}
+	require.NoError(t, applications.add(&application))
+	require.NoError(t, applications.add(&models.Application{Volunteer_ID: volunteer.ID, Opportunity_ID: opportunity.ID, Status: "Accepted"}))
+
+	t.Run("Filter", func(t *testing.T) {
+		list, total, err := stores.Applications.List(ctx, ApplicationFilter{Status: "Pending"}, options(t, ""))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, application.ID, list[0].ID)
+	})
+
+	t.Run("Cascade", func(t *testing.T) {
+		require.NoError(t, stores.Opportunities.Delete(ctx, opportunity.ID))
+		_, err := stores.Applications.Get(ctx, application.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestMemoryCategories(t *testing.T) {
+	ctx := context.Background()
+	categories := NewMemory().Categories
+
+	require.NoError(t, categories.Create(ctx, &models.Category{Category: "Education"}))
+	assert.ErrorIs(t, categories.Create(ctx, &models.Category{Category: "Education"}), ErrDuplicate)
+
+	category, err := categories.GetByName(ctx, "Education")
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), category.ID)
+
+	_, err = categories.GetByName(ctx, "Health")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
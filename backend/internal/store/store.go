@@ -0,0 +1,98 @@
+// Package store defines how the handlers that only need single records or plain lists read and
+// write volunteers, organizations, opportunities and categories, and read applications.
+// Applications are written, and other records joined or updated together in one transaction,
+// with GORM directly instead. NewGorm keeps the records in the database; NewMemory keeps them in
+// memory, so the handlers and ownership checks that only use stores can be tested without one.
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/prathamrao021/HelperHub/internal/listing"
+	"github.com/prathamrao021/HelperHub/models"
+)
+
+// Errors returned by every store implementation
+var (
+	ErrNotFound         = errors.New("record not found")
+	ErrDuplicate        = errors.New("record already exists")
+	ErrInvalidReference = errors.New("referenced record does not exist")
+)
+
+// AccountFilter narrows a list of volunteer or organization accounts
+type AccountFilter struct {
+	Suspended *bool // Only suspended accounts when true, only active ones when false
+}
+
+// OpportunityFilter narrows a list of opportunities
+type OpportunityFilter struct {
+	Hidden *bool // Only hidden opportunities when true, only visible ones when false
+}
+
+// ApplicationFilter narrows a list of applications
+type ApplicationFilter struct {
+	Status string // Only applications with this status, when set
+}
+
+// Update methods write every field of a record that was read from the store, except the time it
+// was created and, for accounts, the time it was suspended. Admins suspend accounts with their
+// own requests, so that a profile update made meanwhile does not undo the suspension.
+
+// List methods return the page of the records that pass the filter and the list options,
+// with one record more than the limit when there is a next page (see listing.Options.Page),
+// and the number of records that pass the filters.
+
+// VolunteerStore reads and writes volunteers. Deleting a volunteer deletes their applications.
+type VolunteerStore interface {
+	Create(ctx context.Context, volunteer *models.Volunteer) error
+	GetByEmail(ctx context.Context, email string) (models.Volunteer, error)
+	Update(ctx context.Context, volunteer *models.Volunteer) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, filter AccountFilter, options listing.Options) ([]models.Volunteer, int64, error)
+}
+
+// OrganizationStore reads and writes organizations. Opportunities belong to the email of the
+// organization that posted them, so updating the email of an organization moves its
+// opportunities to the new email.
+type OrganizationStore interface {
+	Create(ctx context.Context, organization *models.Organization) error
+	Get(ctx context.Context, id uint) (models.Organization, error)
+	GetByEmail(ctx context.Context, email string) (models.Organization, error)
+	Update(ctx context.Context, organization *models.Organization) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, filter AccountFilter, options listing.Options) ([]models.Organization, int64, error)
+}
+
+// OpportunityStore reads and writes opportunities. Deleting an opportunity deletes its
+// applications.
+type OpportunityStore interface {
+	Create(ctx context.Context, opportunity *models.Opportunity) error
+	Get(ctx context.Context, id uint) (models.Opportunity, error)
+	Update(ctx context.Context, opportunity *models.Opportunity) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, filter OpportunityFilter, options listing.Options) ([]models.Opportunity, int64, error)
+}
+
+// ApplicationStore reads applications. They are written with GORM, in transactions that also
+// lock the opportunity and record the status history.
+type ApplicationStore interface {
+	Get(ctx context.Context, id uint) (models.Application, error)
+	List(ctx context.Context, filter ApplicationFilter, options listing.Options) ([]models.Application, int64, error)
+}
+
+// CategoryStore reads and writes categories
+type CategoryStore interface {
+	Create(ctx context.Context, category *models.Category) error
+	GetByName(ctx context.Context, name string) (models.Category, error)
+	List(ctx context.Context, options listing.Options) ([]models.Category, int64, error)
+}
+
+// Stores bundles one store of each kind, all backed by the same database
+type Stores struct {
+	Volunteers    VolunteerStore
+	Organizations OrganizationStore
+	Opportunities OpportunityStore
+	Applications  ApplicationStore
+	Categories    CategoryStore
+}
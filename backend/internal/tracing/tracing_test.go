@@ -0,0 +1,139 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordSpans sends the spans ended during the test to the returned recorder
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+	return recorder
+}
+
+// attributes returns the attributes of a span by key
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	values := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		values[kv.Key] = kv.Value
+	}
+	return values
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := recordSpans(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/items/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		c.Error(errors.New("database is closed"))
+		c.Status(http.StatusInternalServerError)
+	})
+
+	// The trace of the caller is continued
+	req := httptest.NewRequest("GET", "/items/7", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	span := spans[0]
+	assert.Equal(t, "GET /items/:id", span.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.Equal(t, "/items/:id", attributes(span)["http.route"].AsString())
+	assert.Equal(t, "/items/7", attributes(span)["url.path"].AsString())
+	assert.Equal(t, int64(http.StatusNoContent), attributes(span)["http.response.status_code"].AsInt64())
+	assert.Equal(t, codes.Unset, span.Status().Code)
+
+	span = spans[1]
+	assert.False(t, span.Parent().IsValid())
+	assert.Equal(t, codes.Error, span.Status().Code)
+	require.Len(t, span.Events(), 1)
+	assert.Equal(t, "exception", span.Events()[0].Name)
+}
+
+func TestGormPlugin(t *testing.T) {
+	recorder := recordSpans(t)
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// Every connection to :memory: is a new database
+	sqlDB.SetMaxOpenConns(1)
+	defer sqlDB.Close()
+	require.NoError(t, db.Use(GormPlugin{}))
+	require.NoError(t, db.Exec("CREATE TABLE widgets (id integer PRIMARY KEY, name text)").Error)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "GET /widgets")
+	var name string
+	err = db.WithContext(ctx).Table("widgets").Select("name").Where("id = ?", 1).Take(&name).Error
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Error(t, db.WithContext(ctx).Table("missing").Create(map[string]interface{}{"id": 1}).Error)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+
+	// Queries made with the context of a span are its children
+	query := spans[1]
+	assert.Equal(t, "query widgets", query.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent().SpanID())
+	assert.Equal(t, "sqlite", attributes(query)["db.system"].AsString())
+	assert.Equal(t, "widgets", attributes(query)["db.collection.name"].AsString())
+	assert.Equal(t, "SELECT name FROM `widgets` WHERE id = ? LIMIT 1", attributes(query)["db.query.text"].AsString())
+	assert.Equal(t, codes.Unset, query.Status().Code, "a missing record is not an error")
+
+	create := spans[2]
+	assert.Equal(t, "create missing", create.Name())
+	assert.Equal(t, codes.Error, create.Status().Code)
+}
+
+func TestSetupFile(t *testing.T) {
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+
+	cfg := config.Default().Tracing
+	cfg.Exporter = config.ExporterFile
+	cfg.File = filepath.Join(t.TempDir(), "traces.jsonl")
+
+	shutdown, err := Setup(context.Background(), cfg)
+	require.NoError(t, err)
+	_, span := tracer().Start(context.Background(), "GET /opportunities/:opportunity_id")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	content, err := os.ReadFile(cfg.File)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"Name":"GET /opportunities/:opportunity_id"`)
+	assert.Contains(t, string(content), `"Value":"helperhub"`)
+}
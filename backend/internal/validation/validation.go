@@ -0,0 +1,144 @@
+// Package validation adds the rules request bodies are checked against to the validator Gin
+// binds them with, and turns what fails into one message per field for clients
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/prathamrao021/HelperHub/models"
+)
+
+// FieldError is a field of a request body that failed validation. Field is the JSON path of
+// the field, like "email" or "category_list[1]".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// phoneFormat allows an optional leading + and digits grouped with spaces, dots, dashes or
+// parentheses
+var phoneFormat = regexp.MustCompile(`^\+?[0-9 ().-]+$`)
+
+// Register adds the custom rules to the validator and makes it name fields by their JSON names:
+//
+//   - phone: a phone number of 7 to 15 digits, like "+1 (555) 123-4567"
+func Register(v *validator.Validate) error {
+	v.RegisterTagNameFunc(jsonName)
+	return v.RegisterValidation("phone", isPhone)
+}
+
+// jsonName returns the name of the field in JSON, or nothing for fields that are left out of it
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// isPhone checks the phone rule
+func isPhone(fl validator.FieldLevel) bool {
+	phone := fl.Field().String()
+	if !phoneFormat.MatchString(phone) {
+		return false
+	}
+	digits := 0
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits >= 7 && digits <= 15
+}
+
+// FieldErrors returns the fields an error from binding a request body is about: every field
+// that failed validation, or the field that had the wrong type. It reports false for other
+// errors, like malformed JSON, and for values of the wrong type that the decoder did not name
+// the field of, which it does not for values that failed their own UnmarshalJSON.
+func FieldErrors(err error) ([]FieldError, bool) {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fields := make([]FieldError, 0, len(validationErrors))
+		for _, e := range validationErrors {
+			fields = append(fields, FieldError{Field: fieldPath(e), Message: message(e)})
+		}
+		return fields, true
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		return []FieldError{{Field: typeError.Field, Message: TypeMessage(typeError.Type)}}, true
+	}
+	return nil, false
+}
+
+// fieldPath returns the path of the field without the name of the request type
+func fieldPath(e validator.FieldError) string {
+	_, path, found := strings.Cut(e.Namespace(), ".")
+	if !found {
+		return e.Field()
+	}
+	return path
+}
+
+// message describes the rule the field failed
+func message(e validator.FieldError) string {
+	switch e.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "phone":
+		return "must be a phone number of 7 to 15 digits"
+	case "http_url", "url":
+		return "must be a valid http or https URL"
+	case "min":
+		if e.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", e.Param())
+		}
+		return fmt.Sprintf("must be at least %s", e.Param())
+	case "max":
+		if e.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", e.Param())
+		}
+		return fmt.Sprintf("must be at most %s", e.Param())
+	case "gtfield":
+		return "must be after " + strings.ToLower(e.Param())
+	case "gtefield":
+		return "must not be before " + strings.ToLower(e.Param())
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(e.Param()), ", ")
+	}
+	return fmt.Sprintf("failed the %s rule", e.Tag())
+}
+
+// TypeMessage describes the type a value must have
+func TypeMessage(t reflect.Type) string {
+	if t == reflect.TypeOf(models.CustomDate{}) {
+		return "must be a date formatted as YYYY-MM-DD"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "must be a string"
+	case reflect.Bool:
+		return "must be true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "must be a whole number"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "must be a whole number that is not negative"
+	case reflect.Float32, reflect.Float64:
+		return "must be a number"
+	case reflect.Slice, reflect.Array:
+		return "must be a list"
+	}
+	return "must be an object"
+}
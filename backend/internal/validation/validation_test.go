@@ -0,0 +1,96 @@
+package validation
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testRequest struct {
+	Email      string            `json:"email" validate:"required,email"`
+	Phone      string            `json:"phone" validate:"omitempty,phone"`
+	Website    string            `json:"website_url" validate:"omitempty,http_url"`
+	Hours      *uint             `json:"hours" validate:"omitnil,min=1"`
+	Tags       []string          `json:"tags" validate:"dive,required"`
+	Start_Date models.CustomDate `json:"start_date" validate:"required"`
+	End_Date   models.CustomDate `json:"end_date" validate:"required,gtefield=Start_Date"`
+}
+
+func newValidator(t *testing.T) *validator.Validate {
+	v := validator.New()
+	require.NoError(t, Register(v))
+	return v
+}
+
+func TestPhone(t *testing.T) {
+	v := newValidator(t)
+	for _, phone := range []string{"1234567", "+1 (555) 123-4567", "555.123.4567", "+441632960961"} {
+		assert.NoError(t, v.Var(phone, "phone"), phone)
+	}
+	for _, phone := range []string{"123456", "1234567890123456", "555-CALL-NOW", "12 34 56 ext 7", "+"} {
+		assert.Error(t, v.Var(phone, "phone"), phone)
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	v := newValidator(t)
+	zero := uint(0)
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	valid := testRequest{
+		Email:      "someone@example.com",
+		Phone:      "555-123-4567",
+		Website:    "https://example.com",
+		Tags:       []string{"Education"},
+		Start_Date: models.CustomDate(day),
+		End_Date:   models.CustomDate(day),
+	}
+	assert.NoError(t, v.Struct(valid), "Opportunities can start and end on the same day")
+
+	fields, ok := FieldErrors(v.Struct(testRequest{
+		Email:      "someone",
+		Phone:      "call me",
+		Website:    "example.com",
+		Hours:      &zero,
+		Tags:       []string{"Education", ""},
+		Start_Date: models.CustomDate(day),
+		End_Date:   models.CustomDate(day.AddDate(0, 0, -1)),
+	}))
+	require.True(t, ok)
+	assert.Equal(t, []FieldError{
+		{Field: "email", Message: "must be a valid email address"},
+		{Field: "phone", Message: "must be a phone number of 7 to 15 digits"},
+		{Field: "website_url", Message: "must be a valid http or https URL"},
+		{Field: "hours", Message: "must be at least 1"},
+		{Field: "tags[1]", Message: "is required"},
+		{Field: "end_date", Message: "must not be before start_date"},
+	}, fields)
+
+	fields, ok = FieldErrors(v.Struct(testRequest{}))
+	require.True(t, ok)
+	assert.Equal(t, []FieldError{
+		{Field: "email", Message: "is required"},
+		{Field: "start_date", Message: "is required"},
+		{Field: "end_date", Message: "is required"},
+	}, fields)
+}
+
+func TestFieldErrorsWrongType(t *testing.T) {
+	var request testRequest
+	fields, ok := FieldErrors(json.Unmarshal([]byte(`{"hours": -1}`), &request))
+	require.True(t, ok)
+	assert.Equal(t, []FieldError{{Field: "hours", Message: "must be a whole number that is not negative"}}, fields)
+
+	var typeError *json.UnmarshalTypeError
+	err := json.Unmarshal([]byte(`{"start_date": "March 1"}`), &request)
+	require.ErrorAs(t, err, &typeError)
+	assert.Equal(t, "must be a date formatted as YYYY-MM-DD", TypeMessage(typeError.Type))
+
+	_, ok = FieldErrors(json.Unmarshal([]byte(`{"email": `), &request))
+	assert.False(t, ok, "Malformed JSON is not about a field")
+}
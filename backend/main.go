@@ -1,33 +1,132 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"crypto/rand"
+	"errors"
 	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	docs "github.com/prathamrao021/HelperHub/docs"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/internal/config"
+	"github.com/prathamrao021/HelperHub/internal/database"
+	"github.com/prathamrao021/HelperHub/internal/geo"
+	"github.com/prathamrao021/HelperHub/internal/metrics"
+	"github.com/prathamrao021/HelperHub/internal/migrate"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/internal/tracing"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"github.com/prathamrao021/HelperHub/migrations"
 	"github.com/prathamrao021/HelperHub/models"
 	"github.com/prathamrao021/HelperHub/routes"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"gorm.io/driver/postgres"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
-func initDB() *gorm.DB {
-	var err error
-	dsn := "host=localhost user=postgres password=admin dbname=Helperhub port=5432 sslmode=prefer TimeZone=Asia/Shanghai"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// loadConfig reads the settings from the environment and from the file named by CONFIG_FILE,
+// if any
+func loadConfig() *config.Config {
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatal("Invalid configuration: ", err)
+	}
+	return cfg
+}
+
+// openDB connects to the configured database, PostgreSQL or SQLite
+func openDB(cfg config.Database) *gorm.DB {
+	db, err := gorm.Open(database.Dialector(cfg), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	db.AutoMigrate(&models.User{}, &models.Volunteer{}, &models.Organization{}, &models.Category{}, &models.Opportunity{}, &models.Application{})
-	fmt.Println("Database connection successfully opened")
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal("Failed to configure the connection pool:", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime.Duration)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime.Duration)
 	return db
 }
 
+// initDB opens the database and refuses to start unless its schema is at the version this
+// build expects. The schema is changed with the migrate subcommand, never on startup. The
+// migrator is returned for the readiness probe to check the version again.
+func initDB(cfg config.Database) (*gorm.DB, *migrate.Migrator) {
+	db := openDB(cfg)
+
+	migrator, err := migrate.New(db, migrations.For(cfg.Driver))
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+	if err := migrator.Check(); err != nil {
+		log.Fatalf("%v. Run \"go run . migrate up\" to migrate the database.", err)
+	}
+
+	log.Print("Database connection successfully opened")
+	return db, migrator
+}
+
+func initTokens(cfg config.Auth) *auth.TokenManager {
+	secret := []byte(cfg.JWTSecret)
+	if len(secret) == 0 {
+		// Without a configured secret, sessions do not survive a restart
+		log.Print("JWT_SECRET is not set, using a random signing key")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatal("Failed to generate signing key:", err)
+		}
+	}
+	return auth.NewTokenManager(secret, cfg.AccessTokenTTL.Duration, cfg.RefreshTokenTTL.Duration)
+}
+
+// seedAdmin creates the first admin account from the configured credentials when no admin
+// exists yet. Further admins are created through the /admin/users API.
+func seedAdmin(db *gorm.DB, cfg *config.Config) {
+	email, password := cfg.Admin.Email, cfg.Admin.Password
+	if email == "" {
+		return
+	}
+
+	var count int64
+	if err := db.Model(&models.User{}).Where("role = ?", auth.RoleAdmin).Count(&count).Error; err != nil {
+		log.Fatal("Failed to look up admin accounts:", err)
+	}
+	if count > 0 {
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), cfg.Auth.BcryptCost)
+	if err != nil {
+		log.Fatal("Failed to hash admin password:", err)
+	}
+
+	admin := models.User{
+		Email:         email,
+		Password_Hash: string(hashedPassword),
+		Full_Name:     "Administrator",
+		Role:          auth.RoleAdmin,
+		Created_At:    time.Now(),
+		Updated_At:    time.Now(),
+	}
+	if err := db.Create(&admin).Error; err != nil {
+		log.Fatal("Failed to create admin account:", err)
+	}
+	log.Printf("Created admin account %s", email)
+}
+
 // @title           HELPERHUB API
 // @version         1.0
 // @description     This is a sample server celler server.
@@ -42,36 +141,121 @@ func initDB() *gorm.DB {
 
 // @host      localhost:8080
 
-// @securityDefinitions.basic  BasicAuth
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
 
 // @externalDocs.description  OpenAPI
 // @externalDocs.url          https://swagger.io/resources/open-api/
 
 func main() {
-	router := gin.Default()
+	cfg := loadConfig()
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(cfg, os.Args[2:])
+		return
+	}
+
+	// Logs are JSON records on stdout, including those written with the log package
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatal("Failed to set up tracing:", err)
+	}
+
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.Logger(logger), middleware.Recovery(logger))
+	router.Use(metrics.Middleware(), tracing.Middleware())
 
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"},
+		AllowOrigins:     cfg.CORS.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", middleware.RequestIDHeader, "traceparent", "tracestate"},
+		ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader},
+		AllowCredentials: cfg.CORS.AllowCredentials,
 	}))
 
-	db := initDB()
+	db, migrator := initDB(cfg.Database)
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		log.Fatal("Failed to install database metrics:", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		log.Fatal("Failed to install database tracing:", err)
+	}
+	metrics.Registry.MustRegister(metrics.NewDomainCollector(db))
+	seedAdmin(db, cfg)
 	router.Use(func(c *gin.Context) {
 		c.Set("db", db)
 		c.Next()
 	})
 
+	stores := store.NewGorm(db)
+
 	// Initialize static categories
-	// routes.CreateCategory(nil, db)
+	// routes.CreateCategory(nil, stores.Categories)
+
+	routes.SetupHealthRoutes(router, db, migrator)
+	routes.SetupRoutes(router, db, stores, cfg, initTokens(cfg.Auth), geo.NewStaticGeocoder(geo.Places))
 
-	routes.SetupRoutes(router, db)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.NoRoute(func(c *gin.Context) {
+		middleware.RespondProblem(c, http.StatusNotFound, "No route matches "+c.Request.Method+" "+c.Request.URL.Path)
+	})
 
 	docs.SwaggerInfo.BasePath = "/"
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	log.Print("Starting server on port 8080")
-	router.Run(":8080")
+	server := &http.Server{
+		Addr:         cfg.Server.Addr(),
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout.Duration,
+		WriteTimeout: cfg.Server.WriteTimeout.Duration,
+		IdleTimeout:  cfg.Server.IdleTimeout.Duration,
+	}
+	serve(server, cfg.Server.ShutdownTimeout.Duration)
+
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	// Export the spans of the last requests
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Duration)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		log.Print("Failed to export traces: ", err)
+	}
+}
+
+// serve runs the server until SIGTERM or an interrupt, then stops accepting connections and
+// waits up to shutdownTimeout for the requests in flight to finish
+func serve(server *http.Server, shutdownTimeout time.Duration) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Printf("Starting server on %s", server.Addr)
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		log.Fatal("Server failed: ", err)
+	case <-ctx.Done():
+	}
+	// A second signal kills the server without waiting
+	stop()
+
+	log.Printf("Shutting down, waiting up to %s for requests in flight", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Print("Shutdown did not complete: ", err)
+	}
+	if err := <-errs; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Print("Server failed: ", err)
+	}
+	log.Print("Server stopped")
 }
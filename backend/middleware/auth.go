@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+)
+
+// PrincipalKey is the gin.Context key under which the authenticated principal is stored
+const PrincipalKey = "principal"
+
+// RequireAuth rejects requests without a valid "Authorization: Bearer <token>" header
+// and stores the authenticated principal in the context
+func RequireAuth(tokens *auth.TokenManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			AbortWithProblem(c, http.StatusUnauthorized, "Missing or malformed Authorization header")
+			return
+		}
+
+		principal, err := tokens.ParseAccessToken(strings.TrimSpace(token))
+		if err != nil {
+			AbortWithProblem(c, http.StatusUnauthorized, "Invalid or expired token")
+			return
+		}
+
+		c.Set(PrincipalKey, principal)
+		c.Next()
+	}
+}
+
+// CurrentPrincipal returns the principal stored by RequireAuth, if any
+func CurrentPrincipal(c *gin.Context) (*auth.Principal, bool) {
+	value, exists := c.Get(PrincipalKey)
+	if !exists {
+		return nil, false
+	}
+	principal, ok := value.(*auth.Principal)
+	return principal, ok
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRouterForAuth(tokens *auth.TokenManager) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	r.GET("/whoami", RequireAuth(tokens), func(c *gin.Context) {
+		principal, ok := CurrentPrincipal(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "principal missing"})
+			return
+		}
+		c.JSON(http.StatusOK, principal)
+	})
+
+	return r
+}
+
+func TestRequireAuthValidToken(t *testing.T) {
+	tokens := auth.NewTokenManager([]byte("test-secret"), time.Minute, time.Hour)
+	router := setupRouterForAuth(tokens)
+
+	token, _, _ := tokens.IssueAccessToken(auth.Principal{ID: 3, Email: "test@volunteer.com", Role: auth.RoleVolunteer})
+
+	req, _ := http.NewRequest("GET", "/whoami", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "test@volunteer.com")
+}
+
+func TestRequireAuthMissingHeader(t *testing.T) {
+	tokens := auth.NewTokenManager([]byte("test-secret"), time.Minute, time.Hour)
+	router := setupRouterForAuth(tokens)
+
+	req, _ := http.NewRequest("GET", "/whoami", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRequireAuthInvalidToken(t *testing.T) {
+	tokens := auth.NewTokenManager([]byte("test-secret"), time.Minute, time.Hour)
+	router := setupRouterForAuth(tokens)
+
+	req, _ := http.NewRequest("GET", "/whoami", nil)
+	req.Header.Set("Authorization", "Bearer not-a-token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
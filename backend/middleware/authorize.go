@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+)
+
+// ForbiddenMessage is the error returned for every request rejected by an authorization check
+const ForbiddenMessage = "You do not have permission to perform this action"
+
+// AbortForbidden stops the request with a 403 and the standard problem
+func AbortForbidden(c *gin.Context) {
+	AbortWithProblem(c, http.StatusForbidden, ForbiddenMessage)
+}
+
+// IsAdmin reports whether the principal is a platform admin
+func IsAdmin(p *auth.Principal) bool {
+	return p != nil && p.Role == auth.RoleAdmin
+}
+
+// RequireRole only lets principals with one of the given roles through. Admins are always allowed.
+// It must run after RequireAuth.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := CurrentPrincipal(c)
+		if !ok {
+			AbortWithProblem(c, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+		if IsAdmin(principal) {
+			c.Next()
+			return
+		}
+		for _, role := range roles {
+			if principal.Role == role {
+				c.Next()
+				return
+			}
+		}
+		AbortForbidden(c)
+	}
+}
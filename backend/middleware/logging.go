@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Logger writes one structured record per request once it has been handled: its ID, method,
+// route template, status and latency, and the authenticated principal, if any. Errors that
+// handlers attached with c.Error are included, and requests that failed with a 5xx status are
+// logged as errors.
+func Logger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("request_id", CurrentRequestID(c)),
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if principal, ok := CurrentPrincipal(c); ok {
+			attrs = append(attrs, slog.Group("principal", slog.Uint64("id", uint64(principal.ID)), slog.String("role", principal.Role)))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", strings.Join(c.Errors.Errors(), "; ")))
+		}
+
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery turns a panic in a handler into a 500 response carrying the request ID. The panic
+// and its stack are logged under the same ID.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			logger.Error("panic", "request_id", CurrentRequestID(c), "panic", fmt.Sprint(recovered), "stack", string(debug.Stack()))
+			c.Error(fmt.Errorf("panic: %v", recovered))
+			c.Abort()
+			RespondInternalError(c)
+		}()
+		c.Next()
+	}
+}
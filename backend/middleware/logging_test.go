@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRouterForLogging(logs *bytes.Buffer, tokens *auth.TokenManager) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewJSONHandler(logs, nil))
+
+	r := gin.New()
+	r.Use(RequestID(), Logger(logger), Recovery(logger))
+
+	r.GET("/items/:id", RequireAuth(tokens), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+	r.GET("/fail", func(c *gin.Context) {
+		c.Error(errors.New("relation \"items\" does not exist"))
+		RespondInternalError(c)
+	})
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	return r
+}
+
+// logRecords decodes the JSON log records written to logs
+func logRecords(t *testing.T, logs *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestRequestID(t *testing.T) {
+	router := setupRouterForLogging(&bytes.Buffer{}, nil)
+
+	t.Run("Generated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+		assert.Len(t, w.Header().Get(RequestIDHeader), 32)
+	})
+
+	t.Run("Propagated", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/fail", nil)
+		req.Header.Set(RequestIDHeader, "3f2c9a1e-7b4d-4e0a-9c1f-5d6e7f8a9b0c")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, "3f2c9a1e-7b4d-4e0a-9c1f-5d6e7f8a9b0c", w.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/fail", nil)
+		req.Header.Set(RequestIDHeader, "bad id\n")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Len(t, w.Header().Get(RequestIDHeader), 32)
+	})
+}
+
+func TestLogger(t *testing.T) {
+	var logs bytes.Buffer
+	tokens := auth.NewTokenManager([]byte("test-secret"), time.Minute, time.Hour)
+	router := setupRouterForLogging(&logs, tokens)
+
+	token, _, _ := tokens.IssueAccessToken(auth.Principal{ID: 3, Email: "test@volunteer.com", Role: auth.RoleVolunteer})
+	req := httptest.NewRequest("GET", "/items/7", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(RequestIDHeader, "request-1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	records := logRecords(t, &logs)
+	require.Len(t, records, 1)
+	record := records[0]
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "request-1", record["request_id"])
+	assert.Equal(t, "GET", record["method"])
+	assert.Equal(t, "/items/:id", record["route"])
+	assert.Equal(t, "/items/7", record["path"])
+	assert.Equal(t, float64(http.StatusOK), record["status"])
+	assert.Contains(t, record, "latency_ms")
+	assert.Equal(t, map[string]interface{}{"id": float64(3), "role": auth.RoleVolunteer}, record["principal"])
+}
+
+func TestLoggerErrors(t *testing.T) {
+	var logs bytes.Buffer
+	router := setupRouterForLogging(&logs, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/fail", nil))
+	requestID := w.Header().Get(RequestIDHeader)
+
+	// The client gets the request ID, and the log the error
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"instance":"/fail","code":"internal_error","request_id":"`+requestID+`"}`, w.Body.String())
+
+	record := logRecords(t, &logs)[0]
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Equal(t, requestID, record["request_id"])
+	assert.Equal(t, `relation "items" does not exist`, record["error"])
+	assert.NotContains(t, record, "principal")
+}
+
+func TestRecovery(t *testing.T) {
+	var logs bytes.Buffer
+	router := setupRouterForLogging(&logs, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "boom")
+
+	records := logRecords(t, &logs)
+	require.Len(t, records, 2)
+	assert.Equal(t, "boom", records[0]["panic"])
+	assert.Equal(t, records[0]["request_id"], records[1]["request_id"])
+	assert.Equal(t, float64(http.StatusInternalServerError), records[1]["status"])
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/validation"
+)
+
+// ProblemContentType is the media type of error responses (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// Problem codes, which tell clients what went wrong without parsing the detail
+const (
+	CodeBadRequest       = "bad_request"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeValidationFailed = "validation_failed"
+	CodeUnavailable      = "unavailable"
+	CodeInternal         = "internal_error"
+)
+
+// Problem is the body of every error response: an RFC 7807 problem details object with the
+// code of the problem and the ID of the request as extension members. Type is always
+// "about:blank", so Title is the status text and Code tells problems with the same status
+// apart. Problems with a request body list the fields that are wrong with it in Errors.
+type Problem struct {
+	Type      string                  `json:"type"`
+	Title     string                  `json:"title"`
+	Status    int                     `json:"status"`
+	Detail    string                  `json:"detail,omitempty"`
+	Instance  string                  `json:"instance,omitempty"`
+	Code      string                  `json:"code"`
+	RequestID string                  `json:"request_id,omitempty"`
+	Errors    []validation.FieldError `json:"errors,omitempty"`
+}
+
+// NewProblem returns the problem for a response to the request with the status. Detail is
+// shown to the client, so it must not contain internal errors.
+func NewProblem(c *gin.Context, status int, detail string) Problem {
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      problemCode(status),
+		RequestID: CurrentRequestID(c),
+	}
+}
+
+// problemCode returns the code of the problems with the status
+func problemCode(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// RespondProblem writes a problem response with the status and detail
+func RespondProblem(c *gin.Context, status int, detail string) {
+	RespondFieldProblem(c, status, detail, nil)
+}
+
+// RespondFieldProblem writes a problem response with the status and detail that lists the
+// fields of the request body that are wrong
+func RespondFieldProblem(c *gin.Context, status int, detail string, fields []validation.FieldError) {
+	problem := NewProblem(c, status, detail)
+	problem.Errors = fields
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(status, problem)
+}
+
+// AbortWithProblem writes a problem response with the status and detail and stops the
+// handlers that follow
+func AbortWithProblem(c *gin.Context, status int, detail string) {
+	c.Abort()
+	RespondProblem(c, status, detail)
+}
+
+// RespondInternalError writes the 500 response of a request that failed unexpectedly. It
+// carries the request ID rather than the error, which handlers attach with c.Error so that it
+// is logged under the same ID.
+func RespondInternalError(c *gin.Context) {
+	RespondProblem(c, http.StatusInternalServerError, "")
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRespondProblem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/items/:id", func(c *gin.Context) {
+		AbortWithProblem(c, http.StatusNotFound, "Item not found")
+	}, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+
+	req := httptest.NewRequest("GET", "/items/7", nil)
+	req.Header.Set(RequestIDHeader, "request-1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"detail":"Item not found","instance":"/items/7","code":"not_found","request_id":"request-1"}`, w.Body.String())
+}
+
+func TestProblemCodes(t *testing.T) {
+	codes := map[int]string{
+		http.StatusBadRequest:          CodeBadRequest,
+		http.StatusUnauthorized:        CodeUnauthorized,
+		http.StatusForbidden:           CodeForbidden,
+		http.StatusNotFound:            CodeNotFound,
+		http.StatusConflict:            CodeConflict,
+		http.StatusUnprocessableEntity: CodeValidationFailed,
+		http.StatusTooManyRequests:     CodeBadRequest,
+		http.StatusInternalServerError: CodeInternal,
+		http.StatusServiceUnavailable:  CodeUnavailable,
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	for status, code := range codes {
+		problem := NewProblem(c, status, "")
+		assert.Equal(t, code, problem.Code, "status %d", status)
+		assert.Equal(t, http.StatusText(status), problem.Title)
+	}
+}
+
+func TestRespondFieldProblem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/items", func(c *gin.Context) {
+		RespondFieldProblem(c, http.StatusUnprocessableEntity, "The request body is invalid", []validation.FieldError{
+			{Field: "email", Message: "must be a valid email address"},
+			{Field: "tags[1]", Message: "is required"},
+		})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/items", nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"The request body is invalid","instance":"/items","code":"validation_failed",`+
+		`"errors":[{"field":"email","message":"must be a valid email address"},{"field":"tags[1]","message":"is required"}]}`, w.Body.String())
+}
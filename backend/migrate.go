@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/prathamrao021/HelperHub/internal/config"
+	"github.com/prathamrao021/HelperHub/internal/migrate"
+	"github.com/prathamrao021/HelperHub/migrations"
+)
+
+const migrateUsage = `Usage: go run . migrate <command>
+
+Commands:
+  up        Apply every pending migration
+  down [n]  Revert the last n applied migrations, 1 by default
+  status    List the migrations and whether they have been applied
+  baseline  Adopt a database created by db.AutoMigrate and record the first migration as applied`
+
+// runMigrate runs the migrate subcommand with its arguments
+func runMigrate(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		os.Exit(2)
+	}
+
+	migrator, err := migrate.New(openDB(cfg.Database), migrations.For(cfg.Database.Driver))
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Printf("Applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if errors.Is(err, migrate.ErrUnversioned) {
+			log.Fatalf("Migration failed: %v. Run migrate baseline to adopt it first.", err)
+		}
+		if err != nil {
+			log.Fatal("Migration failed: ", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("The database is up to date")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatalf("Invalid number of migrations %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(steps)
+		for _, migration := range reverted {
+			fmt.Printf("Reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatal("Migration failed: ", err)
+		}
+
+	case "baseline":
+		adopt, ok := migrations.Adopt(cfg.Database.Driver)
+		if !ok {
+			log.Fatalf("Only PostgreSQL databases were created by db.AutoMigrate, there is nothing to adopt on %s", cfg.Database.Driver)
+		}
+		if err := migrator.Baseline(adopt); err != nil {
+			log.Fatal("Baseline failed: ", err)
+		}
+		fmt.Println("Recorded the first migration as applied, run migrate up to apply the rest")
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatal("Failed to read the schema version: ", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied_At != nil {
+				appliedAt = status.Applied_At.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		w.Flush()
+
+	default:
+		fmt.Println(migrateUsage)
+		os.Exit(2)
+	}
+}
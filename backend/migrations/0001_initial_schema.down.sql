@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS attendance_records;
+DROP TABLE IF EXISTS attendance_codes;
+DROP TABLE IF EXISTS time_entries;
+DROP TABLE IF EXISTS application_status_histories;
+DROP TABLE IF EXISTS applications;
+DROP TABLE IF EXISTS shifts;
+DROP TABLE IF EXISTS opportunities;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS organizations;
+DROP TABLE IF EXISTS volunteers;
+DROP TABLE IF EXISTS users;
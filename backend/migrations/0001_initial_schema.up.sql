@@ -0,0 +1,167 @@
+-- The initial schema. Databases created by db.AutoMigrate before migrations were introduced
+-- lack some of its columns and may break its unique indexes, so migrate up refuses to run on a
+-- database that has tables but no applied migrations. migrate baseline adopts them with
+-- adopt/postgres.sql first.
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial PRIMARY KEY,
+    email text NOT NULL CONSTRAINT uni_users_email UNIQUE,
+    password_hash text NOT NULL,
+    full_name text NOT NULL,
+    role text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS volunteers (
+    id bigserial PRIMARY KEY,
+    email text NOT NULL CONSTRAINT uni_volunteers_email UNIQUE,
+    password text NOT NULL,
+    name text NOT NULL,
+    phone text NOT NULL CONSTRAINT uni_volunteers_phone UNIQUE,
+    location text,
+    latitude decimal,
+    longitude decimal,
+    bio_data text,
+    category_list json NOT NULL,
+    availabile_hours bigint NOT NULL,
+    suspended_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id bigserial PRIMARY KEY,
+    email text NOT NULL CONSTRAINT uni_organizations_email UNIQUE,
+    password text NOT NULL,
+    name text NOT NULL CONSTRAINT uni_organizations_name UNIQUE,
+    phone text NOT NULL,
+    location text NOT NULL,
+    latitude decimal,
+    longitude decimal,
+    description text NOT NULL,
+    website_url text NOT NULL,
+    suspended_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id bigserial PRIMARY KEY,
+    category text NOT NULL CONSTRAINT uni_categories_category UNIQUE,
+    created_at timestamptz
+);
+
+CREATE TABLE IF NOT EXISTS opportunities (
+    id bigserial PRIMARY KEY,
+    organization_mail text NOT NULL,
+    category text NOT NULL,
+    title text NOT NULL,
+    description text NOT NULL,
+    location text NOT NULL,
+    latitude decimal,
+    longitude decimal,
+    remote boolean NOT NULL DEFAULT false,
+    hours_required bigint NOT NULL,
+    capacity bigint NOT NULL DEFAULT 0,
+    start_date date NOT NULL,
+    end_date date NOT NULL,
+    hidden_at timestamptz,
+    moderation_note text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(category, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'C')
+    ) STORED
+);
+CREATE INDEX IF NOT EXISTS idx_opportunities_coordinates ON opportunities (latitude, longitude);
+CREATE INDEX IF NOT EXISTS idx_opportunities_search ON opportunities USING gin (search_vector);
+
+CREATE TABLE IF NOT EXISTS shifts (
+    id bigserial PRIMARY KEY,
+    opportunity_id bigint NOT NULL CONSTRAINT fk_shifts_opportunity REFERENCES opportunities (id) ON DELETE CASCADE,
+    date date NOT NULL,
+    start_time text NOT NULL,
+    end_time text NOT NULL,
+    capacity bigint NOT NULL DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_shifts_opportunity_id ON shifts (opportunity_id);
+
+CREATE TABLE IF NOT EXISTS applications (
+    id bigserial PRIMARY KEY,
+    volunteer_id bigint NOT NULL CONSTRAINT fk_applications_volunteer REFERENCES volunteers (id) ON DELETE CASCADE,
+    opportunity_id bigint NOT NULL CONSTRAINT fk_applications_opportunity REFERENCES opportunities (id) ON DELETE CASCADE,
+    shift_id bigint CONSTRAINT fk_applications_shift REFERENCES shifts (id) ON DELETE CASCADE,
+    status text NOT NULL,
+    cover_letter text NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_applications_volunteer_opportunity ON applications (volunteer_id, opportunity_id) WHERE shift_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_applications_volunteer_shift ON applications (volunteer_id, shift_id);
+
+CREATE TABLE IF NOT EXISTS application_status_histories (
+    id bigserial PRIMARY KEY,
+    application_id bigint NOT NULL CONSTRAINT fk_application_status_histories_application REFERENCES applications (id) ON DELETE CASCADE,
+    from_status text,
+    to_status text NOT NULL,
+    actor_id bigint,
+    actor_role text,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_application_status_histories_application_id ON application_status_histories (application_id);
+
+CREATE TABLE IF NOT EXISTS time_entries (
+    id bigserial PRIMARY KEY,
+    application_id bigint NOT NULL CONSTRAINT fk_time_entries_application REFERENCES applications (id) ON DELETE CASCADE,
+    work_date date NOT NULL,
+    hours decimal NOT NULL,
+    note text,
+    status text NOT NULL,
+    reviewer_id bigint,
+    review_note text,
+    reviewed_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_time_entries_application_id ON time_entries (application_id);
+CREATE INDEX IF NOT EXISTS idx_time_entries_status ON time_entries (status);
+
+CREATE TABLE IF NOT EXISTS attendance_codes (
+    id bigserial PRIMARY KEY,
+    opportunity_id bigint NOT NULL CONSTRAINT fk_attendance_codes_opportunity REFERENCES opportunities (id) ON DELETE CASCADE,
+    shift_id bigint CONSTRAINT fk_attendance_codes_shift REFERENCES shifts (id) ON DELETE CASCADE,
+    code text NOT NULL,
+    issuer_id bigint,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_attendance_codes_opportunity_id ON attendance_codes (opportunity_id);
+CREATE INDEX IF NOT EXISTS idx_attendance_codes_shift_id ON attendance_codes (shift_id);
+
+CREATE TABLE IF NOT EXISTS attendance_records (
+    id bigserial PRIMARY KEY,
+    application_id bigint NOT NULL CONSTRAINT fk_attendance_records_application REFERENCES applications (id) ON DELETE CASCADE,
+    check_in_at timestamptz NOT NULL,
+    check_out_at timestamptz,
+    time_entry_id bigint,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_attendance_records_application_id ON attendance_records (application_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id bigserial PRIMARY KEY,
+    token_hash text NOT NULL CONSTRAINT uni_refresh_tokens_token_hash UNIQUE,
+    subject_id bigint NOT NULL,
+    email text NOT NULL,
+    role text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_email ON refresh_tokens (email);
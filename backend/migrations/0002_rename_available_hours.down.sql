@@ -0,0 +1 @@
+ALTER TABLE volunteers RENAME COLUMN available_hours TO availabile_hours;
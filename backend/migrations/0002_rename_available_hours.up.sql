@@ -0,0 +1 @@
+ALTER TABLE volunteers RENAME COLUMN availabile_hours TO available_hours;
@@ -0,0 +1 @@
+ALTER TABLE attendance_codes ADD COLUMN failed_attempts integer NOT NULL DEFAULT 0;
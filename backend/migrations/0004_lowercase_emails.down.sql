@@ -0,0 +1,4 @@
+-- The emails stay in lower case
+DROP INDEX idx_organizations_email_lower;
+DROP INDEX idx_volunteers_email_lower;
+DROP INDEX idx_users_email_lower;
@@ -0,0 +1,2 @@
+ALTER TABLE attendance_codes ADD COLUMN failed_attempts integer NOT NULL DEFAULT 0;
+DROP TABLE attendance_code_misses;
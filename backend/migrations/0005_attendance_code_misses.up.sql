@@ -0,0 +1,12 @@
+-- Wrong attendance codes are counted for each volunteer and code, so one volunteer's guesses
+-- cannot stop a code from working for everyone else
+CREATE TABLE attendance_code_misses (
+    attendance_code_id bigint NOT NULL CONSTRAINT fk_attendance_code_misses_code REFERENCES attendance_codes (id) ON DELETE CASCADE,
+    volunteer_id bigint NOT NULL CONSTRAINT fk_attendance_code_misses_volunteer REFERENCES volunteers (id) ON DELETE CASCADE,
+    failed_attempts integer NOT NULL DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (attendance_code_id, volunteer_id)
+);
+
+ALTER TABLE attendance_codes DROP COLUMN failed_attempts;
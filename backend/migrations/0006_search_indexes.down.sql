@@ -0,0 +1,2 @@
+DROP INDEX idx_organizations_name_search;
+DROP INDEX idx_opportunities_organization_mail;
@@ -0,0 +1,4 @@
+-- Keyword searches match the organization's name on its own, and look up the opportunities of
+-- the matching organizations
+CREATE INDEX idx_opportunities_organization_mail ON opportunities (organization_mail);
+CREATE INDEX idx_organizations_name_search ON organizations USING gin (to_tsvector('english', name));
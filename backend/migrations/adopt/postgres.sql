@@ -0,0 +1,68 @@
+-- Brings a database created by db.AutoMigrate, before migrations were introduced, to the
+-- initial schema. migrate baseline runs it before the first migration, which then creates the
+-- tables and indexes that are still missing, all in one transaction. Only PostgreSQL databases
+-- were ever created by db.AutoMigrate.
+
+ALTER TABLE volunteers ADD COLUMN IF NOT EXISTS latitude decimal;
+ALTER TABLE volunteers ADD COLUMN IF NOT EXISTS longitude decimal;
+ALTER TABLE volunteers ADD COLUMN IF NOT EXISTS suspended_at timestamptz;
+
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS latitude decimal;
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS longitude decimal;
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS suspended_at timestamptz;
+
+ALTER TABLE opportunities ADD COLUMN IF NOT EXISTS latitude decimal;
+ALTER TABLE opportunities ADD COLUMN IF NOT EXISTS longitude decimal;
+ALTER TABLE opportunities ADD COLUMN IF NOT EXISTS remote boolean NOT NULL DEFAULT false;
+ALTER TABLE opportunities ADD COLUMN IF NOT EXISTS capacity bigint NOT NULL DEFAULT 0;
+ALTER TABLE opportunities ADD COLUMN IF NOT EXISTS hidden_at timestamptz;
+ALTER TABLE opportunities ADD COLUMN IF NOT EXISTS moderation_note text;
+ALTER TABLE opportunities ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(category, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'C')
+) STORED;
+
+-- Applications refer to shifts, so the shifts table is created here rather than by the first
+-- migration
+CREATE TABLE IF NOT EXISTS shifts (
+    id bigserial PRIMARY KEY,
+    opportunity_id bigint NOT NULL CONSTRAINT fk_shifts_opportunity REFERENCES opportunities (id) ON DELETE CASCADE,
+    date date NOT NULL,
+    start_time text NOT NULL,
+    end_time text NOT NULL,
+    capacity bigint NOT NULL DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS shift_id bigint;
+
+-- Applications of volunteers or opportunities that no longer exist would break the foreign
+-- keys, and a volunteer applies once to an opportunity or shift, so those are deleted, keeping
+-- the latest application
+DELETE FROM applications
+WHERE volunteer_id NOT IN (SELECT id FROM volunteers)
+    OR opportunity_id NOT IN (SELECT id FROM opportunities)
+    OR shift_id NOT IN (SELECT id FROM shifts);
+DELETE FROM applications AS older
+USING applications AS newer
+WHERE older.volunteer_id = newer.volunteer_id
+    AND older.opportunity_id = newer.opportunity_id
+    AND older.shift_id IS NOT DISTINCT FROM newer.shift_id
+    AND older.id < newer.id;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_applications_volunteer') THEN
+        ALTER TABLE applications ADD CONSTRAINT fk_applications_volunteer
+            FOREIGN KEY (volunteer_id) REFERENCES volunteers (id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_applications_opportunity') THEN
+        ALTER TABLE applications ADD CONSTRAINT fk_applications_opportunity
+            FOREIGN KEY (opportunity_id) REFERENCES opportunities (id) ON DELETE CASCADE;
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_applications_shift') THEN
+        ALTER TABLE applications ADD CONSTRAINT fk_applications_shift
+            FOREIGN KEY (shift_id) REFERENCES shifts (id) ON DELETE CASCADE;
+    END IF;
+END $$;
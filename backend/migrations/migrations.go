@@ -0,0 +1,45 @@
+// Package migrations holds the versioned SQL migrations of the database schema. Each version
+// is a pair of files, NNNN_name.up.sql and NNNN_name.down.sql, applied in order by the
+// internal/migrate package. The PostgreSQL migrations are at the top level and the SQLite
+// versions of the same migrations are in the sqlite directory. The adopt directory holds the
+// SQL that migrate baseline runs on databases created by db.AutoMigrate.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+
+	"github.com/prathamrao021/HelperHub/internal/config"
+)
+
+// FS holds the PostgreSQL migration files
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFiles embed.FS
+
+// SQLite holds the SQLite migration files
+var SQLite, _ = fs.Sub(sqliteFiles, "sqlite")
+
+//go:embed adopt/*.sql
+var adoptFiles embed.FS
+
+// Adopt returns the SQL that brings a database created by db.AutoMigrate to the schema of the
+// first migration, and false for drivers no such database exists for
+func Adopt(driver string) (string, bool) {
+	content, err := adoptFiles.ReadFile("adopt/" + driver + ".sql")
+	if err != nil {
+		return "", false
+	}
+	return string(content), true
+}
+
+// For returns the migration files for a database driver
+func For(driver string) fs.FS {
+	if driver == config.DriverSQLite {
+		return SQLite
+	}
+	return FS
+}
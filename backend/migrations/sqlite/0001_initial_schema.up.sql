@@ -0,0 +1,202 @@
+-- The SQLite version of the initial schema, used for local development and tests. It matches
+-- the PostgreSQL schema, except that opportunities are searched through the opportunity_search
+-- full-text table instead of a search_vector column.
+
+CREATE TABLE IF NOT EXISTS users (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    email text NOT NULL CONSTRAINT uni_users_email UNIQUE,
+    password_hash text NOT NULL,
+    full_name text NOT NULL,
+    role text NOT NULL,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS volunteers (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    email text NOT NULL CONSTRAINT uni_volunteers_email UNIQUE,
+    password text NOT NULL,
+    name text NOT NULL,
+    phone text NOT NULL CONSTRAINT uni_volunteers_phone UNIQUE,
+    location text,
+    latitude real,
+    longitude real,
+    bio_data text,
+    category_list json NOT NULL,
+    availabile_hours bigint NOT NULL,
+    suspended_at datetime,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    email text NOT NULL CONSTRAINT uni_organizations_email UNIQUE,
+    password text NOT NULL,
+    name text NOT NULL CONSTRAINT uni_organizations_name UNIQUE,
+    phone text NOT NULL,
+    location text NOT NULL,
+    latitude real,
+    longitude real,
+    description text NOT NULL,
+    website_url text NOT NULL,
+    suspended_at datetime,
+    created_at datetime,
+    updated_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    category text NOT NULL CONSTRAINT uni_categories_category UNIQUE,
+    created_at datetime
+);
+
+CREATE TABLE IF NOT EXISTS opportunities (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    organization_mail text NOT NULL,
+    category text NOT NULL,
+    title text NOT NULL,
+    description text NOT NULL,
+    location text NOT NULL,
+    latitude real,
+    longitude real,
+    remote boolean NOT NULL DEFAULT false,
+    hours_required bigint NOT NULL,
+    capacity bigint NOT NULL DEFAULT 0,
+    start_date date NOT NULL,
+    end_date date NOT NULL,
+    hidden_at datetime,
+    moderation_note text,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_opportunities_coordinates ON opportunities (latitude, longitude);
+
+-- Full-text search over opportunities, kept up to date by triggers. The docid of a row is the
+-- id of its opportunity, and organization holds the name of the organization offering it.
+CREATE VIRTUAL TABLE IF NOT EXISTS opportunity_search USING fts4 (
+    title, category, description, organization, tokenize=porter
+);
+
+CREATE TRIGGER IF NOT EXISTS opportunity_search_insert AFTER INSERT ON opportunities BEGIN
+    INSERT INTO opportunity_search (docid, title, category, description, organization)
+    VALUES (new.id, new.title, new.category, new.description,
+        coalesce((SELECT name FROM organizations WHERE email = new.organization_mail), ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS opportunity_search_update AFTER UPDATE OF organization_mail, title, category, description ON opportunities BEGIN
+    UPDATE opportunity_search SET
+        title = new.title,
+        category = new.category,
+        description = new.description,
+        organization = coalesce((SELECT name FROM organizations WHERE email = new.organization_mail), '')
+    WHERE docid = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS opportunity_search_delete AFTER DELETE ON opportunities BEGIN
+    DELETE FROM opportunity_search WHERE docid = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS organization_search_insert AFTER INSERT ON organizations BEGIN
+    UPDATE opportunity_search SET organization = new.name
+    WHERE docid IN (SELECT id FROM opportunities WHERE organization_mail = new.email);
+END;
+
+CREATE TRIGGER IF NOT EXISTS organization_search_update AFTER UPDATE OF email, name ON organizations BEGIN
+    UPDATE opportunity_search SET organization = ''
+    WHERE docid IN (SELECT id FROM opportunities WHERE organization_mail = old.email);
+    UPDATE opportunity_search SET organization = new.name
+    WHERE docid IN (SELECT id FROM opportunities WHERE organization_mail = new.email);
+END;
+
+CREATE TRIGGER IF NOT EXISTS organization_search_delete AFTER DELETE ON organizations BEGIN
+    UPDATE opportunity_search SET organization = ''
+    WHERE docid IN (SELECT id FROM opportunities WHERE organization_mail = old.email);
+END;
+
+CREATE TABLE IF NOT EXISTS shifts (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    opportunity_id bigint NOT NULL CONSTRAINT fk_shifts_opportunity REFERENCES opportunities (id) ON DELETE CASCADE,
+    date date NOT NULL,
+    start_time text NOT NULL,
+    end_time text NOT NULL,
+    capacity bigint NOT NULL DEFAULT 0,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_shifts_opportunity_id ON shifts (opportunity_id);
+
+CREATE TABLE IF NOT EXISTS applications (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    volunteer_id bigint NOT NULL CONSTRAINT fk_applications_volunteer REFERENCES volunteers (id) ON DELETE CASCADE,
+    opportunity_id bigint NOT NULL CONSTRAINT fk_applications_opportunity REFERENCES opportunities (id) ON DELETE CASCADE,
+    shift_id bigint CONSTRAINT fk_applications_shift REFERENCES shifts (id) ON DELETE CASCADE,
+    status text NOT NULL,
+    cover_letter text NOT NULL,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_applications_volunteer_opportunity ON applications (volunteer_id, opportunity_id) WHERE shift_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_applications_volunteer_shift ON applications (volunteer_id, shift_id);
+
+CREATE TABLE IF NOT EXISTS application_status_histories (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    application_id bigint NOT NULL CONSTRAINT fk_application_status_histories_application REFERENCES applications (id) ON DELETE CASCADE,
+    from_status text,
+    to_status text NOT NULL,
+    actor_id bigint,
+    actor_role text,
+    created_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_application_status_histories_application_id ON application_status_histories (application_id);
+
+CREATE TABLE IF NOT EXISTS time_entries (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    application_id bigint NOT NULL CONSTRAINT fk_time_entries_application REFERENCES applications (id) ON DELETE CASCADE,
+    work_date date NOT NULL,
+    hours real NOT NULL,
+    note text,
+    status text NOT NULL,
+    reviewer_id bigint,
+    review_note text,
+    reviewed_at datetime,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_time_entries_application_id ON time_entries (application_id);
+CREATE INDEX IF NOT EXISTS idx_time_entries_status ON time_entries (status);
+
+CREATE TABLE IF NOT EXISTS attendance_codes (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    opportunity_id bigint NOT NULL CONSTRAINT fk_attendance_codes_opportunity REFERENCES opportunities (id) ON DELETE CASCADE,
+    shift_id bigint CONSTRAINT fk_attendance_codes_shift REFERENCES shifts (id) ON DELETE CASCADE,
+    code text NOT NULL,
+    issuer_id bigint,
+    expires_at datetime NOT NULL,
+    created_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_attendance_codes_opportunity_id ON attendance_codes (opportunity_id);
+CREATE INDEX IF NOT EXISTS idx_attendance_codes_shift_id ON attendance_codes (shift_id);
+
+CREATE TABLE IF NOT EXISTS attendance_records (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    application_id bigint NOT NULL CONSTRAINT fk_attendance_records_application REFERENCES applications (id) ON DELETE CASCADE,
+    check_in_at datetime NOT NULL,
+    check_out_at datetime,
+    time_entry_id bigint,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_attendance_records_application_id ON attendance_records (application_id);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    token_hash text NOT NULL CONSTRAINT uni_refresh_tokens_token_hash UNIQUE,
+    subject_id bigint NOT NULL,
+    email text NOT NULL,
+    role text NOT NULL,
+    expires_at datetime NOT NULL,
+    revoked_at datetime,
+    created_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_email ON refresh_tokens (email);
@@ -0,0 +1 @@
+ALTER TABLE attendance_codes DROP COLUMN failed_attempts;
@@ -0,0 +1,13 @@
+-- Emails are stored in lower case, and no two accounts of a kind can have emails that differ
+-- only in case. The migration fails when such accounts already exist; merge or rename them
+-- first.
+
+UPDATE users SET email = lower(email);
+UPDATE volunteers SET email = lower(email);
+UPDATE organizations SET email = lower(email);
+UPDATE opportunities SET organization_mail = lower(organization_mail);
+UPDATE refresh_tokens SET email = lower(email);
+
+CREATE UNIQUE INDEX idx_users_email_lower ON users (lower(email));
+CREATE UNIQUE INDEX idx_volunteers_email_lower ON volunteers (lower(email));
+CREATE UNIQUE INDEX idx_organizations_email_lower ON organizations (lower(email));
@@ -0,0 +1 @@
+DROP INDEX idx_opportunities_organization_mail;
@@ -0,0 +1,2 @@
+-- Opportunities are looked up by the email of their organization
+CREATE INDEX idx_opportunities_organization_mail ON opportunities (organization_mail);
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Application lifecycle statuses
+const (
+	ApplicationPending    = "Pending"
+	ApplicationWaitlisted = "Waitlisted" // Applied while the opportunity was at capacity
+	ApplicationAccepted   = "Accepted"
+	ApplicationRejected   = "Rejected"
+	ApplicationWithdrawn  = "Withdrawn"
+	ApplicationCompleted  = "Completed"
+	ApplicationNoShow     = "NoShow"
+	ApplicationRemoved    = "Removed" // Accepted, then taken off the roster by the organization
+)
+
+// ApprovedApplicationStatuses are the statuses of applications that were accepted by the organization
+var ApprovedApplicationStatuses = []string{ApplicationAccepted, ApplicationCompleted}
+
+// ConcludedApplicationStatuses are the outcomes of accepted applications once the work is over:
+// the volunteer either completed it or did not show up
+var ConcludedApplicationStatuses = []string{ApplicationCompleted, ApplicationNoShow}
+
+// DeletableApplicationStatuses are the statuses of applications the organization has not decided
+// on yet, which can still be deleted. Later on an application is withdrawn instead, which keeps its
+// history and hours.
+var DeletableApplicationStatuses = []string{ApplicationPending, ApplicationWaitlisted}
+
+// CanDeleteApplication reports whether an application in the given status can be deleted
+func CanDeleteApplication(status string) bool {
+	for _, s := range DeletableApplicationStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// HoldsSeat reports whether an application in the given status takes one of the seats of its
+// opportunity and shift. Completed applications keep their seat, so finishing the work does not
+// open it up to the waitlist.
+func HoldsSeat(status string) bool {
+	for _, s := range ApprovedApplicationStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// applicationTransitions lists the statuses each status may move to. Statuses without an
+// entry are final.
+var applicationTransitions = map[string][]string{
+	ApplicationPending:    {ApplicationAccepted, ApplicationRejected, ApplicationWithdrawn},
+	ApplicationWaitlisted: {ApplicationAccepted, ApplicationRejected, ApplicationWithdrawn},
+	ApplicationAccepted:   {ApplicationCompleted, ApplicationNoShow, ApplicationWithdrawn, ApplicationRemoved},
+}
+
+var applicationStatuses = []string{
+	ApplicationPending,
+	ApplicationWaitlisted,
+	ApplicationAccepted,
+	ApplicationRejected,
+	ApplicationWithdrawn,
+	ApplicationCompleted,
+	ApplicationNoShow,
+	ApplicationRemoved,
+}
+
+// ParseApplicationStatus returns the canonical spelling of an application status, matching
+// case-insensitively. It reports false for unknown statuses.
+func ParseApplicationStatus(status string) (string, bool) {
+	for _, s := range applicationStatuses {
+		if strings.EqualFold(s, strings.TrimSpace(status)) {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// CanTransitionApplication reports whether an application may move from one status to another
+func CanTransitionApplication(from string, to string) bool {
+	for _, next := range applicationTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplicationStatusHistory struct. One row is written for every status an application enters.
+type ApplicationStatusHistory struct {
+	ID             uint         `gorm:"primaryKey" json:"id"`
+	Application_ID uint         `gorm:"not null;index" json:"application_id"`
+	From_Status    string       `json:"from_status"`
+	To_Status      string       `gorm:"not null" json:"to_status"`
+	Actor_ID       uint         `json:"actor_id"`
+	Actor_Role     string       `json:"actor_role"`
+	Created_At     time.Time    `json:"created_at"`
+	Application    *Application `gorm:"foreignKey:Application_ID;constraint:OnDelete:CASCADE" json:"-"`
+}
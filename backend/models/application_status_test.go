@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseApplicationStatus(t *testing.T) {
+	status, ok := ParseApplicationStatus("accepted")
+	assert.True(t, ok)
+	assert.Equal(t, ApplicationAccepted, status)
+
+	status, ok = ParseApplicationStatus(" NOSHOW ")
+	assert.True(t, ok)
+	assert.Equal(t, ApplicationNoShow, status)
+
+	_, ok = ParseApplicationStatus("Approved")
+	assert.False(t, ok)
+}
+
+func TestCanTransitionApplication(t *testing.T) {
+	allowed := [][2]string{
+		{ApplicationPending, ApplicationAccepted},
+		{ApplicationPending, ApplicationRejected},
+		{ApplicationPending, ApplicationWithdrawn},
+		{ApplicationAccepted, ApplicationCompleted},
+		{ApplicationAccepted, ApplicationNoShow},
+		{ApplicationAccepted, ApplicationWithdrawn},
+		{ApplicationAccepted, ApplicationRemoved},
+		{ApplicationWaitlisted, ApplicationAccepted},
+		{ApplicationWaitlisted, ApplicationWithdrawn},
+	}
+	for _, transition := range allowed {
+		assert.True(t, CanTransitionApplication(transition[0], transition[1]), "%s -> %s", transition[0], transition[1])
+	}
+
+	refused := [][2]string{
+		{ApplicationPending, ApplicationCompleted},
+		{ApplicationAccepted, ApplicationPending},
+		{ApplicationRejected, ApplicationAccepted},
+		{ApplicationWithdrawn, ApplicationPending},
+		{ApplicationCompleted, ApplicationNoShow},
+		{ApplicationPending, ApplicationPending},
+		{ApplicationPending, ApplicationWaitlisted},
+		{ApplicationPending, ApplicationRemoved},
+		{ApplicationRemoved, ApplicationAccepted},
+	}
+	for _, transition := range refused {
+		assert.False(t, CanTransitionApplication(transition[0], transition[1]), "%s -> %s", transition[0], transition[1])
+	}
+}
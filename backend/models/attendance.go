@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// AttendanceCodeTTL is how long an attendance code can be used after it was issued
+const AttendanceCodeTTL = 5 * time.Minute
+
+// MaxAttendanceCodeAttempts is how many wrong codes a volunteer can send while a code is current
+// before it stops working for them, so the six digits cannot be guessed
+const MaxAttendanceCodeAttempts = 5
+
+// AttendanceCode struct. A short-lived code an organization shows at an opportunity or shift so
+// volunteers can check in and out. Issuing a new code expires the previous one.
+type AttendanceCode struct {
+	ID             uint         `gorm:"primaryKey" json:"id"`
+	Opportunity_ID uint         `gorm:"not null;index" json:"opportunity_id"`
+	Shift_ID       *uint        `gorm:"index" json:"shift_id"`
+	Code           string       `gorm:"not null" json:"-"`
+	Issuer_ID      uint         `json:"issuer_id"`
+	Expires_At     time.Time    `gorm:"not null" json:"expires_at"`
+	Created_At     time.Time    `json:"created_at"`
+	Opportunity    *Opportunity `gorm:"foreignKey:Opportunity_ID;constraint:OnDelete:CASCADE" json:"-"`
+	Shift          *Shift       `gorm:"foreignKey:Shift_ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// AttendanceCodeMiss struct. The wrong codes one volunteer sent while an attendance code was
+// current.
+type AttendanceCodeMiss struct {
+	Attendance_Code_ID uint            `gorm:"primaryKey" json:"attendance_code_id"`
+	Volunteer_ID       uint            `gorm:"primaryKey" json:"volunteer_id"`
+	Failed_Attempts    int             `gorm:"not null;default:0" json:"failed_attempts"`
+	Created_At         time.Time       `json:"created_at"`
+	Updated_At         time.Time       `json:"updated_at"`
+	Attendance_Code    *AttendanceCode `gorm:"foreignKey:Attendance_Code_ID;constraint:OnDelete:CASCADE" json:"-"`
+	Volunteer          *Volunteer      `gorm:"foreignKey:Volunteer_ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// AttendanceRecord struct. One visit of a volunteer, from check-in to check-out.
+type AttendanceRecord struct {
+	ID             uint         `gorm:"primaryKey" json:"id"`
+	Application_ID uint         `gorm:"not null;index" json:"application_id"`
+	Check_In_At    time.Time    `gorm:"not null" json:"check_in_at"`
+	Check_Out_At   *time.Time   `json:"check_out_at"`
+	Time_Entry_ID  *uint        `json:"time_entry_id"` // Hours recorded for the visit on check-out
+	Created_At     time.Time    `json:"created_at"`
+	Updated_At     time.Time    `json:"updated_at"`
+	Application    *Application `gorm:"foreignKey:Application_ID;constraint:OnDelete:CASCADE" json:"-"`
+}
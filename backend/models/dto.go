@@ -0,0 +1,694 @@
+package models
+
+import (
+	"time"
+
+	"github.com/prathamrao021/HelperHub/internal/matching"
+)
+
+// Request and response bodies for the HTTP API. Credentials are only ever accepted on
+// requests; response types never carry a password or password hash.
+
+// SessionResponse struct
+type SessionResponse struct {
+	Access_Token  string `json:"access_token"`
+	Refresh_Token string `json:"refresh_token"`
+	Token_Type    string `json:"token_type"`
+	Expires_In    int    `json:"expires_in"`
+}
+
+// LoginResponse struct
+type LoginResponse struct {
+	SessionResponse
+	User interface{} `json:"user"`
+}
+
+// UserCreateRequest struct
+type UserCreateRequest struct {
+	Email     string `json:"email" binding:"required,email"`
+	Password  string `json:"password" binding:"required"`
+	Full_Name string `json:"full_name" binding:"required"`
+}
+
+// UserUpdateRequest struct
+type UserUpdateRequest struct {
+	Email     *string `json:"email" binding:"omitnil,email"`
+	Password  *string `json:"password"`
+	Full_Name *string `json:"full_name"`
+}
+
+// UserResponse struct
+type UserResponse struct {
+	ID         uint      `json:"id"`
+	Email      string    `json:"email"`
+	Full_Name  string    `json:"full_name"`
+	Role       string    `json:"role"`
+	Created_At time.Time `json:"created_at"`
+	Updated_At time.Time `json:"updated_at"`
+}
+
+// NewUserResponse converts a User into its API representation
+func NewUserResponse(u User) UserResponse {
+	return UserResponse{
+		ID:         u.ID,
+		Email:      u.Email,
+		Full_Name:  u.Full_Name,
+		Role:       u.Role,
+		Created_At: u.Created_At,
+		Updated_At: u.Updated_At,
+	}
+}
+
+// NewUserResponses converts a list of users into their API representation
+func NewUserResponses(users []User) []UserResponse {
+	responses := make([]UserResponse, 0, len(users))
+	for _, u := range users {
+		responses = append(responses, NewUserResponse(u))
+	}
+	return responses
+}
+
+// VolunteerCreateRequest struct
+type VolunteerCreateRequest struct {
+	Email           string     `json:"email" binding:"required,email"`
+	Password        string     `json:"password" binding:"required"`
+	Name            string     `json:"name"`
+	Phone           string     `json:"phone" binding:"omitempty,phone"`
+	Location        string     `json:"location"`
+	Bio_Data        string     `json:"bio_data"`
+	Category_List   StringList `json:"category_list"`
+	Available_Hours uint       `json:"available_hours"`
+}
+
+// VolunteerUpdateRequest struct. Only the fields present in the body are changed.
+type VolunteerUpdateRequest struct {
+	Email           *string     `json:"email" binding:"omitnil,email"`
+	Password        *string     `json:"password"`
+	Name            *string     `json:"name"`
+	Phone           *string     `json:"phone" binding:"omitempty,phone"`
+	Location        *string     `json:"location"`
+	Bio_Data        *string     `json:"bio_data"`
+	Category_List   *StringList `json:"category_list"`
+	Available_Hours *uint       `json:"available_hours"`
+}
+
+// VolunteerResponse struct
+type VolunteerResponse struct {
+	ID              uint       `json:"id"`
+	Email           string     `json:"email"`
+	Name            string     `json:"name"`
+	Phone           string     `json:"phone"`
+	Location        string     `json:"location"`
+	Latitude        *float64   `json:"latitude"`
+	Longitude       *float64   `json:"longitude"`
+	Bio_Data        string     `json:"bio_data"`
+	Category_List   StringList `json:"category_list"`
+	Available_Hours uint       `json:"available_hours"`
+	Suspended_At    *time.Time `json:"suspended_at,omitempty"`
+	Created_At      time.Time  `json:"created_at"`
+	Updated_At      time.Time  `json:"updated_at"`
+}
+
+// NewVolunteerResponse converts a Volunteer into its API representation
+func NewVolunteerResponse(v Volunteer) VolunteerResponse {
+	return VolunteerResponse{
+		ID:              v.ID,
+		Email:           v.Email,
+		Name:            v.Name,
+		Phone:           v.Phone,
+		Location:        v.Location,
+		Latitude:        v.Latitude,
+		Longitude:       v.Longitude,
+		Bio_Data:        v.Bio_Data,
+		Category_List:   v.Category_List,
+		Available_Hours: v.Available_Hours,
+		Suspended_At:    v.Suspended_At,
+		Created_At:      v.Created_At,
+		Updated_At:      v.Updated_At,
+	}
+}
+
+// NewVolunteerResponses converts a list of volunteers into their API representation
+func NewVolunteerResponses(volunteers []Volunteer) []VolunteerResponse {
+	responses := make([]VolunteerResponse, 0, len(volunteers))
+	for _, v := range volunteers {
+		responses = append(responses, NewVolunteerResponse(v))
+	}
+	return responses
+}
+
+// OrganizationCreateRequest struct
+type OrganizationCreateRequest struct {
+	Email       string `json:"email" binding:"required,email"`
+	Password    string `json:"password" binding:"required"`
+	Name        string `json:"name"`
+	Phone       string `json:"phone" binding:"omitempty,phone"`
+	Location    string `json:"location"`
+	Description string `json:"description"`
+	Website_Url string `json:"website_url" binding:"omitempty,http_url"`
+}
+
+// OrganizationUpdateRequest struct. Only the fields present in the body are changed.
+type OrganizationUpdateRequest struct {
+	Email       *string `json:"email" binding:"omitnil,email"`
+	Password    *string `json:"password"`
+	Name        *string `json:"name"`
+	Phone       *string `json:"phone" binding:"omitempty,phone"`
+	Location    *string `json:"location"`
+	Description *string `json:"description"`
+	Website_Url *string `json:"website_url" binding:"omitempty,http_url"`
+}
+
+// OrganizationResponse struct
+type OrganizationResponse struct {
+	ID           uint       `json:"id"`
+	Email        string     `json:"email"`
+	Name         string     `json:"name"`
+	Phone        string     `json:"phone"`
+	Location     string     `json:"location"`
+	Latitude     *float64   `json:"latitude"`
+	Longitude    *float64   `json:"longitude"`
+	Description  string     `json:"description"`
+	Website_Url  string     `json:"website_url"`
+	Suspended_At *time.Time `json:"suspended_at,omitempty"`
+	Created_At   time.Time  `json:"created_at"`
+	Updated_At   time.Time  `json:"updated_at"`
+}
+
+// NewOrganizationResponse converts an Organization into its API representation
+func NewOrganizationResponse(o Organization) OrganizationResponse {
+	return OrganizationResponse{
+		ID:           o.ID,
+		Email:        o.Email,
+		Name:         o.Name,
+		Phone:        o.Phone,
+		Location:     o.Location,
+		Latitude:     o.Latitude,
+		Longitude:    o.Longitude,
+		Description:  o.Description,
+		Website_Url:  o.Website_Url,
+		Suspended_At: o.Suspended_At,
+		Created_At:   o.Created_At,
+		Updated_At:   o.Updated_At,
+	}
+}
+
+// NewOrganizationResponses converts a list of organizations into their API representation
+func NewOrganizationResponses(organizations []Organization) []OrganizationResponse {
+	responses := make([]OrganizationResponse, 0, len(organizations))
+	for _, o := range organizations {
+		responses = append(responses, NewOrganizationResponse(o))
+	}
+	return responses
+}
+
+// CategoryResponse struct
+type CategoryResponse struct {
+	ID         uint      `json:"id"`
+	Category   string    `json:"category"`
+	Created_At time.Time `json:"created_at"`
+}
+
+// NewCategoryResponse converts a Category into its API representation
+func NewCategoryResponse(c Category) CategoryResponse {
+	return CategoryResponse{
+		ID:         c.ID,
+		Category:   c.Category,
+		Created_At: c.Created_At,
+	}
+}
+
+// OpportunityCreateRequest struct. Opportunities may start and end on the same day.
+type OpportunityCreateRequest struct {
+	Organization_mail string     `json:"organization_mail" binding:"omitempty,email"`
+	Category          string     `json:"category" binding:"required"`
+	Title             string     `json:"title" binding:"required"`
+	Description       string     `json:"description"`
+	Location          string     `json:"location"`
+	Remote            bool       `json:"remote"`
+	Hours_Required    uint       `json:"hours_required" binding:"min=1"`
+	Capacity          uint       `json:"capacity"`
+	Start_Date        CustomDate `json:"start_date" binding:"required"`
+	End_Date          CustomDate `json:"end_date" binding:"required,gtefield=Start_Date"`
+}
+
+// OpportunityUpdateRequest struct. Only the fields present in the body are changed.
+type OpportunityUpdateRequest struct {
+	Category       *string     `json:"category"`
+	Title          *string     `json:"title" binding:"omitnil,min=1"`
+	Description    *string     `json:"description"`
+	Location       *string     `json:"location"`
+	Remote         *bool       `json:"remote"`
+	Hours_Required *uint       `json:"hours_required" binding:"omitnil,min=1"`
+	Capacity       *uint       `json:"capacity"`
+	Start_Date     *CustomDate `json:"start_date"`
+	End_Date       *CustomDate `json:"end_date"`
+}
+
+// OpportunityResponse struct
+type OpportunityResponse struct {
+	ID                uint       `json:"id"`
+	Organization_mail string     `json:"organization_mail"`
+	Category          string     `json:"category"`
+	Title             string     `json:"title"`
+	Description       string     `json:"description"`
+	Location          string     `json:"location"`
+	Latitude          *float64   `json:"latitude"`
+	Longitude         *float64   `json:"longitude"`
+	Remote            bool       `json:"remote"`
+	Hours_Required    uint       `json:"hours_required"`
+	Capacity          uint       `json:"capacity"`
+	Start_Date        CustomDate `json:"start_date"`
+	End_Date          CustomDate `json:"end_date"`
+	Hidden_At         *time.Time `json:"hidden_at,omitempty"`
+	Moderation_Note   string     `json:"moderation_note,omitempty"`
+	Created_At        time.Time  `json:"created_at"`
+	Updated_At        time.Time  `json:"updated_at"`
+}
+
+// NewOpportunityResponse converts an Opportunity into its API representation
+func NewOpportunityResponse(o Opportunity) OpportunityResponse {
+	return OpportunityResponse{
+		ID:                o.ID,
+		Organization_mail: o.Organization_mail,
+		Category:          o.Category,
+		Title:             o.Title,
+		Description:       o.Description,
+		Location:          o.Location,
+		Latitude:          o.Latitude,
+		Longitude:         o.Longitude,
+		Remote:            o.Remote,
+		Hours_Required:    o.Hours_Required,
+		Capacity:          o.Capacity,
+		Start_Date:        o.Start_Date,
+		End_Date:          o.End_Date,
+		Hidden_At:         o.Hidden_At,
+		Moderation_Note:   o.Moderation_Note,
+		Created_At:        o.Created_At,
+		Updated_At:        o.Updated_At,
+	}
+}
+
+// NewOpportunityResponses converts a list of opportunities into their API representation
+func NewOpportunityResponses(opportunities []Opportunity) []OpportunityResponse {
+	responses := make([]OpportunityResponse, 0, len(opportunities))
+	for _, o := range opportunities {
+		responses = append(responses, NewOpportunityResponse(o))
+	}
+	return responses
+}
+
+// OpportunityWithApplicationCountResponse struct
+type OpportunityWithApplicationCountResponse struct {
+	OpportunityResponse
+	Application_Count int64 `json:"application_count"`
+}
+
+// AvailableOpportunityResponse struct. Distance_Km is set when the list was asked for
+// opportunities near a point.
+type AvailableOpportunityResponse struct {
+	OpportunityResponse
+	Organization_Name string   `json:"organization_name"`
+	Distance_Km       *float64 `json:"distance_km,omitempty"`
+}
+
+// OpportunitySearchResult struct. Headlines repeat the title and description escaped as HTML,
+// with the matched words wrapped in <mark> tags.
+type OpportunitySearchResult struct {
+	AvailableOpportunityResponse
+	Rank                 float64 `json:"rank"`
+	Title_Headline       string  `json:"title_headline,omitempty"`
+	Description_Headline string  `json:"description_headline,omitempty"`
+}
+
+// MatchFactorResponse struct. One weighted part of a match score with the reason it scored
+// the way it did.
+type MatchFactorResponse struct {
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	Weight float64 `json:"weight"`
+	Reason string  `json:"reason"`
+}
+
+// NewMatchFactorResponses converts the factors of a match into their API representation
+func NewMatchFactorResponses(factors []matching.Factor) []MatchFactorResponse {
+	responses := make([]MatchFactorResponse, 0, len(factors))
+	for _, f := range factors {
+		responses = append(responses, MatchFactorResponse{
+			Name:   f.Name,
+			Score:  f.Score,
+			Weight: f.Weight,
+			Reason: f.Reason,
+		})
+	}
+	return responses
+}
+
+// RecommendationResponse struct. An open opportunity recommended to a volunteer, with the
+// match score between 0 and 1 and the reasons it matched.
+type RecommendationResponse struct {
+	AvailableOpportunityResponse
+	Score   float64               `json:"score"`
+	Reasons []string              `json:"reasons"`
+	Factors []MatchFactorResponse `json:"factors"`
+}
+
+// OpportunityStatsResponse struct
+type OpportunityStatsResponse struct {
+	ID                      uint                 `json:"id"`
+	Organization_ID         string               `json:"organization_id"`
+	Title                   string               `json:"title"`
+	Description             string               `json:"description"`
+	Location                string               `json:"location"`
+	Latitude                *float64             `json:"latitude"`
+	Longitude               *float64             `json:"longitude"`
+	Remote                  bool                 `json:"remote"`
+	Hours_Required          uint                 `json:"hours_required"`
+	Capacity                uint                 `json:"capacity"`
+	Start_Date              CustomDate           `json:"start_date"`
+	End_Date                CustomDate           `json:"end_date"`
+	Created_At              time.Time            `json:"created_at"`
+	Updated_At              time.Time            `json:"updated_at"`
+	Category                string               `json:"category"`
+	Total_Applications      int64                `json:"total_applications"`
+	Pending_Applications    int64                `json:"pending_applications"`
+	Waitlisted_Applications int64                `json:"waitlisted_applications"`
+	Accepted_Applications   int64                `json:"accepted_applications"`
+	Rejected_Applications   int64                `json:"rejected_applications"`
+	Withdrawn_Applications  int64                `json:"withdrawn_applications"`
+	Completed_Applications  int64                `json:"completed_applications"`
+	No_Show_Applications    int64                `json:"no_show_applications"`
+	Removed_Applications    int64                `json:"removed_applications"`
+	Shifts                  []ShiftStatsResponse `json:"shifts"`
+}
+
+// ShiftCreateRequest struct
+type ShiftCreateRequest struct {
+	Date       CustomDate `json:"date"`
+	Start_Time string     `json:"start_time"`
+	End_Time   string     `json:"end_time"`
+	Capacity   uint       `json:"capacity"`
+}
+
+// ShiftUpdateRequest struct. Only the fields present in the body are changed.
+type ShiftUpdateRequest struct {
+	Date       *CustomDate `json:"date"`
+	Start_Time *string     `json:"start_time"`
+	End_Time   *string     `json:"end_time"`
+	Capacity   *uint       `json:"capacity"`
+}
+
+// ShiftResponse struct
+type ShiftResponse struct {
+	ID             uint       `json:"id"`
+	Opportunity_ID uint       `json:"opportunity_id"`
+	Date           CustomDate `json:"date"`
+	Start_Time     string     `json:"start_time"`
+	End_Time       string     `json:"end_time"`
+	Capacity       uint       `json:"capacity"`
+	Created_At     time.Time  `json:"created_at"`
+	Updated_At     time.Time  `json:"updated_at"`
+}
+
+// NewShiftResponse converts a Shift into its API representation
+func NewShiftResponse(s Shift) ShiftResponse {
+	return ShiftResponse{
+		ID:             s.ID,
+		Opportunity_ID: s.Opportunity_ID,
+		Date:           s.Date,
+		Start_Time:     s.Start_Time,
+		End_Time:       s.End_Time,
+		Capacity:       s.Capacity,
+		Created_At:     s.Created_At,
+		Updated_At:     s.Updated_At,
+	}
+}
+
+// NewShiftResponses converts a list of shifts into their API representation
+func NewShiftResponses(shifts []Shift) []ShiftResponse {
+	responses := make([]ShiftResponse, 0, len(shifts))
+	for _, s := range shifts {
+		responses = append(responses, NewShiftResponse(s))
+	}
+	return responses
+}
+
+// ShiftRosterEntry struct. A volunteer holding a seat on a shift.
+type ShiftRosterEntry struct {
+	Application_ID  uint   `json:"application_id"`
+	Volunteer_ID    uint   `json:"volunteer_id"`
+	Volunteer_Name  string `json:"volunteer_name"`
+	Volunteer_Email string `json:"volunteer_email"`
+	Status          string `json:"status"`
+}
+
+// ShiftStatsResponse struct. Fill_Rate is the share of the capacity that is taken and is
+// left out for shifts with unlimited capacity. Roster is left out for callers who may not see
+// who volunteers for the shift.
+type ShiftStatsResponse struct {
+	ShiftResponse
+	Filled_Seats            int64              `json:"filled_seats"`
+	Waitlisted_Applications int64              `json:"waitlisted_applications"`
+	Fill_Rate               *float64           `json:"fill_rate,omitempty"`
+	Roster                  []ShiftRosterEntry `json:"roster,omitempty"`
+}
+
+// ApplicationCreateRequest struct
+type ApplicationCreateRequest struct {
+	Volunteer_ID   uint   `json:"volunteer_ID"`
+	Opportunity_ID uint   `json:"opportunity_ID"`
+	Shift_ID       *uint  `json:"shift_ID"`
+	Status         string `json:"status"`
+	Cover_Letter   string `json:"cover_Letter"`
+}
+
+// ApplicationUpdateRequest struct. Only the fields present in the body are changed.
+type ApplicationUpdateRequest struct {
+	Status       *string `json:"status"`
+	Cover_Letter *string `json:"cover_Letter"`
+}
+
+// ApplicationResponse struct
+type ApplicationResponse struct {
+	ID             uint      `json:"id"`
+	Volunteer_ID   uint      `json:"volunteer_ID"`
+	Opportunity_ID uint      `json:"opportunity_ID"`
+	Shift_ID       *uint     `json:"shift_ID,omitempty"`
+	Status         string    `json:"status"`
+	Cover_Letter   string    `json:"cover_Letter"`
+	Created_At     time.Time `json:"created_At"`
+	Updated_At     time.Time `json:"updated_At"`
+}
+
+// NewApplicationResponse converts an Application into its API representation
+func NewApplicationResponse(a Application) ApplicationResponse {
+	return ApplicationResponse{
+		ID:             a.ID,
+		Volunteer_ID:   a.Volunteer_ID,
+		Opportunity_ID: a.Opportunity_ID,
+		Shift_ID:       a.Shift_ID,
+		Status:         a.Status,
+		Cover_Letter:   a.Cover_Letter,
+		Created_At:     a.Created_At,
+		Updated_At:     a.Updated_At,
+	}
+}
+
+// NewApplicationResponses converts a list of applications into their API representation
+func NewApplicationResponses(applications []Application) []ApplicationResponse {
+	responses := make([]ApplicationResponse, 0, len(applications))
+	for _, a := range applications {
+		responses = append(responses, NewApplicationResponse(a))
+	}
+	return responses
+}
+
+// ApplicationWithOpportunityResponse struct
+type ApplicationWithOpportunityResponse struct {
+	ID                uint      `json:"id"`
+	Volunteer_ID      uint      `json:"volunteer_id"`
+	Opportunity_ID    uint      `json:"opportunity_id"`
+	Opportunity_Title string    `json:"opportunity_title"`
+	Organization_Name string    `json:"organization_name"`
+	Status            string    `json:"status"`
+	Cover_Letter      string    `json:"cover_letter"`
+	Created_At        time.Time `json:"created_at"`
+	Updated_At        time.Time `json:"updated_at"`
+}
+
+// ApplicationWithVolunteerResponse struct. Ranked lists also carry the candidate's match score
+// and its factors.
+type ApplicationWithVolunteerResponse struct {
+	ID              uint                  `json:"id"`
+	Volunteer_ID    uint                  `json:"volunteer_id"`
+	Volunteer_Name  string                `json:"volunteer_name"`
+	Volunteer_Email string                `json:"volunteer_email"`
+	Opportunity_ID  uint                  `json:"opportunity_id"`
+	Status          string                `json:"status"`
+	Cover_Letter    string                `json:"cover_letter"`
+	Created_At      time.Time             `json:"created_at"`
+	Updated_At      time.Time             `json:"updated_at"`
+	Score           *float64              `gorm:"-" json:"score,omitempty"`   // Set when ranked
+	Factors         []MatchFactorResponse `gorm:"-" json:"factors,omitempty"` // Set when ranked
+}
+
+// ApplicationStatusHistoryResponse struct
+type ApplicationStatusHistoryResponse struct {
+	ID             uint      `json:"id"`
+	Application_ID uint      `json:"application_id"`
+	From_Status    string    `json:"from_status"`
+	To_Status      string    `json:"to_status"`
+	Actor_ID       uint      `json:"actor_id"`
+	Actor_Role     string    `json:"actor_role"`
+	Created_At     time.Time `json:"created_at"`
+}
+
+// NewApplicationStatusHistoryResponses converts status history rows into their API representation
+func NewApplicationStatusHistoryResponses(history []ApplicationStatusHistory) []ApplicationStatusHistoryResponse {
+	responses := make([]ApplicationStatusHistoryResponse, 0, len(history))
+	for _, h := range history {
+		responses = append(responses, ApplicationStatusHistoryResponse{
+			ID:             h.ID,
+			Application_ID: h.Application_ID,
+			From_Status:    h.From_Status,
+			To_Status:      h.To_Status,
+			Actor_ID:       h.Actor_ID,
+			Actor_Role:     h.Actor_Role,
+			Created_At:     h.Created_At,
+		})
+	}
+	return responses
+}
+
+// VolunteerStatsResponse struct. Total jobs counts the Accepted and Completed applications.
+// Verified hours were approved by the organization, pending hours await review, and estimated
+// hours are the hours required by the opportunities of those jobs.
+type VolunteerStatsResponse struct {
+	Total_Jobs      int64   `json:"total_jobs"`
+	Verified_Hours  float64 `json:"verified_hours"`
+	Pending_Hours   float64 `json:"pending_hours"`
+	Disputed_Hours  float64 `json:"disputed_hours"`
+	Estimated_Hours int64   `json:"estimated_hours"`
+}
+
+// TimeEntryCreateRequest struct
+type TimeEntryCreateRequest struct {
+	Work_Date CustomDate `json:"work_date"`
+	Hours     float64    `json:"hours"`
+	Note      string     `json:"note"`
+}
+
+// TimeEntryUpdateRequest struct. Only the fields present in the body are changed.
+type TimeEntryUpdateRequest struct {
+	Work_Date *CustomDate `json:"work_date"`
+	Hours     *float64    `json:"hours"`
+	Note      *string     `json:"note"`
+}
+
+// TimeEntryReviewRequest struct
+type TimeEntryReviewRequest struct {
+	Note string `json:"note"`
+}
+
+// TimeEntryResponse struct
+type TimeEntryResponse struct {
+	ID             uint       `json:"id"`
+	Application_ID uint       `json:"application_id"`
+	Work_Date      CustomDate `json:"work_date"`
+	Hours          float64    `json:"hours"`
+	Note           string     `json:"note"`
+	Status         string     `json:"status"`
+	Reviewer_ID    uint       `json:"reviewer_id,omitempty"`
+	Review_Note    string     `json:"review_note,omitempty"`
+	Reviewed_At    *time.Time `json:"reviewed_at,omitempty"`
+	Created_At     time.Time  `json:"created_at"`
+	Updated_At     time.Time  `json:"updated_at"`
+}
+
+// NewTimeEntryResponse converts a TimeEntry into its API representation
+func NewTimeEntryResponse(e TimeEntry) TimeEntryResponse {
+	return TimeEntryResponse{
+		ID:             e.ID,
+		Application_ID: e.Application_ID,
+		Work_Date:      e.Work_Date,
+		Hours:          e.Hours,
+		Note:           e.Note,
+		Status:         e.Status,
+		Reviewer_ID:    e.Reviewer_ID,
+		Review_Note:    e.Review_Note,
+		Reviewed_At:    e.Reviewed_At,
+		Created_At:     e.Created_At,
+		Updated_At:     e.Updated_At,
+	}
+}
+
+// NewTimeEntryResponses converts a list of time entries into their API representation
+func NewTimeEntryResponses(entries []TimeEntry) []TimeEntryResponse {
+	responses := make([]TimeEntryResponse, 0, len(entries))
+	for _, e := range entries {
+		responses = append(responses, NewTimeEntryResponse(e))
+	}
+	return responses
+}
+
+// AttendanceCodeRequest struct. Opportunities with shifts issue codes for one shift.
+type AttendanceCodeRequest struct {
+	Shift_ID *uint `json:"shift_id"`
+}
+
+// AttendanceCodeResponse struct. QR_Payload carries the same code for scanning.
+type AttendanceCodeResponse struct {
+	Opportunity_ID uint      `json:"opportunity_id"`
+	Shift_ID       *uint     `json:"shift_id,omitempty"`
+	Code           string    `json:"code"`
+	QR_Payload     string    `json:"qr_payload"`
+	Expires_At     time.Time `json:"expires_at"`
+}
+
+// AttendanceCheckRequest struct
+type AttendanceCheckRequest struct {
+	Volunteer_ID   uint   `json:"volunteer_id"`
+	Opportunity_ID uint   `json:"opportunity_id" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// AttendanceCloseRequest struct
+type AttendanceCloseRequest struct {
+	Shift_ID *uint `json:"shift_id"`
+}
+
+// AttendanceRecordResponse struct
+type AttendanceRecordResponse struct {
+	ID             uint       `json:"id"`
+	Application_ID uint       `json:"application_id"`
+	Check_In_At    time.Time  `json:"check_in_at"`
+	Check_Out_At   *time.Time `json:"check_out_at"`
+	Time_Entry_ID  *uint      `json:"time_entry_id,omitempty"`
+}
+
+// NewAttendanceRecordResponse converts an AttendanceRecord into its API representation
+func NewAttendanceRecordResponse(r AttendanceRecord) AttendanceRecordResponse {
+	return AttendanceRecordResponse{
+		ID:             r.ID,
+		Application_ID: r.Application_ID,
+		Check_In_At:    r.Check_In_At,
+		Check_Out_At:   r.Check_Out_At,
+		Time_Entry_ID:  r.Time_Entry_ID,
+	}
+}
+
+// NewAttendanceRecordResponses converts a list of attendance records into their API representation
+func NewAttendanceRecordResponses(records []AttendanceRecord) []AttendanceRecordResponse {
+	responses := make([]AttendanceRecordResponse, 0, len(records))
+	for _, r := range records {
+		responses = append(responses, NewAttendanceRecordResponse(r))
+	}
+	return responses
+}
+
+// AttendanceCloseResponse struct. The number of applications closing attendance marked
+// Completed and NoShow.
+type AttendanceCloseResponse struct {
+	Completed int64 `json:"completed"`
+	No_Show   int64 `json:"no_show"`
+}
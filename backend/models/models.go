@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"time"
 )
 
@@ -18,12 +19,14 @@ func (s StringList) Value() (driver.Value, error) {
 
 // sql.Scanner interface for StringList (DB -> GO)
 func (s *StringList) Scan(value interface{}) error {
-	bytes, ok := value.([]byte)
-	if !ok {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string: // SQLite returns JSON columns as text
+		return json.Unmarshal([]byte(v), s)
+	default:
 		return errors.New("type assertion to []byte failed")
 	}
-
-	return json.Unmarshal(bytes, s)
 }
 
 // UintList is a custom type for a list of uints
@@ -36,12 +39,14 @@ func (u UintList) Value() (driver.Value, error) {
 
 // sql.Scanner interface for UintList (DB -> GO)
 func (u *UintList) Scan(value interface{}) error {
-	bytes, ok := value.([]byte)
-	if !ok {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, u)
+	case string: // SQLite returns JSON columns as text
+		return json.Unmarshal([]byte(v), u)
+	default:
 		return errors.New("type assertion to []byte failed")
 	}
-
-	return json.Unmarshal(bytes, u)
 }
 
 type CustomDate time.Time
@@ -54,11 +59,12 @@ func (d CustomDate) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + formatted + `"`), nil
 }
 
-// UnmarshalJSON for CustomDate (JSON -> Go)
+// UnmarshalJSON for CustomDate (JSON -> Go). Values that are not dates are reported as type
+// errors, like values of any other wrong type.
 func (d *CustomDate) UnmarshalJSON(data []byte) error {
 	parsed, err := time.Parse(`"`+customDateFormat+`"`, string(data))
 	if err != nil {
-		return err
+		return &json.UnmarshalTypeError{Value: string(data), Type: reflect.TypeOf(*d)}
 	}
 	*d = CustomDate(parsed)
 	return nil
@@ -69,34 +75,35 @@ func (d CustomDate) Value() (driver.Value, error) {
 	return time.Time(d).Format(customDateFormat), nil
 }
 
-// Scan for CustomDate (DB -> Go)
-// Scan for CustomDate (DB -> Go)
+// Scan for CustomDate (DB -> Go). SQLite returns dates as text, with a time of day when the
+// date was written as a time.Time, so only the leading date is read from text.
 func (d *CustomDate) Scan(value interface{}) error {
-    if value == nil {
-        return nil
-    }
-
-    switch v := value.(type) {
-    case time.Time:
-        *d = CustomDate(v)
-        return nil
-    case string:
-        parsed, err := time.Parse(customDateFormat, v)
-        if err != nil {
-            return err
-        }
-        *d = CustomDate(parsed)
-        return nil
-    case []byte:
-        parsed, err := time.Parse(customDateFormat, string(v))
-        if err != nil {
-            return err
-        }
-        *d = CustomDate(parsed)
-        return nil
-    default:
-        return fmt.Errorf("cannot scan type %T into CustomDate", value)
-    }
+	if value == nil {
+		return nil
+	}
+
+	var text string
+	switch v := value.(type) {
+	case time.Time:
+		*d = CustomDate(v)
+		return nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("cannot scan type %T into CustomDate", value)
+	}
+
+	if len(text) > len(customDateFormat) {
+		text = text[:len(customDateFormat)]
+	}
+	parsed, err := time.Parse(customDateFormat, text)
+	if err != nil {
+		return err
+	}
+	*d = CustomDate(parsed)
+	return nil
 }
 
 // ToTime converts CustomDate to time.Time
@@ -104,11 +111,11 @@ func (d CustomDate) ToTime() time.Time {
 	return time.Time(d)
 }
 
-// User struct
+// User struct. Users are platform admin accounts.
 type User struct {
 	ID            uint   `gorm:"primaryKey"`
 	Email         string `gorm:"unique;not null"`
-	Password_Hash string `gorm:"not null"`
+	Password_Hash string `gorm:"not null" json:"-"`
 	Full_Name     string `gorm:"not null"`
 	Role          string `gorm:"not null"`
 	Created_At    time.Time
@@ -117,31 +124,37 @@ type User struct {
 
 // Volunteer struct
 type Volunteer struct {
-	ID               uint       `gorm:"primaryKey" json:"id"`
-	Email            string     `gorm:"unique;not null" json:"email"`
-	Password         string     `gorm:"not null" json:"password"`
-	Name             string     `gorm:"not null" json:"name"`
-	Phone            string     `gorm:"unique;not null" json:"phone"`
-	Location         string     `json:"location"`
-	Bio_Data         string     `json:"bio_data"`
-	Category_List    StringList `gorm:"type:json;not null" json:"category_list"`
-	Availabile_Hours uint       `gorm:"not null" json:"availabile_hours"`
-	Created_At       time.Time  `json:"created_at"`
-	Updated_At       time.Time  `json:"updated_at"`
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	Email           string     `gorm:"unique;not null" json:"email"`
+	Password        string     `gorm:"not null" json:"-"`
+	Name            string     `gorm:"not null" json:"name"`
+	Phone           string     `gorm:"unique;not null" json:"phone"`
+	Location        string     `json:"location"`
+	Latitude        *float64   `json:"latitude"` // Geocoded from Location, nil when unknown
+	Longitude       *float64   `json:"longitude"`
+	Bio_Data        string     `json:"bio_data"`
+	Category_List   StringList `gorm:"type:json;not null" json:"category_list"`
+	Available_Hours uint       `gorm:"not null" json:"available_hours"`
+	Suspended_At    *time.Time `json:"suspended_at"`
+	Created_At      time.Time  `json:"created_at"`
+	Updated_At      time.Time  `json:"updated_at"`
 }
 
 // Organization struct
 type Organization struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	Email       string    `gorm:"unique;not null" json:"email"`
-	Password    string    `gorm:"not null" json:"password"`
-	Name        string    `gorm:"unique;not null" json:"name"`
-	Phone       string    `gorm:"not null" json:"phone"`
-	Location    string    `gorm:"not null" json:"location"`
-	Description string    `gorm:"not null" json:"description"`
-	Website_Url string    `gorm:"not null" json:"website_url"`
-	Created_At  time.Time `json:"created_at"`
-	Updated_At  time.Time `json:"updated_at"`
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Email        string     `gorm:"unique;not null" json:"email"`
+	Password     string     `gorm:"not null" json:"-"`
+	Name         string     `gorm:"unique;not null" json:"name"`
+	Phone        string     `gorm:"not null" json:"phone"`
+	Location     string     `gorm:"not null" json:"location"`
+	Latitude     *float64   `json:"latitude"` // Geocoded from Location, nil when unknown
+	Longitude    *float64   `json:"longitude"`
+	Description  string     `gorm:"not null" json:"description"`
+	Website_Url  string     `gorm:"not null" json:"website_url"`
+	Suspended_At *time.Time `json:"suspended_at"`
+	Created_At   time.Time  `json:"created_at"`
+	Updated_At   time.Time  `json:"updated_at"`
 }
 
 // Category struct
@@ -154,27 +167,52 @@ type Category struct {
 // Opportunity struct
 type Opportunity struct {
 	ID                uint       `gorm:"primaryKey" json:"id"`
-	Organization_mail string     `gorm:"not null" json:"organization_mail"`
+	Organization_mail string     `gorm:"not null;index" json:"organization_mail"`
 	Category          string     `gorm:"not null" json:"category"`
 	Title             string     `gorm:"not null" json:"title"`
 	Description       string     `gorm:"not null" json:"description"`
 	Location          string     `gorm:"not null" json:"location"`
+	Latitude          *float64   `gorm:"index:idx_opportunities_coordinates" json:"latitude"` // Geocoded from Location, nil when unknown or remote
+	Longitude         *float64   `gorm:"index:idx_opportunities_coordinates" json:"longitude"`
+	Remote            bool       `gorm:"not null;default:false" json:"remote"` // Done remotely rather than at Location
 	Hours_Required    uint       `gorm:"not null" json:"hours_required"`
+	Capacity          uint       `gorm:"not null;default:0" json:"capacity"` // Number of volunteers needed, 0 means unlimited
 	Start_Date        CustomDate `gorm:"type:date;not null" json:"start_date"` // Use CustomDate
 	End_Date          CustomDate `gorm:"type:date;not null" json:"end_date"`   // Use CustomDate
+	Hidden_At         *time.Time `json:"hidden_at"`                          // Set when an admin hides the opportunity
+	Moderation_Note   string     `json:"moderation_note"`
 	Created_At        time.Time  `json:"created_at"`
 	Updated_At        time.Time  `json:"updated_at"`
+	Search_Vector     string     `gorm:"->:false;<-:false;type:tsvector GENERATED ALWAYS AS (setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(category, '')), 'B') || setweight(to_tsvector('english', coalesce(description, '')), 'C')) STORED;index:idx_opportunities_search,type:gin" json:"-"` // Kept up to date by the database for full-text search
 }
 
-// Application struct
+// Shift struct. A dated time slot of an opportunity with its own headcount.
+type Shift struct {
+	ID             uint         `gorm:"primaryKey" json:"id"`
+	Opportunity_ID uint         `gorm:"not null;index" json:"opportunity_id"`
+	Date           CustomDate   `gorm:"type:date;not null" json:"date"`
+	Start_Time     string       `gorm:"not null" json:"start_time"`         // 24-hour "15:04"
+	End_Time       string       `gorm:"not null" json:"end_time"`           // 24-hour "15:04"
+	Capacity       uint         `gorm:"not null;default:0" json:"capacity"` // Number of volunteers needed, 0 means unlimited
+	Created_At     time.Time    `json:"created_at"`
+	Updated_At     time.Time    `json:"updated_at"`
+	Opportunity    *Opportunity `gorm:"foreignKey:Opportunity_ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// Application struct. Applications to an opportunity with shifts are made for one shift; a
+// volunteer can apply once per shift, or once per opportunity when it has no shifts.
 type Application struct {
-	ID             uint      `gorm:"primaryKey" json:"id"`
-	Volunteer_ID   uint      `gorm:"not null" json:"volunteer_ID"`
-	Opportunity_ID uint      `gorm:"not null" json:"opportunity_ID"`
-	Status         string    `gorm:"not null" json:"status"`
-	Cover_Letter   string    `gorm:"not null" json:"cover_Letter"`
-	Created_At     time.Time `json:"created_At"`
-	Updated_At     time.Time `json:"updated_At"`
+	ID             uint         `gorm:"primaryKey" json:"id"`
+	Volunteer_ID   uint         `gorm:"not null;uniqueIndex:idx_applications_volunteer_opportunity,where:shift_id IS NULL;uniqueIndex:idx_applications_volunteer_shift" json:"volunteer_ID"`
+	Opportunity_ID uint         `gorm:"not null;uniqueIndex:idx_applications_volunteer_opportunity,where:shift_id IS NULL" json:"opportunity_ID"`
+	Shift_ID       *uint        `gorm:"uniqueIndex:idx_applications_volunteer_shift" json:"shift_ID"`
+	Status         string       `gorm:"not null" json:"status"`
+	Cover_Letter   string       `gorm:"not null" json:"cover_Letter"`
+	Created_At     time.Time    `json:"created_At"`
+	Updated_At     time.Time    `json:"updated_At"`
+	Volunteer      *Volunteer   `gorm:"foreignKey:Volunteer_ID;constraint:OnDelete:CASCADE" json:"-"`
+	Opportunity    *Opportunity `gorm:"foreignKey:Opportunity_ID;constraint:OnDelete:CASCADE" json:"-"`
+	Shift          *Shift       `gorm:"foreignKey:Shift_ID;constraint:OnDelete:CASCADE" json:"-"`
 }
 
 // LoginRequest struct
@@ -183,3 +221,25 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 	Role     string `json:"role" binding:"required"`
 }
+
+// RefreshToken struct
+type RefreshToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Token_Hash string     `gorm:"unique;not null" json:"-"`
+	Subject_ID uint       `gorm:"not null" json:"subject_id"`
+	Email      string     `gorm:"not null;index" json:"email"`
+	Role       string     `gorm:"not null" json:"role"`
+	Expires_At time.Time  `gorm:"not null" json:"expires_at"`
+	Revoked_At *time.Time `json:"revoked_at"`
+	Created_At time.Time  `json:"created_at"`
+}
+
+// ModerationRequest struct
+type ModerationRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RefreshRequest struct
+type RefreshRequest struct {
+	Refresh_Token string `json:"refresh_token" binding:"required"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanText(t *testing.T) {
+	// SQLite returns JSON and date columns as text
+	var list StringList
+	require.NoError(t, list.Scan(`["Education","Health"]`))
+	assert.Equal(t, StringList{"Education", "Health"}, list)
+
+	var ids UintList
+	require.NoError(t, ids.Scan([]byte(`[1,2]`)))
+	assert.Equal(t, UintList{1, 2}, ids)
+	assert.Error(t, ids.Scan(42))
+
+	expected := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, value := range []interface{}{"2025-03-01", []byte("2025-03-01"), "2025-03-01 00:00:00+00:00", expected} {
+		var date CustomDate
+		require.NoError(t, date.Scan(value))
+		assert.Equal(t, expected, date.ToTime())
+	}
+
+	var date CustomDate
+	assert.Error(t, date.Scan("March 1"))
+}
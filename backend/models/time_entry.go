@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Time entry review statuses
+const (
+	TimeEntryPending  = "Pending"
+	TimeEntryApproved = "Approved"
+	TimeEntryDisputed = "Disputed"
+)
+
+// MaxHoursPerEntry is the most hours a single time entry can record
+const MaxHoursPerEntry = 24
+
+// MaxHoursPerDay is the most hours a volunteer can log for one date, over all their entries
+const MaxHoursPerDay = 24
+
+// TimeEntry struct. Hours a volunteer worked on an accepted application, as submitted by the
+// volunteer and reviewed by the organization.
+type TimeEntry struct {
+	ID             uint         `gorm:"primaryKey" json:"id"`
+	Application_ID uint         `gorm:"not null;index" json:"application_id"`
+	Work_Date      CustomDate   `gorm:"type:date;not null" json:"work_date"`
+	Hours          float64      `gorm:"not null" json:"hours"`
+	Note           string       `json:"note"`
+	Status         string       `gorm:"not null;index" json:"status"`
+	Reviewer_ID    uint         `json:"reviewer_id"`
+	Review_Note    string       `json:"review_note"`
+	Reviewed_At    *time.Time   `json:"reviewed_at"`
+	Created_At     time.Time    `json:"created_at"`
+	Updated_At     time.Time    `json:"updated_at"`
+	Application    *Application `gorm:"foreignKey:Application_ID;constraint:OnDelete:CASCADE" json:"-"`
+}
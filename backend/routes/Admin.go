@@ -0,0 +1,352 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"github.com/prathamrao021/HelperHub/models"
+	"gorm.io/gorm"
+)
+
+// Admin moderation of volunteer and organization accounts and of opportunities. Every route
+// in this file is mounted under /admin and is only reachable by admins.
+
+// revokeRefreshTokens revokes every outstanding refresh token of the given account, so the
+// account has to log in again once its current access token expires
+func revokeRefreshTokens(db *gorm.DB, subjectID uint, role string) error {
+	return db.Model(&models.RefreshToken{}).
+		Where("subject_id = ? AND role = ? AND revoked_at IS NULL", subjectID, role).
+		Update("revoked_at", time.Now()).Error
+}
+
+// setSuspended suspends or reinstates an account that has already been loaded into account
+// and reloads it. Suspending an account also revokes its refresh tokens.
+func setSuspended(db *gorm.DB, account interface{}, id uint, role string, suspend bool) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var suspendedAt interface{} = gorm.Expr("NULL")
+		if suspend {
+			suspendedAt = time.Now()
+		}
+		if err := tx.Model(account).Updates(map[string]interface{}{
+			"suspended_at": suspendedAt,
+			"updated_at":   time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		if suspend {
+			if err := revokeRefreshTokens(tx, id, role); err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("id = ?", id).First(account).Error
+	})
+}
+
+// parseSuspensionFilter reads the optional "status" query parameter of an account list
+// ("active" or "suspended")
+func parseSuspensionFilter(c *gin.Context) (store.AccountFilter, bool) {
+	var suspended bool
+	switch c.Query("status") {
+	case "":
+		return store.AccountFilter{}, true
+	case "active":
+		suspended = false
+	case "suspended":
+		suspended = true
+	default:
+		middleware.RespondProblem(c, http.StatusBadRequest, "Invalid status. Must be 'active' or 'suspended'")
+		return store.AccountFilter{}, false
+	}
+	return store.AccountFilter{Suspended: &suspended}, true
+}
+
+// adminGetVolunteers godoc
+// @Summary List volunteers
+// @Description Retrieve all volunteer accounts, optionally filtered by suspension status.
+// @Description Sortable and filterable by id, email, name, location, available_hours, created_at and updated_at.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param status query string false "Filter by status (active or suspended)"
+// @Param sort query string false "Comma-separated fields to sort by, - for descending"
+// @Param filter query []string false "field:op:value, with op one of eq, ne, lt, lte, gt, gte, in and like" collectionFormat(multi)
+// @Param limit query int false "Page size, 20 by default and at most 100"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} listing.Page{data=[]models.VolunteerResponse}
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/volunteers [get]
+func adminGetVolunteers(c *gin.Context, volunteers store.VolunteerStore) {
+	filter, ok := parseSuspensionFilter(c)
+	if !ok {
+		return
+	}
+	options, ok := parseListOptions(c, volunteerListSpec)
+	if !ok {
+		return
+	}
+
+	page, total, err := volunteers.List(c.Request.Context(), filter, options)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, options.Page(models.NewVolunteerResponses(page), total, c.Request.URL))
+}
+
+// adminSuspendVolunteer godoc
+// @Summary Suspend a volunteer
+// @Description Suspend a volunteer account. Suspended volunteers cannot log in or refresh their session.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path uint true "Volunteer ID"
+// @Success 200 {object} models.VolunteerResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/volunteers/{id}/suspend [post]
+func adminSuspendVolunteer(c *gin.Context, db *gorm.DB) {
+	setVolunteerSuspended(c, db, true)
+}
+
+// adminReinstateVolunteer godoc
+// @Summary Reinstate a volunteer
+// @Description Lift the suspension of a volunteer account
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path uint true "Volunteer ID"
+// @Success 200 {object} models.VolunteerResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/volunteers/{id}/reinstate [post]
+func adminReinstateVolunteer(c *gin.Context, db *gorm.DB) {
+	setVolunteerSuspended(c, db, false)
+}
+
+func setVolunteerSuspended(c *gin.Context, db *gorm.DB, suspend bool) {
+	var volunteer models.Volunteer
+	if err := db.Where("id = ?", c.Param("id")).First(&volunteer).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Volunteer not found")
+		return
+	}
+
+	if err := setSuspended(db, &volunteer, volunteer.ID, auth.RoleVolunteer, suspend); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewVolunteerResponse(volunteer))
+}
+
+// adminGetOrganizations godoc
+// @Summary List organizations
+// @Description Retrieve all organization accounts, optionally filtered by suspension status.
+// @Description Sortable and filterable by id, email, name, location, created_at and updated_at.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param status query string false "Filter by status (active or suspended)"
+// @Param sort query string false "Comma-separated fields to sort by, - for descending"
+// @Param filter query []string false "field:op:value, with op one of eq, ne, lt, lte, gt, gte, in and like" collectionFormat(multi)
+// @Param limit query int false "Page size, 20 by default and at most 100"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} listing.Page{data=[]models.OrganizationResponse}
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/organizations [get]
+func adminGetOrganizations(c *gin.Context, organizations store.OrganizationStore) {
+	filter, ok := parseSuspensionFilter(c)
+	if !ok {
+		return
+	}
+	options, ok := parseListOptions(c, organizationListSpec)
+	if !ok {
+		return
+	}
+
+	page, total, err := organizations.List(c.Request.Context(), filter, options)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, options.Page(models.NewOrganizationResponses(page), total, c.Request.URL))
+}
+
+// adminSuspendOrganization godoc
+// @Summary Suspend an organization
+// @Description Suspend an organization account. Suspended organizations cannot log in and their opportunities are no longer listed as available.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path uint true "Organization ID"
+// @Success 200 {object} models.OrganizationResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/organizations/{id}/suspend [post]
+func adminSuspendOrganization(c *gin.Context, db *gorm.DB) {
+	setOrganizationSuspended(c, db, true)
+}
+
+// adminReinstateOrganization godoc
+// @Summary Reinstate an organization
+// @Description Lift the suspension of an organization account
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path uint true "Organization ID"
+// @Success 200 {object} models.OrganizationResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/organizations/{id}/reinstate [post]
+func adminReinstateOrganization(c *gin.Context, db *gorm.DB) {
+	setOrganizationSuspended(c, db, false)
+}
+
+func setOrganizationSuspended(c *gin.Context, db *gorm.DB, suspend bool) {
+	var organization models.Organization
+	if err := db.Where("id = ?", c.Param("id")).First(&organization).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Organization not found")
+		return
+	}
+
+	if err := setSuspended(db, &organization, organization.ID, auth.RoleOrganization, suspend); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewOrganizationResponse(organization))
+}
+
+// adminGetOpportunities godoc
+// @Summary List opportunities
+// @Description Retrieve all opportunities including hidden and expired ones, optionally filtered by moderation status.
+// @Description Sortable and filterable by id, organization_mail, category, title, location, remote, hours_required, capacity, start_date, end_date and created_at.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param status query string false "Filter by status (visible or hidden)"
+// @Param sort query string false "Comma-separated fields to sort by, - for descending"
+// @Param filter query []string false "field:op:value, with op one of eq, ne, lt, lte, gt, gte, in and like" collectionFormat(multi)
+// @Param limit query int false "Page size, 20 by default and at most 100"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} listing.Page{data=[]models.OpportunityResponse}
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/opportunities [get]
+func adminGetOpportunities(c *gin.Context, opportunities store.OpportunityStore) {
+	var filter store.OpportunityFilter
+	switch status := c.Query("status"); status {
+	case "":
+	case "visible", "hidden":
+		hidden := status == "hidden"
+		filter.Hidden = &hidden
+	default:
+		middleware.RespondProblem(c, http.StatusBadRequest, "Invalid status. Must be 'visible' or 'hidden'")
+		return
+	}
+	options, ok := parseListOptions(c, adminOpportunityListSpec)
+	if !ok {
+		return
+	}
+
+	page, total, err := opportunities.List(c.Request.Context(), filter, options)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, options.Page(models.NewOpportunityResponses(page), total, c.Request.URL))
+}
+
+// adminHideOpportunity godoc
+// @Summary Hide an opportunity
+// @Description Hide an opportunity from the available listings, recording the reason as a moderation note
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path uint true "Opportunity ID"
+// @Param moderation body models.ModerationRequest false "Moderation reason"
+// @Success 200 {object} models.OpportunityResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/opportunities/{id}/hide [post]
+func adminHideOpportunity(c *gin.Context, opportunities store.OpportunityStore) {
+	var request models.ModerationRequest
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &request) {
+			return
+		}
+	}
+
+	now := time.Now()
+	moderateOpportunity(c, opportunities, func(opportunity *models.Opportunity) {
+		opportunity.Hidden_At = &now
+		opportunity.Moderation_Note = request.Reason
+	})
+}
+
+// adminRestoreOpportunity godoc
+// @Summary Restore a hidden opportunity
+// @Description Make a hidden opportunity visible again and clear its moderation note
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path uint true "Opportunity ID"
+// @Success 200 {object} models.OpportunityResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/opportunities/{id}/restore [post]
+func adminRestoreOpportunity(c *gin.Context, opportunities store.OpportunityStore) {
+	moderateOpportunity(c, opportunities, func(opportunity *models.Opportunity) {
+		opportunity.Hidden_At = nil
+		opportunity.Moderation_Note = ""
+	})
+}
+
+func moderateOpportunity(c *gin.Context, opportunities store.OpportunityStore, moderate func(*models.Opportunity)) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		return
+	}
+	opportunity, err := opportunities.Get(c.Request.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	moderate(&opportunity)
+	opportunity.Updated_At = time.Now()
+	if err := opportunities.Update(c.Request.Context(), &opportunity); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewOpportunityResponse(opportunity))
+}
@@ -0,0 +1,332 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func setupTestDBForAdmin() *gorm.DB {
+	return setupTestDBForVolunteer()
+}
+
+func setupRouterForAdmin(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	stores := store.NewGorm(db)
+	tokens := newTestTokenManager()
+
+	r.POST("/login/admin", func(c *gin.Context) {
+		loginAdmin(c, db, tokens)
+	})
+	r.POST("/login/volunteer", func(c *gin.Context) {
+		loginVolunteer(c, db, tokens)
+	})
+	r.POST("/auth/refresh", func(c *gin.Context) {
+		refreshSession(c, db, tokens)
+	})
+	r.GET("/admin/volunteers", func(c *gin.Context) {
+		adminGetVolunteers(c, stores.Volunteers)
+	})
+	r.POST("/admin/volunteers/:id/suspend", func(c *gin.Context) {
+		adminSuspendVolunteer(c, db)
+	})
+	r.POST("/admin/volunteers/:id/reinstate", func(c *gin.Context) {
+		adminReinstateVolunteer(c, db)
+	})
+	r.GET("/opportunities/available", func(c *gin.Context) {
+		getAvailableOpportunities(c, db)
+	})
+	r.GET("/admin/opportunities", func(c *gin.Context) {
+		adminGetOpportunities(c, stores.Opportunities)
+	})
+	r.POST("/admin/opportunities/:id/hide", func(c *gin.Context) {
+		adminHideOpportunity(c, stores.Opportunities)
+	})
+	r.POST("/admin/opportunities/:id/restore", func(c *gin.Context) {
+		adminRestoreOpportunity(c, stores.Opportunities)
+	})
+
+	return r
+}
+
+func createTestAdmin(db *gorm.DB) models.User {
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("adminpassword"), bcrypt.DefaultCost)
+
+	admin := models.User{
+		Email:         "admin@helperhub.com",
+		Password_Hash: string(hashedPassword),
+		Full_Name:     "Test Admin",
+		Role:          auth.RoleAdmin,
+		Created_At:    time.Now(),
+		Updated_At:    time.Now(),
+	}
+
+	if err := db.Create(&admin).Error; err != nil {
+		panic("Failed to create test admin: " + err.Error())
+	}
+
+	return admin
+}
+
+func cleanupTestAdmins(db *gorm.DB) {
+	db.Exec("DELETE FROM users")
+	db.Exec("DELETE FROM refresh_tokens")
+	cleanupTestVolunteers(db)
+}
+
+func TestLoginAdmin(t *testing.T) {
+	db := setupTestDBForAdmin()
+	router := setupRouterForAdmin(db)
+	defer cleanupTestAdmins(db)
+
+	admin := createTestAdmin(db)
+
+	w := sendJSON(router, "POST", "/login/admin", LoginRequest{
+		Email:    admin.Email,
+		Password: "adminpassword",
+		Role:     auth.RoleAdmin,
+	})
+	t.Logf("Response Body: %s", w.Body.String())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "password")
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	principal, err := newTestTokenManager().ParseAccessToken(response["access_token"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, auth.RoleAdmin, principal.Role)
+	assert.Equal(t, admin.ID, principal.ID)
+
+	// A wrong password is rejected
+	w = sendJSON(router, "POST", "/login/admin", LoginRequest{
+		Email:    admin.Email,
+		Password: "wrongpassword",
+		Role:     auth.RoleAdmin,
+	})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSuspendAndReinstateVolunteer(t *testing.T) {
+	db := setupTestDBForAdmin()
+	router := setupRouterForAdmin(db)
+	defer cleanupTestAdmins(db)
+
+	volunteer := createTestVolunteer(db)
+	session := loginTestVolunteer(t, router)
+
+	w := sendJSON(router, "POST", fmt.Sprintf("/admin/volunteers/%d/suspend", volunteer.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NotNil(t, response["suspended_at"])
+
+	// The suspended volunteer shows up in the filtered list
+	w = sendJSON(router, "GET", "/admin/volunteers?status=suspended", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var suspended []map[string]interface{}
+	page := decodePage(t, w, &suspended)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Len(t, suspended, 1)
+	assert.Equal(t, volunteer.Email, suspended[0]["email"])
+
+	// Suspension blocks login and revokes existing refresh tokens
+	w = sendJSON(router, "POST", "/login/volunteer", LoginRequest{Email: volunteer.Email, Password: "testpassword", Role: "volunteer"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = postRefreshToken(router, "/auth/refresh", session["refresh_token"].(string))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = sendJSON(router, "POST", fmt.Sprintf("/admin/volunteers/%d/reinstate", volunteer.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var reinstated models.Volunteer
+	db.First(&reinstated, volunteer.ID)
+	assert.Nil(t, reinstated.Suspended_At)
+
+	loginTestVolunteer(t, router)
+}
+
+func TestSuspendNonExistentVolunteer(t *testing.T) {
+	db := setupTestDBForAdmin()
+	router := setupRouterForAdmin(db)
+	defer cleanupTestAdmins(db)
+
+	w := sendJSON(router, "POST", "/admin/volunteers/99999/suspend", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminGetVolunteersInvalidStatus(t *testing.T) {
+	db := setupTestDBForAdmin()
+	router := setupRouterForAdmin(db)
+	defer cleanupTestAdmins(db)
+
+	w := sendJSON(router, "GET", "/admin/volunteers?status=banned", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHideAndRestoreOpportunity(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForAdmin(db)
+	defer cleanupTestOpportunities(db)
+
+	opp := createTestOpportunity(db)
+
+	w := sendJSON(router, "POST", fmt.Sprintf("/admin/opportunities/%d/hide", opp.ID), models.ModerationRequest{Reason: "Spam"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NotNil(t, response["hidden_at"])
+	assert.Equal(t, "Spam", response["moderation_note"])
+
+	// Hidden opportunities are no longer listed as available
+	w = sendJSON(router, "GET", "/opportunities/available", nil)
+	var available []map[string]interface{}
+	decodePage(t, w, &available)
+	assert.Len(t, available, 0)
+
+	// But admins still see them
+	w = sendJSON(router, "GET", "/admin/opportunities?status=hidden", nil)
+	var hidden []map[string]interface{}
+	decodePage(t, w, &hidden)
+	assert.Len(t, hidden, 1)
+
+	w = sendJSON(router, "POST", fmt.Sprintf("/admin/opportunities/%d/restore", opp.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = sendJSON(router, "GET", "/opportunities/available", nil)
+	decodePage(t, w, &available)
+	assert.Len(t, available, 1)
+}
+
+func TestAdminRoutesRequireAdmin(t *testing.T) {
+	db := setupTestDBForAdmin()
+	defer cleanupTestAdmins(db)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	tokens := newTestTokenManager()
+	SetupRoutes(router, db, store.NewGorm(db), testConfig(), tokens, testGeocoder)
+
+	volunteerToken, _, _ := tokens.IssueAccessToken(auth.Principal{ID: 1, Email: "test@volunteer.com", Role: auth.RoleVolunteer})
+	adminToken, _, _ := tokens.IssueAccessToken(auth.Principal{ID: 1, Email: "admin@helperhub.com", Role: auth.RoleAdmin})
+
+	// Anonymous callers must log in first
+	req, _ := http.NewRequest("GET", "/admin/applications", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Volunteers are not admins
+	req, _ = http.NewRequest("GET", "/admin/applications", nil)
+	req.Header.Set("Authorization", "Bearer "+volunteerToken)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// The global application list is no longer exposed outside /admin
+	req, _ = http.NewRequest("GET", "/applications/", nil)
+	req.Header.Set("Authorization", "Bearer "+volunteerToken)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.NotEqual(t, http.StatusOK, w.Code)
+
+	// Nor are the applications of every volunteer with a status
+	req, _ = http.NewRequest("GET", "/applications/status/Pending", nil)
+	req.Header.Set("Authorization", "Bearer "+volunteerToken)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, _ = http.NewRequest("GET", "/applications/status/Pending", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("GET", "/admin/volunteers", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAdminListsAndModerationInMemory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	stores := store.NewMemory()
+	router.GET("/admin/volunteers", func(c *gin.Context) { adminGetVolunteers(c, stores.Volunteers) })
+	router.GET("/admin/opportunities", func(c *gin.Context) { adminGetOpportunities(c, stores.Opportunities) })
+	router.POST("/admin/opportunities/:id/hide", func(c *gin.Context) { adminHideOpportunity(c, stores.Opportunities) })
+	router.POST("/admin/opportunities/:id/restore", func(c *gin.Context) { adminRestoreOpportunity(c, stores.Opportunities) })
+
+	ctx := context.Background()
+	suspendedAt := time.Now()
+	assert.NoError(t, stores.Volunteers.Create(ctx, &models.Volunteer{Email: "active@volunteer.com", Phone: "1"}))
+	assert.NoError(t, stores.Volunteers.Create(ctx, &models.Volunteer{Email: "suspended@volunteer.com", Phone: "2", Suspended_At: &suspendedAt}))
+
+	var volunteers []models.VolunteerResponse
+	decodePage(t, sendJSON(router, "GET", "/admin/volunteers?status=suspended", nil), &volunteers)
+	if assert.Len(t, volunteers, 1) {
+		assert.Equal(t, "suspended@volunteer.com", volunteers[0].Email)
+	}
+
+	opportunity := models.Opportunity{Title: "Beach Cleanup"}
+	assert.NoError(t, stores.Opportunities.Create(ctx, &opportunity))
+
+	w := sendJSON(router, "POST", fmt.Sprintf("/admin/opportunities/%d/hide", opportunity.ID), models.ModerationRequest{Reason: "Spam"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var hidden []models.OpportunityResponse
+	decodePage(t, sendJSON(router, "GET", "/admin/opportunities?status=hidden", nil), &hidden)
+	if assert.Len(t, hidden, 1) {
+		assert.Equal(t, "Spam", hidden[0].Moderation_Note)
+	}
+
+	w = sendJSON(router, "POST", fmt.Sprintf("/admin/opportunities/%d/restore", opportunity.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var visible []models.OpportunityResponse
+	decodePage(t, sendJSON(router, "GET", "/admin/opportunities?status=visible", nil), &visible)
+	assert.Len(t, visible, 1)
+
+	w = sendJSON(router, "POST", "/admin/opportunities/99/hide", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// failingOpportunities is an opportunity store whose reads fail
+type failingOpportunities struct {
+	store.OpportunityStore
+}
+
+func (failingOpportunities) Get(ctx context.Context, id uint) (models.Opportunity, error) {
+	return models.Opportunity{}, errors.New("connection refused")
+}
+
+func TestModerateOpportunityLookupFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/admin/opportunities/:id/hide", func(c *gin.Context) { adminHideOpportunity(c, failingOpportunities{}) })
+
+	// A store that cannot be read is not mistaken for a missing opportunity
+	w := sendJSON(router, "POST", "/admin/opportunities/1/hide", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	w = sendJSON(router, "POST", "/admin/opportunities/abc/hide", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -1,80 +1,292 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/internal/metrics"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/middleware"
 	"github.com/prathamrao021/HelperHub/models"
 	"gorm.io/gorm"
 )
 
+var (
+	errAlreadyApplied    = errors.New("Volunteer has already applied to this opportunity")
+	errOpportunityClosed = errors.New("Opportunity is not open for applications")
+	errOpportunityEnded  = errors.New("Opportunity has already ended")
+	errShiftRequired     = errors.New("Opportunity has shifts, shift_ID is required")
+	errShiftNotFound     = errors.New("Shift not found")
+	errShiftEnded        = errors.New("Shift has already taken place")
+)
+
 // createApplication godoc
 // @Summary Create a new application
-// @Description Create a new application with the provided details
+// @Description Create a new application with the provided details. New applications start as Pending, or as Waitlisted when the opportunity is at capacity.
+// @Description The volunteer and opportunity must exist, the opportunity must not have ended, and a volunteer can only apply to an opportunity once.
+// @Description Applications to an opportunity with shifts name one of its shifts; a volunteer can apply once per shift.
 // @Tags applications
 // @Accept json
 // @Produce json
-// @Param application body models.Application true "Application data"
-// @Success 200 {object} models.Application
+// @Param application body models.ApplicationCreateRequest true "Application data"
+// @Success 200 {object} models.ApplicationResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /applications [post]
 func createApplication(c *gin.Context, db *gorm.DB) {
-	var application models.Application
-	if err := c.ShouldBindJSON(&application); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var request models.ApplicationCreateRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	// Every application starts out pending
+	if request.Status != "" {
+		if status, ok := models.ParseApplicationStatus(request.Status); !ok || status != models.ApplicationPending {
+			middleware.RespondProblem(c, http.StatusUnprocessableEntity, "New applications must have status "+models.ApplicationPending)
+			return
+		}
+	}
+
+	application := models.Application{
+		Volunteer_ID:   request.Volunteer_ID,
+		Opportunity_ID: request.Opportunity_ID,
+		Shift_ID:       request.Shift_ID,
+		Status:         models.ApplicationPending,
+		Cover_Letter:   request.Cover_Letter,
+	}
+
+	// Volunteers can only apply on their own behalf
+	principal, _ := middleware.CurrentPrincipal(c)
+	if principal != nil && principal.Role == auth.RoleVolunteer {
+		if application.Volunteer_ID == 0 {
+			application.Volunteer_ID = principal.ID
+		}
+		if application.Volunteer_ID != principal.ID {
+			middleware.AbortForbidden(c)
+			return
+		}
+	}
+
+	if application.Volunteer_ID == 0 || application.Opportunity_ID == 0 {
+		middleware.RespondProblem(c, http.StatusUnprocessableEntity, "volunteer_ID and opportunity_ID are required")
+		return
+	}
+
+	var volunteer models.Volunteer
+	if err := db.Where("id = ?", application.Volunteer_ID).First(&volunteer).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Volunteer not found")
 		return
 	}
 
 	application.Created_At = time.Now()
 	application.Updated_At = time.Now()
 
-	if err := db.Create(&application).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	err := db.Transaction(func(tx *gorm.DB) error {
+		opportunity, err := lockOpportunity(tx, application.Opportunity_ID)
+		if err != nil {
+			return err
+		}
+
+		if opportunity.Hidden_At != nil {
+			return errOpportunityClosed
+		}
+		now := time.Now()
+		endDate := opportunity.End_Date.ToTime()
+		if endDate.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, endDate.Location())) {
+			return errOpportunityEnded
+		}
+
+		// Opportunities with shifts take applications for one shift at a time
+		var shifts int64
+		if err := tx.Model(&models.Shift{}).Where("opportunity_id = ?", opportunity.ID).Count(&shifts).Error; err != nil {
+			return err
+		}
+		if application.Shift_ID == nil && shifts > 0 {
+			return errShiftRequired
+		}
+		if application.Shift_ID != nil {
+			var shift models.Shift
+			if err := tx.Where("id = ? AND opportunity_id = ?", *application.Shift_ID, opportunity.ID).First(&shift).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errShiftNotFound
+				}
+				return err
+			}
+			shiftDate := shift.Date.ToTime()
+			if shiftDate.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, shiftDate.Location())) {
+				return errShiftEnded
+			}
+		}
+
+		// The unique indexes on (volunteer_id, opportunity_id) and (volunteer_id, shift_id) back this check up
+		existing := tx.Model(&models.Application{}).Where("volunteer_id = ?", application.Volunteer_ID)
+		if application.Shift_ID != nil {
+			existing = existing.Where("shift_id = ?", *application.Shift_ID)
+		} else {
+			existing = existing.Where("opportunity_id = ? AND shift_id IS NULL", application.Opportunity_ID)
+		}
+		var count int64
+		if err := existing.Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errAlreadyApplied
+		}
+
+		// Once the opportunity or shift is full, new applicants join the waitlist
+		open, err := hasOpenSeat(tx, opportunity, application.Shift_ID)
+		if err != nil {
+			return err
+		}
+		if !open {
+			application.Status = models.ApplicationWaitlisted
+		}
+
+		if err := tx.Create(&application).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return errAlreadyApplied
+			}
+			return err
+		}
+		return recordApplicationStatus(tx, application.ID, "", application.Status, principal)
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		return
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		// The volunteer or opportunity was deleted while the application was being made
+		middleware.RespondProblem(c, http.StatusNotFound, "Volunteer or opportunity not found")
+		return
+	case errors.Is(err, errAlreadyApplied):
+		middleware.RespondProblem(c, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, errShiftNotFound):
+		middleware.RespondProblem(c, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, errOpportunityClosed), errors.Is(err, errOpportunityEnded),
+		errors.Is(err, errShiftRequired), errors.Is(err, errShiftEnded):
+		middleware.RespondProblem(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	case err != nil:
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, application)
+	c.JSON(http.StatusOK, models.NewApplicationResponse(application))
 }
 
 // getAllApplications godoc
 // @Summary Retrieve all applications (Admin-only)
-// @Description Retrieve all applications
-// @Tags applications
+// @Description Retrieve all applications across every opportunity, newest first.
+// @Description Sortable and filterable by id, volunteer_id, opportunity_id, shift_id, status, created_at and updated_at.
+// @Tags admin
 // @Accept json
 // @Produce json
-// @Success 200 {array} models.Application
-// @Router /applications [get]
-func getAllApplications(c *gin.Context, db *gorm.DB) {
-	var applications []models.Application
+// @Param sort query string false "Comma-separated fields to sort by, - for descending"
+// @Param filter query []string false "field:op:value, with op one of eq, ne, lt, lte, gt, gte, in and like" collectionFormat(multi)
+// @Param limit query int false "Page size, 20 by default and at most 100"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} listing.Page{data=[]models.ApplicationResponse}
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/applications [get]
+func getAllApplications(c *gin.Context, applications store.ApplicationStore) {
+	options, ok := parseListOptions(c, applicationListSpec)
+	if !ok {
+		return
+	}
 
-	if err := db.Find(&applications).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	page, total, err := applications.List(c.Request.Context(), store.ApplicationFilter{}, options)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, applications)
+	c.JSON(http.StatusOK, options.Page(models.NewApplicationResponses(page), total, c.Request.URL))
 }
 
 // getApplicationByID godoc
 // @Summary Retrieve an application by ID
-// @Description Retrieve an application by ID
+// @Description Retrieve an application by ID. Only the applying volunteer and the organization that posted the opportunity can read it.
 // @Tags applications
 // @Accept json
 // @Produce json
 // @Param id path uint true "Application ID"
-// @Success 200 {object} models.Application
+// @Success 200 {object} models.ApplicationResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /applications/{id} [get]
-func getApplicationByID(c *gin.Context, db *gorm.DB) {
+func getApplicationByID(c *gin.Context, applications store.ApplicationStore) {
+	id, ok := paramID(c, "id")
+	application, err := applications.Get(c.Request.Context(), id)
+	if !ok || err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Application not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewApplicationResponse(application))
+}
+
+// getApplicationStatusHistory godoc
+// @Summary Retrieve the status history of an application
+// @Description Retrieve every status change of an application in order, with the actor that made it
+// @Tags applications
+// @Accept json
+// @Produce json
+// @Param id path uint true "Application ID"
+// @Success 200 {array} models.ApplicationStatusHistoryResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /applications/{id}/history [get]
+func getApplicationStatusHistory(c *gin.Context, db *gorm.DB) {
 	id := c.Param("id")
 	var application models.Application
 
 	if err := db.Where("id = ?", id).First(&application).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		middleware.RespondProblem(c, http.StatusNotFound, "Application not found")
+		return
+	}
+
+	var history []models.ApplicationStatusHistory
+	if err := db.Where("application_id = ?", application.ID).Order("created_at ASC, id ASC").Find(&history).Error; err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, application)
+	c.JSON(http.StatusOK, models.NewApplicationStatusHistoryResponses(history))
+}
+
+// recordApplicationStatus writes a status history row for an application entering a new status
+// and counts the transition. The count is not taken back if the transaction is rolled back
+// afterwards, which only happens when a later step fails.
+func recordApplicationStatus(tx *gorm.DB, applicationID uint, from string, to string, actor *auth.Principal) error {
+	entry := models.ApplicationStatusHistory{
+		Application_ID: applicationID,
+		From_Status:    from,
+		To_Status:      to,
+		Created_At:     time.Now(),
+	}
+	if actor != nil {
+		entry.Actor_ID = actor.ID
+		entry.Actor_Role = actor.Role
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return err
+	}
+	metrics.ApplicationTransitions.WithLabelValues(from, to).Inc()
+	return nil
 }
 
 // // getApplicationsByVolunteerID godoc
@@ -91,7 +303,7 @@ func getApplicationByID(c *gin.Context, db *gorm.DB) {
 // 	var applications []models.Application
 
 // 	if err := db.Where("volunteer_id = ?", volunteerID).Find(&applications).Error; err != nil {
-// 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+// 		respondError(c, err)
 // 		return
 // 	}
 
@@ -112,7 +324,7 @@ func getApplicationByID(c *gin.Context, db *gorm.DB) {
 // 	var applications []models.Application
 
 // 	if err := db.Where("opportunity_id = ?", opportunityID).Find(&applications).Error; err != nil {
-// 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+// 		respondError(c, err)
 // 		return
 // 	}
 
@@ -121,80 +333,225 @@ func getApplicationByID(c *gin.Context, db *gorm.DB) {
 
 // getApplicationsByStatus godoc
 // @Summary Retrieve applications by status
-// @Description Retrieve applications by status
+// @Description Retrieve applications by status, newest first. Only admins can list them. Sorts and filters work as they do for /admin/applications.
 // @Tags applications
 // @Accept json
 // @Produce json
 // @Param status path string true "Status"
-// @Success 200 {array} models.Application
+// @Param sort query string false "Comma-separated fields to sort by, - for descending"
+// @Param filter query []string false "field:op:value, with op one of eq, ne, lt, lte, gt, gte, in and like" collectionFormat(multi)
+// @Param limit query int false "Page size, 20 by default and at most 100"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} listing.Page{data=[]models.ApplicationResponse}
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /applications/status/{status} [get]
-func getApplicationsByStatus(c *gin.Context, db *gorm.DB) {
-	status := c.Param("status")
-	var applications []models.Application
+func getApplicationsByStatus(c *gin.Context, applications store.ApplicationStore) {
+	status, ok := models.ParseApplicationStatus(c.Param("status"))
+	if !ok {
+		middleware.RespondProblem(c, http.StatusBadRequest, "Invalid status")
+		return
+	}
+
+	options, ok := parseListOptions(c, applicationListSpec)
+	if !ok {
+		return
+	}
 
-	if err := db.Where("status = ?", status).Find(&applications).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	page, total, err := applications.List(c.Request.Context(), store.ApplicationFilter{Status: status}, options)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, applications)
+	c.JSON(http.StatusOK, options.Page(models.NewApplicationResponses(page), total, c.Request.URL))
 }
 
 // updateApplication godoc
 // @Summary Update application details
-// @Description Update application details (Cover Letter for Volunteers, Status for Organizations)
+// @Description Update application details (Cover Letter and withdrawal for Volunteers, Status for Organizations).
+// @Description Status changes must follow the lifecycle: Pending or Waitlisted to Accepted, Rejected or Withdrawn, and Accepted to Completed, NoShow, Withdrawn or Removed.
+// @Description Accepting fails with 409 when the opportunity is at capacity. When an accepted volunteer leaves, the oldest waitlisted application is accepted.
 // @Tags applications
 // @Accept json
 // @Produce json
 // @Param id path uint true "Application ID"
-// @Param application body models.Application true "Application data"
-// @Success 200 {object} models.Application
+// @Param application body models.ApplicationUpdateRequest true "Application data"
+// @Success 200 {object} models.ApplicationResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /applications/{id} [put]
 func updateApplication(c *gin.Context, db *gorm.DB) {
 	id := c.Param("id")
 	var application models.Application
 
 	if err := db.Where("id = ?", id).First(&application).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		middleware.RespondProblem(c, http.StatusNotFound, "Application not found")
 		return
 	}
 
-	if err := c.ShouldBindJSON(&application); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var request models.ApplicationUpdateRequest
+	if !bindJSON(c, &request) {
 		return
 	}
 
-	application.Updated_At = time.Now()
+	// Rows written before statuses were canonical may use a different spelling
+	currentStatus := application.Status
+	if status, ok := models.ParseApplicationStatus(application.Status); ok {
+		currentStatus = status
+	}
+
+	newStatus := currentStatus
+	if request.Status != nil {
+		status, ok := models.ParseApplicationStatus(*request.Status)
+		if !ok {
+			middleware.RespondProblem(c, http.StatusUnprocessableEntity, "Invalid status")
+			return
+		}
+		newStatus = status
+	}
+
+	statusChanged := newStatus != currentStatus
+	coverLetterChanged := request.Cover_Letter != nil && *request.Cover_Letter != application.Cover_Letter
+
+	// Volunteers may only edit their cover letter and withdraw; organizations may only decide on the application
+	principal, _ := middleware.CurrentPrincipal(c)
+	if principal != nil && !middleware.IsAdmin(principal) {
+		if (principal.Role == auth.RoleVolunteer && statusChanged && newStatus != models.ApplicationWithdrawn) ||
+			(principal.Role == auth.RoleOrganization && (coverLetterChanged || (statusChanged && newStatus == models.ApplicationWithdrawn))) {
+			middleware.AbortForbidden(c)
+			return
+		}
+	}
+
+	if statusChanged && !models.CanTransitionApplication(currentStatus, newStatus) {
+		middleware.RespondProblem(c, http.StatusConflict, "Cannot change application status from "+currentStatus+" to "+newStatus)
+		return
+	}
+
+	updates := map[string]interface{}{"status": newStatus, "updated_at": time.Now()}
+	if request.Cover_Letter != nil {
+		updates["cover_letter"] = *request.Cover_Letter
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		opportunity, err := lockOpportunity(tx, application.Opportunity_ID)
+		if err != nil {
+			return err
+		}
+
+		if statusChanged && newStatus == models.ApplicationAccepted {
+			open, err := hasOpenSeat(tx, opportunity, application.Shift_ID)
+			if err != nil {
+				return err
+			}
+			if !open {
+				return errOpportunityFull
+			}
+		}
+
+		// Only apply the change if nobody else changed the status in the meantime
+		result := tx.Model(&models.Application{}).
+			Where("id = ? AND status = ?", application.ID, application.Status).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errApplicationChanged
+		}
+
+		if !statusChanged {
+			return nil
+		}
+		if err := recordApplicationStatus(tx, application.ID, currentStatus, newStatus, principal); err != nil {
+			return err
+		}
 
-	if err := db.Save(&application).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		// A volunteer leaving frees a seat for the waitlist
+		if models.HoldsSeat(currentStatus) && !models.HoldsSeat(newStatus) {
+			return promoteWaitlisted(tx, opportunity)
+		}
+		return nil
+	})
+	if err == errOpportunityFull || err == errApplicationChanged {
+		middleware.RespondProblem(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err == gorm.ErrRecordNotFound {
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := db.Where("id = ?", application.ID).First(&application).Error; err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, application)
+	c.JSON(http.StatusOK, models.NewApplicationResponse(application))
 }
 
 // deleteApplication godoc
 // @Summary Delete an application
-// @Description Delete an application by ID
+// @Description Delete an application by ID. Only Pending and Waitlisted applications can be deleted; once the organization has decided on an application, withdraw it instead, which keeps its history.
 // @Tags applications
 // @Accept json
 // @Produce json
 // @Param id path uint true "Application ID"
 // @Success 200 {object} map[string]string
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /applications/{id} [delete]
 func deleteApplication(c *gin.Context, db *gorm.DB) {
 	id := c.Param("id")
 	var application models.Application
 
 	if err := db.Where("id = ?", id).First(&application).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		middleware.RespondProblem(c, http.StatusNotFound, "Application not found")
 		return
-	} else {
-		if err := db.Where("id = ?", id).Delete(&application).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+	}
+
+	status := application.Status
+	if canonical, ok := models.ParseApplicationStatus(status); ok {
+		status = canonical
+	}
+	if !models.CanDeleteApplication(status) {
+		middleware.RespondProblem(c, http.StatusConflict, "Only Pending or Waitlisted applications can be deleted, withdraw the application instead")
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("application_id = ?", application.ID).Delete(&models.ApplicationStatusHistory{}).Error; err != nil {
+			return err
 		}
+
+		// Only delete the application if nobody decided on it in the meantime
+		result := tx.Where("id = ? AND status = ?", application.ID, application.Status).Delete(&models.Application{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errApplicationChanged
+		}
+		return nil
+	})
+	if err == errApplicationChanged {
+		middleware.RespondProblem(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Application deleted successfully"})
@@ -202,30 +559,32 @@ func deleteApplication(c *gin.Context, db *gorm.DB) {
 
 // getLastNApprovedApplications godoc
 // @Summary Retrieve the last 'n' approved applications for a volunteer
-// @Description Retrieve the last 'n' applications with the status "Approved" for a specific volunteer
+// @Description Retrieve the last 'n' applications for a specific volunteer that were accepted (status Accepted or Completed)
 // @Tags applications
 // @Accept json
 // @Produce json
 // @Param volunteer_id path uint true "Volunteer ID"
 // @Param n query int true "Number of applications"
-// @Success 200 {array} models.Application
+// @Success 200 {array} models.ApplicationResponse
+// @Failure 403 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /applications/volunteer/{volunteer_id}/approved [get]
 func getLastNApprovedApplications(c *gin.Context, db *gorm.DB) {
 	volunteerID := c.Param("volunteer_id")
 	nStr := c.Query("n")
 	n, err := strconv.Atoi(nStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid number of applications"})
+		middleware.RespondProblem(c, http.StatusBadRequest, "Invalid number of applications")
 		return
 	}
 
 	var applications []models.Application
-	if err := db.Where("volunteer_id = ? AND status = ?", volunteerID, "Approved").Order("created_at desc").Limit(n).Find(&applications).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.Where("volunteer_id = ? AND status IN ?", volunteerID, models.ApprovedApplicationStatuses).Order("created_at desc").Limit(n).Find(&applications).Error; err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, applications)
+	c.JSON(http.StatusOK, models.NewApplicationResponses(applications))
 }
 
 // getLastNAcceptedOpportunitiesForVolunteer godoc
@@ -236,14 +595,16 @@ func getLastNApprovedApplications(c *gin.Context, db *gorm.DB) {
 // @Produce json
 // @Param volunteer_id path uint true "Volunteer ID"
 // @Param n query int true "Number of opportunities"
-// @Success 200 {array} models.Opportunity
-// @Router /opportunities/volunteer/{volunteer_id}/accepted-expired [get]
+// @Success 200 {array} models.OpportunityResponse
+// @Failure 403 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /applications/volunteer/{volunteer_id}/completed [get]
 func getLastNAcceptedOpportunitiesForVolunteer(c *gin.Context, db *gorm.DB) {
 	volunteerID := c.Param("volunteer_id")
 	nStr := c.Query("n")
 	n, err := strconv.Atoi(nStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid number of opportunities"})
+		middleware.RespondProblem(c, http.StatusBadRequest, "Invalid number of opportunities")
 		return
 	}
 
@@ -254,15 +615,15 @@ func getLastNAcceptedOpportunitiesForVolunteer(c *gin.Context, db *gorm.DB) {
 	if err := db.Table("applications").
 		Select("opportunities.*").
 		Joins("join opportunities on applications.opportunity_id = opportunities.id").
-		Where("applications.volunteer_id = ? AND applications.status = ? AND opportunities.end_date < ?", volunteerID, "Accepted", currentDate).
+		Where("applications.volunteer_id = ? AND applications.status IN ? AND opportunities.end_date < ?", volunteerID, models.ApprovedApplicationStatuses, currentDate).
 		Order("opportunities.end_date desc").
 		Limit(n).
 		Scan(&opportunities).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, opportunities)
+	c.JSON(http.StatusOK, models.NewOpportunityResponses(opportunities))
 }
 
 // getApplicationsByVolunteerWithDetails godoc
@@ -272,12 +633,14 @@ func getLastNAcceptedOpportunitiesForVolunteer(c *gin.Context, db *gorm.DB) {
 // @Accept json
 // @Produce json
 // @Param volunteer_id path uint true "Volunteer ID"
-// @Success 200 {array} map[string]interface{}
+// @Success 200 {array} models.ApplicationWithOpportunityResponse
+// @Failure 403 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /applications/volunteer/{volunteer_id} [get]
 func getApplicationsByVolunteerWithDetails(c *gin.Context, db *gorm.DB) {
 	volunteerID := c.Param("volunteer_id")
 
-	var results []map[string]interface{}
+	results := []models.ApplicationWithOpportunityResponse{}
 
 	// Query with inner joins to get data from all three tables and filter by volunteer ID
 	if err := db.Table("applications").
@@ -296,7 +659,7 @@ func getApplicationsByVolunteerWithDetails(c *gin.Context, db *gorm.DB) {
 		Joins("INNER JOIN organizations ON opportunities.organization_mail = organizations.email").
 		Where("applications.volunteer_id = ?", volunteerID).
 		Find(&results).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -305,23 +668,35 @@ func getApplicationsByVolunteerWithDetails(c *gin.Context, db *gorm.DB) {
 
 // getApplicationsByOpportunityWithVolunteerDetails godoc
 // @Summary Get applications for an opportunity with volunteer details
-// @Description Retrieve all applications for a specific opportunity with detailed volunteer information
+// @Description Retrieve all applications for a specific opportunity with detailed volunteer information, newest first.
+// @Description With sort=rank the best candidates come first, and each application carries its match score and the score of each factor.
+// @Description Only the organization that posted the opportunity can list them.
 // @Tags applications
 // @Accept json
 // @Produce json
 // @Param opportunity_id path uint true "Opportunity ID"
-// @Success 200 {array} map[string]interface{}
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Param sort query string false "created_at (default) or rank"
+// @Success 200 {array} models.ApplicationWithVolunteerResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 500 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /applications/opportunity/{opportunity_id} [get]
 func getApplicationsByOpportunityWithVolunteerDetails(c *gin.Context, db *gorm.DB) {
 	opportunityID := c.Param("opportunity_id")
 	if opportunityID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "opportunity_id is required"})
+		middleware.RespondProblem(c, http.StatusBadRequest, "opportunity_id is required")
+		return
+	}
+
+	sortMode := c.DefaultQuery("sort", applicantSortCreatedAt)
+	if sortMode != applicantSortCreatedAt && sortMode != applicantSortRank {
+		middleware.RespondProblem(c, http.StatusBadRequest, "sort must be created_at or rank")
 		return
 	}
 
-	var results []map[string]interface{}
+	results := []models.ApplicationWithVolunteerResponse{}
 
 	// Join applications table with volunteers table
 	if err := db.Table("applications").
@@ -340,9 +715,21 @@ func getApplicationsByOpportunityWithVolunteerDetails(c *gin.Context, db *gorm.D
 		Where("applications.opportunity_id = ?", opportunityID).
 		Order("applications.created_at DESC").
 		Find(&results).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	if sortMode == applicantSortRank {
+		var opportunity models.Opportunity
+		if err := db.Where("id = ?", opportunityID).First(&opportunity).Error; err != nil {
+			middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+			return
+		}
+		if err := rankApplicants(db, opportunity, results); err != nil {
+			respondError(c, err)
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, results)
 }
@@ -10,34 +10,22 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/store"
 	"github.com/prathamrao021/HelperHub/models"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 func setupTestDBForApplication() *gorm.DB {
-	// Use the same PostgreSQL connection as in main.go but with a test database
-	dsn := "host=localhost user=postgres password=admin dbname=Helperhub_test port=5432 sslmode=prefer TimeZone=Asia/Shanghai"
-
-	// Configure gorm with minimal logging during tests
-	config := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-	}
-
-	// Open connection to PostgreSQL
-	db, err := gorm.Open(postgres.Open(dsn), config)
+	// Connect to the test database configured like the real one
+	db, err := openTestDB()
 	if err != nil {
 		panic("Failed to connect to test database: " + err.Error())
 	}
 
-	// Clean up existing tables
-	db.Exec("DROP TABLE IF EXISTS applications CASCADE")
-
-	// Auto migrate required models
-	db.AutoMigrate(&models.Application{})
+	// Start from an empty schema
+	resetTestDB(db)
 
 	// Create test volunteer if not exists
 	var volunteerCount int64
@@ -46,16 +34,16 @@ func setupTestDBForApplication() *gorm.DB {
 		// Create test volunteer
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
 		volunteer := models.Volunteer{
-			Email:            "test@volunteer.com",
-			Password:         string(hashedPassword),
-			Name:             "Test Volunteer",
-			Phone:            "1234567890",
-			Location:         "Test Location",
-			Bio_Data:         "Test Bio",
-			Category_List:    models.StringList{"Education", "Environment"},
-			Availabile_Hours: 10,
-			Created_At:       time.Now(),
-			Updated_At:       time.Now(),
+			Email:           "test@volunteer.com",
+			Password:        string(hashedPassword),
+			Name:            "Test Volunteer",
+			Phone:           "1234567890",
+			Location:        "Test Location",
+			Bio_Data:        "Test Bio",
+			Category_List:   models.StringList{"Education", "Environment"},
+			Available_Hours: 10,
+			Created_At:      time.Now(),
+			Updated_At:      time.Now(),
 		}
 		db.Create(&volunteer)
 	}
@@ -92,6 +80,8 @@ func setupTestDBForApplication() *gorm.DB {
 			Description:       "Test Description",
 			Location:          "Test Location",
 			Hours_Required:    10, // 48 hours from now
+			Start_Date:        models.CustomDate(time.Now()),
+			End_Date:          models.CustomDate(time.Now().AddDate(0, 0, 30)),
 			Created_At:        time.Now(),
 			Updated_At:        time.Now(),
 		}
@@ -171,6 +161,7 @@ func mockGetApplicationsByStatus(c *gin.Context, db *gorm.DB) {
 func setupRouterForApplication(db *gorm.DB) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	stores := store.NewGorm(db)
 
 	// Register routes with injected database
 	r.POST("/applications", func(c *gin.Context) {
@@ -190,12 +181,15 @@ func setupRouterForApplication(db *gorm.DB) *gin.Engine {
 		} else if status != "" {
 			mockGetApplicationsByStatus(c, db)
 		} else {
-			getAllApplications(c, db)
+			getAllApplications(c, stores.Applications)
 		}
 	})
 
 	r.GET("/applications/:id", func(c *gin.Context) {
-		getApplicationByID(c, db)
+		getApplicationByID(c, stores.Applications)
+	})
+	r.GET("/applications/:id/history", func(c *gin.Context) {
+		getApplicationStatusHistory(c, db)
 	})
 	r.PUT("/applications/:id", func(c *gin.Context) {
 		updateApplication(c, db)
@@ -217,7 +211,7 @@ func createTestApplication(db *gorm.DB) models.Application {
 	application := models.Application{
 		Volunteer_ID:   volunteer.ID,
 		Opportunity_ID: opportunity.ID,
-		Status:         "pending",
+		Status:         models.ApplicationPending,
 		Cover_Letter:   "Test cover letter",
 		Created_At:     time.Now(),
 		Updated_At:     time.Now(),
@@ -254,7 +248,7 @@ func TestCreateApplication(t *testing.T) {
 	application := models.Application{
 		Volunteer_ID:   volunteer.ID,
 		Opportunity_ID: opportunity.ID,
-		Status:         "pending",
+		Status:         models.ApplicationPending,
 		Cover_Letter:   "I am very interested in this opportunity",
 	}
 
@@ -293,9 +287,27 @@ func TestGetAllApplications(t *testing.T) {
 	router := setupRouterForApplication(db)
 	defer cleanupTestApplications(db)
 
-	// Create multiple test applications
-	createTestApplication(db)
-	createTestApplication(db)
+	// Create multiple test applications, each volunteer can only apply to an opportunity once
+	first := createTestApplication(db)
+	opportunity := models.Opportunity{
+		Organization_mail: "test@org.com",
+		Category:          "Education",
+		Title:             "Second Test Opportunity",
+		Description:       "Test Description",
+		Location:          "Test Location",
+		Hours_Required:    5,
+		Created_At:        time.Now(),
+		Updated_At:        time.Now(),
+	}
+	db.Create(&opportunity)
+	db.Create(&models.Application{
+		Volunteer_ID:   first.Volunteer_ID,
+		Opportunity_ID: opportunity.ID,
+		Status:         models.ApplicationPending,
+		Cover_Letter:   "Second cover letter",
+		Created_At:     time.Now(),
+		Updated_At:     time.Now(),
+	})
 
 	// Create request
 	req, _ := http.NewRequest("GET", "/applications", nil)
@@ -311,10 +323,11 @@ func TestGetAllApplications(t *testing.T) {
 
 	// Parse response
 	var response []models.Application
-	json.Unmarshal(w.Body.Bytes(), &response)
+	page := decodePage(t, w, &response)
 
 	// Verify we got all applications
 	assert.GreaterOrEqual(t, len(response), 2)
+	assert.Equal(t, int64(len(response)), page.Total)
 }
 
 func TestGetApplicationByID(t *testing.T) {
@@ -452,11 +465,11 @@ func TestGetApplicationsByStatus(t *testing.T) {
 
 	// Manually verify the application exists in the database with the correct status
 	var count int64
-	db.Model(&models.Application{}).Where("status = ?", "pending").Count(&count)
+	db.Model(&models.Application{}).Where("status = ?", models.ApplicationPending).Count(&count)
 	assert.GreaterOrEqual(t, count, int64(1), "Database should contain at least one application with 'pending' status")
 
 	// Create request for applications with pending status
-	req, _ := http.NewRequest("GET", "/applications?status=pending", nil)
+	req, _ := http.NewRequest("GET", "/applications?status=Pending", nil)
 	w := httptest.NewRecorder()
 
 	// Execute the request
@@ -491,7 +504,7 @@ func TestGetApplicationsByStatus(t *testing.T) {
 
 		// Check if the applications have the requested status
 		for _, a := range response {
-			assert.Equal(t, "pending", a.Status, "All returned applications should have 'pending' status")
+			assert.Equal(t, models.ApplicationPending, a.Status, "All returned applications should have 'Pending' status")
 		}
 	}
 }
@@ -506,7 +519,7 @@ func TestUpdateApplication(t *testing.T) {
 
 	// Updated application data
 	updatedApp := app
-	updatedApp.Status = "accepted"
+	updatedApp.Status = models.ApplicationAccepted
 	updatedApp.Cover_Letter = "Updated cover letter"
 
 	// Convert to JSON
@@ -531,7 +544,7 @@ func TestUpdateApplication(t *testing.T) {
 
 	// Verify response fields
 	assert.Equal(t, app.ID, response.ID)
-	assert.Equal(t, "accepted", response.Status)
+	assert.Equal(t, models.ApplicationAccepted, response.Status)
 	assert.Equal(t, "Updated cover letter", response.Cover_Letter)
 
 	// Verify update time is later than creation time
@@ -564,6 +577,23 @@ func TestDeleteApplication(t *testing.T) {
 	assert.Equal(t, int64(0), count)
 }
 
+func TestDeleteDecidedApplicationRefused(t *testing.T) {
+	db := setupTestDBForApplication()
+	router := setupRouterForApplication(db)
+	defer cleanupTestApplications(db)
+
+	// A rejected volunteer cannot delete the application to apply again
+	app := createTestApplication(db)
+	db.Model(&app).Update("status", models.ApplicationRejected)
+
+	w := sendJSON(router, "DELETE", fmt.Sprintf("/applications/%d", app.ID), nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var count int64
+	db.Model(&models.Application{}).Where("id = ?", app.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
 func TestGetNonExistentApplication(t *testing.T) {
 	db := setupTestDBForApplication()
 	router := setupRouterForApplication(db)
@@ -878,5 +908,177 @@ func TestGetApplicationsByVolunteerWithDetailsDatabaseError(t *testing.T) {
 	// Verify response contains an error message
 	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Contains(t, response, "error")
-}
\ No newline at end of file
+	assert.Equal(t, "internal_error", response["code"])
+}
+func TestApplicationStatusLifecycle(t *testing.T) {
+	db := setupTestDBForApplication()
+	router := setupRouterForApplication(db)
+	defer cleanupTestApplications(db)
+
+	app := createTestApplication(db)
+	path := fmt.Sprintf("/applications/%d", app.ID)
+
+	// Pending applications cannot be completed directly
+	w := sendJSON(router, "PUT", path, map[string]interface{}{"status": models.ApplicationCompleted})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = sendJSON(router, "PUT", path, map[string]interface{}{"status": models.ApplicationAccepted})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Accepted applications cannot go back to pending
+	w = sendJSON(router, "PUT", path, map[string]interface{}{"status": models.ApplicationPending})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Statuses are matched case-insensitively
+	w = sendJSON(router, "PUT", path, map[string]interface{}{"status": "completed"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ApplicationResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, models.ApplicationCompleted, response.Status)
+
+	// Completed is final
+	w = sendJSON(router, "PUT", path, map[string]interface{}{"status": models.ApplicationNoShow})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Only the successful transitions are recorded
+	w = sendJSON(router, "GET", path+"/history", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var history []models.ApplicationStatusHistoryResponse
+	json.Unmarshal(w.Body.Bytes(), &history)
+	assert.Len(t, history, 2)
+	if len(history) == 2 {
+		assert.Equal(t, models.ApplicationPending, history[0].From_Status)
+		assert.Equal(t, models.ApplicationAccepted, history[0].To_Status)
+		assert.Equal(t, models.ApplicationAccepted, history[1].From_Status)
+		assert.Equal(t, models.ApplicationCompleted, history[1].To_Status)
+	}
+}
+
+func TestUpdateApplicationInvalidStatus(t *testing.T) {
+	db := setupTestDBForApplication()
+	router := setupRouterForApplication(db)
+	defer cleanupTestApplications(db)
+
+	app := createTestApplication(db)
+
+	w := sendJSON(router, "PUT", fmt.Sprintf("/applications/%d", app.ID), map[string]interface{}{"status": "Approved"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestCreateApplicationRecordsHistory(t *testing.T) {
+	db := setupTestDBForApplication()
+	router := setupRouterForApplication(db)
+	defer cleanupTestApplications(db)
+
+	var volunteer models.Volunteer
+	var opportunity models.Opportunity
+	db.First(&volunteer)
+	db.First(&opportunity)
+
+	// New applications cannot skip the review
+	w := sendJSON(router, "POST", "/applications", models.ApplicationCreateRequest{
+		Volunteer_ID:   volunteer.ID,
+		Opportunity_ID: opportunity.ID,
+		Status:         models.ApplicationAccepted,
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// Without a status they start out pending
+	w = sendJSON(router, "POST", "/applications", models.ApplicationCreateRequest{
+		Volunteer_ID:   volunteer.ID,
+		Opportunity_ID: opportunity.ID,
+		Cover_Letter:   "No status given",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ApplicationResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, models.ApplicationPending, response.Status)
+
+	var history []models.ApplicationStatusHistory
+	db.Where("application_id = ?", response.ID).Find(&history)
+	assert.Len(t, history, 1)
+	if len(history) == 1 {
+		assert.Equal(t, "", history[0].From_Status)
+		assert.Equal(t, models.ApplicationPending, history[0].To_Status)
+	}
+}
+
+func TestCreateApplicationValidation(t *testing.T) {
+	db := setupTestDBForApplication()
+	router := setupRouterForApplication(db)
+	defer cleanupTestApplications(db)
+
+	var volunteer models.Volunteer
+	var opportunity models.Opportunity
+	db.First(&volunteer)
+	db.Where("title = ?", "Test Opportunity").First(&opportunity)
+
+	// Both IDs are required
+	w := sendJSON(router, "POST", "/applications", models.ApplicationCreateRequest{Opportunity_ID: opportunity.ID})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// Unknown volunteers and opportunities are rejected
+	w = sendJSON(router, "POST", "/applications", models.ApplicationCreateRequest{Volunteer_ID: 99999, Opportunity_ID: opportunity.ID})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = sendJSON(router, "POST", "/applications", models.ApplicationCreateRequest{Volunteer_ID: volunteer.ID, Opportunity_ID: 99999})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Opportunities that have ended no longer take applications
+	ended := models.Opportunity{
+		Organization_mail: "test@org.com",
+		Category:          "Education",
+		Title:             "Ended Opportunity",
+		Description:       "Test Description",
+		Location:          "Test Location",
+		Hours_Required:    5,
+		Start_Date:        models.CustomDate(time.Now().AddDate(0, 0, -10)),
+		End_Date:          models.CustomDate(time.Now().AddDate(0, 0, -1)),
+		Created_At:        time.Now(),
+		Updated_At:        time.Now(),
+	}
+	db.Create(&ended)
+	w = sendJSON(router, "POST", "/applications", models.ApplicationCreateRequest{Volunteer_ID: volunteer.ID, Opportunity_ID: ended.ID})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// A volunteer can only apply once
+	w = sendJSON(router, "POST", "/applications", models.ApplicationCreateRequest{Volunteer_ID: volunteer.ID, Opportunity_ID: opportunity.ID})
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = sendJSON(router, "POST", "/applications", models.ApplicationCreateRequest{Volunteer_ID: volunteer.ID, Opportunity_ID: opportunity.ID})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var count int64
+	db.Model(&models.Application{}).Where("volunteer_id = ? AND opportunity_id = ?", volunteer.ID, opportunity.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestApplicationUniqueConstraint(t *testing.T) {
+	db := setupTestDBForApplication()
+	defer cleanupTestApplications(db)
+
+	app := createTestApplication(db)
+
+	// The database refuses duplicates even when the handler is bypassed
+	duplicate := models.Application{
+		Volunteer_ID:   app.Volunteer_ID,
+		Opportunity_ID: app.Opportunity_ID,
+		Status:         models.ApplicationPending,
+		Cover_Letter:   "Duplicate",
+		Created_At:     time.Now(),
+		Updated_At:     time.Now(),
+	}
+	assert.Error(t, db.Create(&duplicate).Error)
+
+	// And so it does for applications that point nowhere
+	orphan := models.Application{
+		Volunteer_ID:   99999,
+		Opportunity_ID: app.Opportunity_ID,
+		Status:         models.ApplicationPending,
+		Cover_Letter:   "Orphan",
+		Created_At:     time.Now(),
+		Updated_At:     time.Now(),
+	}
+	assert.Error(t, db.Create(&orphan).Error)
+}
@@ -0,0 +1,540 @@
+package routes
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"github.com/prathamrao021/HelperHub/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Attendance. At the opportunity, the organization issues a short-lived code (shown as digits or
+// as a QR code) and accepted volunteers check in and out with it. Checking out records the
+// visit as verified hours and completes the application. When the organization closes
+// attendance, accepted volunteers who never checked in are marked NoShow.
+
+var (
+	errInvalidAttendanceCode = errors.New("Invalid or expired attendance code")
+	errNotAccepted           = errors.New("No accepted application for this opportunity")
+	errAlreadyCheckedIn      = errors.New("Volunteer is already checked in")
+	errNotCheckedIn          = errors.New("Volunteer is not checked in")
+)
+
+// generateAttendanceCode returns a random six digit code
+func generateAttendanceCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// attendanceQRPayload is the text encoded in the QR code of an attendance code
+func attendanceQRPayload(code models.AttendanceCode) string {
+	payload := fmt.Sprintf("helperhub://attendance?opportunity_id=%d", code.Opportunity_ID)
+	if code.Shift_ID != nil {
+		payload += fmt.Sprintf("&shift_id=%d", *code.Shift_ID)
+	}
+	return payload + "&code=" + code.Code
+}
+
+// resolveAttendanceShift checks that a shift belongs to the opportunity, and that one is given
+// when the opportunity has shifts
+func resolveAttendanceShift(db *gorm.DB, opportunity models.Opportunity, shiftID *uint) error {
+	if shiftID == nil {
+		var shifts int64
+		if err := db.Model(&models.Shift{}).Where("opportunity_id = ?", opportunity.ID).Count(&shifts).Error; err != nil {
+			return err
+		}
+		if shifts > 0 {
+			return errors.New("Opportunity has shifts, shift_id is required")
+		}
+		return nil
+	}
+
+	var shift models.Shift
+	if err := db.Where("id = ? AND opportunity_id = ?", *shiftID, opportunity.ID).First(&shift).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errShiftNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// whereShift narrows a query on a table with a shift_id column to the given shift, or to rows
+// without a shift
+func whereShift(query *gorm.DB, shiftID *uint) *gorm.DB {
+	if shiftID == nil {
+		return query.Where("shift_id IS NULL")
+	}
+	return query.Where("shift_id = ?", *shiftID)
+}
+
+// issueAttendanceCode godoc
+// @Summary Issue an attendance code
+// @Description Issue a new six digit attendance code for an opportunity, or for one of its shifts, replacing the previous one. Codes expire after five minutes.
+// @Tags attendance
+// @Accept json
+// @Produce json
+// @Param opportunity_id path uint true "Opportunity ID"
+// @Param request body models.AttendanceCodeRequest false "Shift"
+// @Success 200 {object} models.AttendanceCodeResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /opportunities/{opportunity_id}/attendance/codes [post]
+func issueAttendanceCode(c *gin.Context, db *gorm.DB) {
+	var opportunity models.Opportunity
+	if err := db.Where("id = ?", c.Param("opportunity_id")).First(&opportunity).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		return
+	}
+
+	var request models.AttendanceCodeRequest
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &request) {
+			return
+		}
+	}
+
+	if err := resolveAttendanceShift(db, opportunity, request.Shift_ID); err != nil {
+		if errors.Is(err, errShiftNotFound) {
+			middleware.RespondProblem(c, http.StatusNotFound, err.Error())
+		} else {
+			middleware.RespondProblem(c, http.StatusUnprocessableEntity, err.Error())
+		}
+		return
+	}
+
+	value, err := generateAttendanceCode()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	now := time.Now()
+	code := models.AttendanceCode{
+		Opportunity_ID: opportunity.ID,
+		Shift_ID:       request.Shift_ID,
+		Code:           value,
+		Expires_At:     now.Add(models.AttendanceCodeTTL),
+		Created_At:     now,
+	}
+	if principal, _ := middleware.CurrentPrincipal(c); principal != nil {
+		code.Issuer_ID = principal.ID
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		// The new code replaces the ones issued before it
+		if err := whereShift(tx.Model(&models.AttendanceCode{}), code.Shift_ID).
+			Where("opportunity_id = ? AND expires_at > ?", opportunity.ID, now).
+			Update("expires_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&code).Error
+	}); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AttendanceCodeResponse{
+		Opportunity_ID: code.Opportunity_ID,
+		Shift_ID:       code.Shift_ID,
+		Code:           code.Code,
+		QR_Payload:     attendanceQRPayload(code),
+		Expires_At:     code.Expires_At,
+	})
+}
+
+// bindAttendanceCheck reads a check-in or check-out request and returns the attendance code it
+// uses and the volunteer's accepted application it applies to. It writes the error response
+// itself and reports false when the request cannot go ahead.
+func bindAttendanceCheck(c *gin.Context, db *gorm.DB) (models.AttendanceCode, models.Application, bool) {
+	var code models.AttendanceCode
+	var application models.Application
+
+	var request models.AttendanceCheckRequest
+	if !bindJSON(c, &request) {
+		return code, application, false
+	}
+
+	// Volunteers can only check themselves in and out
+	if principal, _ := middleware.CurrentPrincipal(c); principal != nil && principal.Role == auth.RoleVolunteer {
+		if request.Volunteer_ID == 0 {
+			request.Volunteer_ID = principal.ID
+		}
+		if request.Volunteer_ID != principal.ID {
+			middleware.AbortForbidden(c)
+			return code, application, false
+		}
+	}
+	if request.Volunteer_ID == 0 {
+		middleware.RespondProblem(c, http.StatusUnprocessableEntity, "volunteer_id is required")
+		return code, application, false
+	}
+
+	// Only volunteers the opportunity accepted can use its codes, so nobody else can use up the
+	// guesses. Completed applications were already checked out, so a visit is only recorded once.
+	var applications []models.Application
+	if err := db.Where("volunteer_id = ? AND opportunity_id = ? AND status = ?", request.Volunteer_ID, request.Opportunity_ID, models.ApplicationAccepted).
+		Find(&applications).Error; err != nil {
+		respondError(c, err)
+		return code, application, false
+	}
+	if len(applications) == 0 {
+		middleware.RespondProblem(c, http.StatusConflict, errNotAccepted.Error())
+		return code, application, false
+	}
+
+	// A code stops working for a volunteer who sent too many wrong ones while it was current
+	exhausted := db.Model(&models.AttendanceCodeMiss{}).
+		Select("attendance_code_id").
+		Where("volunteer_id = ? AND failed_attempts >= ?", request.Volunteer_ID, models.MaxAttendanceCodeAttempts)
+	if err := db.Where("opportunity_id = ? AND code = ? AND expires_at > ?", request.Opportunity_ID, request.Code, time.Now()).
+		Where("id NOT IN (?)", exhausted).
+		Order("id DESC").
+		First(&code).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, err)
+			return code, application, false
+		}
+		if err := recordAttendanceMiss(db, request.Opportunity_ID, request.Volunteer_ID); err != nil {
+			respondError(c, err)
+			return code, application, false
+		}
+		middleware.RespondProblem(c, http.StatusUnprocessableEntity, errInvalidAttendanceCode.Error())
+		return code, application, false
+	}
+
+	// A shift's code is only for the volunteers accepted for that shift
+	for _, accepted := range applications {
+		if code.Shift_ID == nil || (accepted.Shift_ID != nil && *accepted.Shift_ID == *code.Shift_ID) {
+			return code, accepted, true
+		}
+	}
+	middleware.RespondProblem(c, http.StatusConflict, errNotAccepted.Error())
+	return code, application, false
+}
+
+// recordAttendanceMiss counts a wrong code from a volunteer against each current code of an
+// opportunity
+func recordAttendanceMiss(db *gorm.DB, opportunityID uint, volunteerID uint) error {
+	now := time.Now()
+	var codeIDs []uint
+	if err := db.Model(&models.AttendanceCode{}).
+		Where("opportunity_id = ? AND expires_at > ?", opportunityID, now).
+		Pluck("id", &codeIDs).Error; err != nil {
+		return err
+	}
+	if len(codeIDs) == 0 {
+		return nil
+	}
+
+	misses := make([]models.AttendanceCodeMiss, len(codeIDs))
+	for i, codeID := range codeIDs {
+		misses[i] = models.AttendanceCodeMiss{
+			Attendance_Code_ID: codeID,
+			Volunteer_ID:       volunteerID,
+			Failed_Attempts:    1,
+			Created_At:         now,
+			Updated_At:         now,
+		}
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "attendance_code_id"}, {Name: "volunteer_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failed_attempts": gorm.Expr("attendance_code_misses.failed_attempts + 1"),
+			"updated_at":      now,
+		}),
+	}).Create(&misses).Error
+}
+
+// checkIn godoc
+// @Summary Check in to an opportunity
+// @Description Check in with the attendance code shown by the organization. Only volunteers with an Accepted application can check in, so a
+// @Description Completed application cannot check in again. After five wrong codes the current code stops working for the volunteer, who has to wait for a new one to be issued.
+// @Tags attendance
+// @Accept json
+// @Produce json
+// @Param request body models.AttendanceCheckRequest true "Attendance code"
+// @Success 200 {object} models.AttendanceRecordResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /attendance/check-in [post]
+func checkIn(c *gin.Context, db *gorm.DB) {
+	_, application, ok := bindAttendanceCheck(c, db)
+	if !ok {
+		return
+	}
+
+	record := models.AttendanceRecord{
+		Application_ID: application.ID,
+		Check_In_At:    time.Now(),
+		Created_At:     time.Now(),
+		Updated_At:     time.Now(),
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Holding the opportunity lock keeps the same volunteer from checking in twice at once
+		if _, err := lockOpportunity(tx, application.Opportunity_ID); err != nil {
+			return err
+		}
+
+		var open int64
+		if err := tx.Model(&models.AttendanceRecord{}).
+			Where("application_id = ? AND check_out_at IS NULL", application.ID).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return errAlreadyCheckedIn
+		}
+		return tx.Create(&record).Error
+	})
+	if errors.Is(err, errAlreadyCheckedIn) {
+		middleware.RespondProblem(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewAttendanceRecordResponse(record))
+}
+
+// checkOut godoc
+// @Summary Check out of an opportunity
+// @Description Check out with the attendance code shown by the organization. The time since check-in is recorded as approved hours and the application is marked Completed.
+// @Tags attendance
+// @Accept json
+// @Produce json
+// @Param request body models.AttendanceCheckRequest true "Attendance code"
+// @Success 200 {object} models.AttendanceRecordResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /attendance/check-out [post]
+func checkOut(c *gin.Context, db *gorm.DB) {
+	code, application, ok := bindAttendanceCheck(c, db)
+	if !ok {
+		return
+	}
+
+	var record models.AttendanceRecord
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockOpportunity(tx, application.Opportunity_ID); err != nil {
+			return err
+		}
+
+		if err := tx.Where("application_id = ? AND check_out_at IS NULL", application.ID).
+			Order("check_in_at DESC").
+			First(&record).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errNotCheckedIn
+			}
+			return err
+		}
+
+		// The organization vouched for the visit by showing the code, so the hours count as verified
+		if err := closeAttendanceRecord(tx, &record, time.Now(), models.TimeEntryApproved, code.Issuer_ID); err != nil {
+			return err
+		}
+		return setAttendanceOutcome(tx, application, models.ApplicationCompleted)
+	})
+	if errors.Is(err, errNotCheckedIn) {
+		middleware.RespondProblem(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewAttendanceRecordResponse(record))
+}
+
+// closeAttendanceRecord checks a visit out at the given time and records its hours as a time
+// entry with the given review status
+func closeAttendanceRecord(tx *gorm.DB, record *models.AttendanceRecord, at time.Time, status string, reviewerID uint) error {
+	hours := math.Min(math.Round(at.Sub(record.Check_In_At).Hours()*100)/100, models.MaxHoursPerEntry)
+	if hours > 0 {
+		entry := models.TimeEntry{
+			Application_ID: record.Application_ID,
+			Work_Date:      models.CustomDate(record.Check_In_At),
+			Hours:          hours,
+			Note:           "Recorded by attendance check-in",
+			Status:         status,
+			Created_At:     at,
+			Updated_At:     at,
+		}
+		if status == models.TimeEntryApproved {
+			entry.Reviewer_ID = reviewerID
+			entry.Reviewed_At = &at
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		record.Time_Entry_ID = &entry.ID
+	}
+
+	record.Check_Out_At = &at
+	record.Updated_At = at
+	return tx.Model(record).Updates(map[string]interface{}{
+		"check_out_at":  record.Check_Out_At,
+		"time_entry_id": record.Time_Entry_ID,
+		"updated_at":    record.Updated_At,
+	}).Error
+}
+
+// setAttendanceOutcome moves an accepted application to Completed or NoShow. Applications that
+// already left Accepted are left alone.
+func setAttendanceOutcome(tx *gorm.DB, application models.Application, status string) error {
+	result := tx.Model(&models.Application{}).
+		Where("id = ? AND status = ?", application.ID, models.ApplicationAccepted).
+		Updates(map[string]interface{}{"status": status, "updated_at": time.Now()})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	return recordApplicationStatus(tx, application.ID, models.ApplicationAccepted, status, systemActor)
+}
+
+// closeAttendance godoc
+// @Summary Close attendance
+// @Description Close attendance for an opportunity or one of its shifts. Accepted volunteers who never checked in are marked NoShow; volunteers still checked in are checked out, with their hours left Pending for review, and marked Completed. Outstanding attendance codes expire.
+// @Tags attendance
+// @Accept json
+// @Produce json
+// @Param opportunity_id path uint true "Opportunity ID"
+// @Param request body models.AttendanceCloseRequest false "Shift"
+// @Success 200 {object} models.AttendanceCloseResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /opportunities/{opportunity_id}/attendance/close [post]
+func closeAttendance(c *gin.Context, db *gorm.DB) {
+	var opportunity models.Opportunity
+	if err := db.Where("id = ?", c.Param("opportunity_id")).First(&opportunity).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		return
+	}
+
+	var request models.AttendanceCloseRequest
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &request) {
+			return
+		}
+	}
+
+	if err := resolveAttendanceShift(db, opportunity, request.Shift_ID); err != nil {
+		if errors.Is(err, errShiftNotFound) {
+			middleware.RespondProblem(c, http.StatusNotFound, err.Error())
+		} else {
+			middleware.RespondProblem(c, http.StatusUnprocessableEntity, err.Error())
+		}
+		return
+	}
+
+	var response models.AttendanceCloseResponse
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockOpportunity(tx, opportunity.ID); err != nil {
+			return err
+		}
+
+		var accepted []models.Application
+		if err := whereShift(tx.Where("opportunity_id = ? AND status = ?", opportunity.ID, models.ApplicationAccepted), request.Shift_ID).
+			Find(&accepted).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, application := range accepted {
+			var records []models.AttendanceRecord
+			if err := tx.Where("application_id = ?", application.ID).Find(&records).Error; err != nil {
+				return err
+			}
+			if len(records) == 0 {
+				if err := setAttendanceOutcome(tx, application, models.ApplicationNoShow); err != nil {
+					return err
+				}
+				response.No_Show++
+				continue
+			}
+
+			// Nobody saw the volunteer leave, so their hours still need a review
+			for i := range records {
+				if records[i].Check_Out_At == nil {
+					if err := closeAttendanceRecord(tx, &records[i], now, models.TimeEntryPending, 0); err != nil {
+						return err
+					}
+				}
+			}
+			if err := setAttendanceOutcome(tx, application, models.ApplicationCompleted); err != nil {
+				return err
+			}
+			response.Completed++
+		}
+
+		return whereShift(tx.Model(&models.AttendanceCode{}), request.Shift_ID).
+			Where("opportunity_id = ? AND expires_at > ?", opportunity.ID, now).
+			Update("expires_at", now).Error
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// getAttendance godoc
+// @Summary List attendance records
+// @Description Retrieve the check-ins and check-outs of every application to an opportunity, optionally for one shift
+// @Tags attendance
+// @Accept json
+// @Produce json
+// @Param opportunity_id path uint true "Opportunity ID"
+// @Param shift_id query uint false "Shift ID"
+// @Success 200 {array} models.AttendanceRecordResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /opportunities/{opportunity_id}/attendance [get]
+func getAttendance(c *gin.Context, db *gorm.DB) {
+	var opportunity models.Opportunity
+	if err := db.Where("id = ?", c.Param("opportunity_id")).First(&opportunity).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		return
+	}
+
+	query := db.Table("attendance_records").
+		Select("attendance_records.*").
+		Joins("INNER JOIN applications ON attendance_records.application_id = applications.id").
+		Where("applications.opportunity_id = ?", opportunity.ID)
+	if shiftID := c.Query("shift_id"); shiftID != "" {
+		query = query.Where("applications.shift_id = ?", shiftID)
+	}
+
+	var records []models.AttendanceRecord
+	if err := query.Order("attendance_records.check_in_at ASC").Find(&records).Error; err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewAttendanceRecordResponses(records))
+}
@@ -0,0 +1,204 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupRouterForAttendance(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+
+	r.POST("/applications", func(c *gin.Context) {
+		createApplication(c, db)
+	})
+	r.PUT("/applications/:id", func(c *gin.Context) {
+		updateApplication(c, db)
+	})
+	r.POST("/opportunities/:opportunity_id/attendance/codes", func(c *gin.Context) {
+		issueAttendanceCode(c, db)
+	})
+	r.POST("/opportunities/:opportunity_id/attendance/close", func(c *gin.Context) {
+		closeAttendance(c, db)
+	})
+	r.GET("/opportunities/:opportunity_id/attendance", func(c *gin.Context) {
+		getAttendance(c, db)
+	})
+	r.POST("/attendance/check-in", func(c *gin.Context) {
+		checkIn(c, db)
+	})
+	r.POST("/attendance/check-out", func(c *gin.Context) {
+		checkOut(c, db)
+	})
+
+	return r
+}
+
+func issueTestAttendanceCode(t *testing.T, router *gin.Engine, opportunityID uint) models.AttendanceCodeResponse {
+	w := sendJSON(router, "POST", fmt.Sprintf("/opportunities/%d/attendance/codes", opportunityID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.AttendanceCodeResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response
+}
+
+func sendAttendanceCheck(router *gin.Engine, action string, volunteerID uint, opportunityID uint, code string) *models.AttendanceRecordResponse {
+	w := sendJSON(router, "POST", "/attendance/"+action, models.AttendanceCheckRequest{
+		Volunteer_ID:   volunteerID,
+		Opportunity_ID: opportunityID,
+		Code:           code,
+	})
+	if w.Code != http.StatusOK {
+		return nil
+	}
+
+	var response models.AttendanceRecordResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return &response
+}
+
+func TestAttendanceCheckInAndOut(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForAttendance(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createStartedTestOpportunity(db)
+	volunteers := createCapacityTestVolunteers(db, 2)
+	application := applyForOpportunity(t, router, volunteers[0].ID, opp.ID)
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, application.ID, models.ApplicationAccepted))
+
+	code := issueTestAttendanceCode(t, router, opp.ID)
+	assert.Len(t, code.Code, 6)
+	assert.Contains(t, code.QR_Payload, "code="+code.Code)
+	assert.True(t, code.Expires_At.After(time.Now()))
+
+	// A wrong code is rejected, and so is a volunteer who was not accepted
+	w := sendJSON(router, "POST", "/attendance/check-in", models.AttendanceCheckRequest{Volunteer_ID: volunteers[0].ID, Opportunity_ID: opp.ID, Code: "not-a-code"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = sendJSON(router, "POST", "/attendance/check-in", models.AttendanceCheckRequest{Volunteer_ID: volunteers[1].ID, Opportunity_ID: opp.ID, Code: code.Code})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	record := sendAttendanceCheck(router, "check-in", volunteers[0].ID, opp.ID, code.Code)
+	assert.NotNil(t, record)
+	w = sendJSON(router, "POST", "/attendance/check-in", models.AttendanceCheckRequest{Volunteer_ID: volunteers[0].ID, Opportunity_ID: opp.ID, Code: code.Code})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Pretend the volunteer arrived two hours ago
+	db.Model(&models.AttendanceRecord{}).Where("id = ?", record.ID).Update("check_in_at", time.Now().Add(-2*time.Hour))
+
+	record = sendAttendanceCheck(router, "check-out", volunteers[0].ID, opp.ID, code.Code)
+	assert.NotNil(t, record)
+	assert.NotNil(t, record.Check_Out_At)
+	assert.NotNil(t, record.Time_Entry_ID)
+
+	var entry models.TimeEntry
+	db.First(&entry, *record.Time_Entry_ID)
+	assert.Equal(t, models.TimeEntryApproved, entry.Status)
+	assert.InDelta(t, 2.0, entry.Hours, 0.02)
+
+	assert.Equal(t, models.ApplicationCompleted, applicationStatus(db, application.ID))
+
+	// The visit is only recorded once: a completed volunteer cannot check in or out again
+	w = sendJSON(router, "POST", "/attendance/check-out", models.AttendanceCheckRequest{Volunteer_ID: volunteers[0].ID, Opportunity_ID: opp.ID, Code: code.Code})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = sendJSON(router, "POST", "/attendance/check-in", models.AttendanceCheckRequest{Volunteer_ID: volunteers[0].ID, Opportunity_ID: opp.ID, Code: code.Code})
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAttendanceCodeLocksOutAfterWrongGuesses(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForAttendance(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createStartedTestOpportunity(db)
+	volunteers := createCapacityTestVolunteers(db, 3)
+	for _, volunteer := range volunteers[:2] {
+		application := applyForOpportunity(t, router, volunteer.ID, opp.ID)
+		assert.Equal(t, http.StatusOK, setApplicationStatus(router, application.ID, models.ApplicationAccepted))
+	}
+
+	code := issueTestAttendanceCode(t, router, opp.ID)
+
+	// A volunteer without an accepted application is turned away before the code is checked
+	for i := 0; i < models.MaxAttendanceCodeAttempts; i++ {
+		w := sendJSON(router, "POST", "/attendance/check-in", models.AttendanceCheckRequest{Volunteer_ID: volunteers[2].ID, Opportunity_ID: opp.ID, Code: "wrong"})
+		assert.Equal(t, http.StatusConflict, w.Code)
+	}
+	var misses int64
+	db.Model(&models.AttendanceCodeMiss{}).Count(&misses)
+	assert.Equal(t, int64(0), misses)
+
+	for i := 0; i < models.MaxAttendanceCodeAttempts; i++ {
+		w := sendJSON(router, "POST", "/attendance/check-in", models.AttendanceCheckRequest{Volunteer_ID: volunteers[0].ID, Opportunity_ID: opp.ID, Code: "wrong"})
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	}
+
+	// The code stops working for the volunteer who guessed, until the organization issues a new
+	// one, but still works for everyone else
+	assert.Nil(t, sendAttendanceCheck(router, "check-in", volunteers[0].ID, opp.ID, code.Code))
+	assert.NotNil(t, sendAttendanceCheck(router, "check-in", volunteers[1].ID, opp.ID, code.Code))
+	code = issueTestAttendanceCode(t, router, opp.ID)
+	assert.NotNil(t, sendAttendanceCheck(router, "check-in", volunteers[0].ID, opp.ID, code.Code))
+}
+
+func TestIssuingAttendanceCodeExpiresPreviousCode(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForAttendance(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createStartedTestOpportunity(db)
+	volunteers := createCapacityTestVolunteers(db, 1)
+	application := applyForOpportunity(t, router, volunteers[0].ID, opp.ID)
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, application.ID, models.ApplicationAccepted))
+
+	first := issueTestAttendanceCode(t, router, opp.ID)
+	second := issueTestAttendanceCode(t, router, opp.ID)
+	if first.Code != second.Code {
+		assert.Nil(t, sendAttendanceCheck(router, "check-in", volunteers[0].ID, opp.ID, first.Code))
+	}
+	assert.NotNil(t, sendAttendanceCheck(router, "check-in", volunteers[0].ID, opp.ID, second.Code))
+}
+
+func TestCloseAttendanceRecordsOutcomes(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForAttendance(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createStartedTestOpportunity(db)
+	volunteers := createCapacityTestVolunteers(db, 2)
+	attended := applyForOpportunity(t, router, volunteers[0].ID, opp.ID)
+	absent := applyForOpportunity(t, router, volunteers[1].ID, opp.ID)
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, attended.ID, models.ApplicationAccepted))
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, absent.ID, models.ApplicationAccepted))
+
+	code := issueTestAttendanceCode(t, router, opp.ID)
+	assert.NotNil(t, sendAttendanceCheck(router, "check-in", volunteers[0].ID, opp.ID, code.Code))
+
+	w := sendJSON(router, "POST", fmt.Sprintf("/opportunities/%d/attendance/close", opp.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.AttendanceCloseResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, int64(1), response.Completed)
+	assert.Equal(t, int64(1), response.No_Show)
+
+	assert.Equal(t, models.ApplicationCompleted, applicationStatus(db, attended.ID))
+	assert.Equal(t, models.ApplicationNoShow, applicationStatus(db, absent.ID))
+
+	// The volunteer still on site was checked out, and the code no longer works
+	w = sendJSON(router, "GET", fmt.Sprintf("/opportunities/%d/attendance", opp.ID), nil)
+	var records []models.AttendanceRecordResponse
+	json.Unmarshal(w.Body.Bytes(), &records)
+	assert.Len(t, records, 1)
+	assert.NotNil(t, records[0].Check_Out_At)
+	assert.Nil(t, sendAttendanceCheck(router, "check-in", volunteers[0].ID, opp.ID, code.Code))
+}
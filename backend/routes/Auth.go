@@ -0,0 +1,166 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/internal/metrics"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"github.com/prathamrao021/HelperHub/models"
+	"gorm.io/gorm"
+)
+
+// countLogins counts the attempts to log in with a role by the status of the response. Wrong
+// credentials and suspended accounts count as failures; malformed requests and server errors
+// are not counted.
+func countLogins(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		switch c.Writer.Status() {
+		case http.StatusOK:
+			metrics.Logins.WithLabelValues(role, metrics.LoginSuccess).Inc()
+		case http.StatusUnauthorized, http.StatusForbidden:
+			metrics.Logins.WithLabelValues(role, metrics.LoginFailure).Inc()
+		}
+	}
+}
+
+// issueSession creates a new access token and a persisted refresh token for the principal
+func issueSession(db *gorm.DB, tokens *auth.TokenManager, principal auth.Principal) (models.SessionResponse, error) {
+	accessToken, _, err := tokens.IssueAccessToken(principal)
+	if err != nil {
+		return models.SessionResponse{}, err
+	}
+
+	refreshToken, refreshHash, refreshExpiresAt, err := tokens.NewRefreshToken()
+	if err != nil {
+		return models.SessionResponse{}, err
+	}
+
+	record := models.RefreshToken{
+		Token_Hash: refreshHash,
+		Subject_ID: principal.ID,
+		Email:      principal.Email,
+		Role:       principal.Role,
+		Expires_At: refreshExpiresAt,
+		Created_At: time.Now(),
+	}
+	if err := db.Create(&record).Error; err != nil {
+		return models.SessionResponse{}, err
+	}
+
+	return models.SessionResponse{
+		Access_Token:  accessToken,
+		Refresh_Token: refreshToken,
+		Token_Type:    "Bearer",
+		Expires_In:    int(tokens.AccessTTL().Seconds()),
+	}, nil
+}
+
+// refreshSession godoc
+// @Summary Refresh an access token
+// @Description Exchange a valid refresh token for a new access token. The refresh token is rotated. The new access token carries the current email of the account, and the tokens of deleted accounts are refused.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param refresh body models.RefreshRequest true "Refresh token"
+// @Success 200 {object} models.SessionResponse
+// @Failure 401 {object} middleware.Problem
+// @Router /auth/refresh [post]
+func refreshSession(c *gin.Context, db *gorm.DB, tokens *auth.TokenManager) {
+	var request models.RefreshRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	var session models.SessionResponse
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var record models.RefreshToken
+		if err := tx.Where("token_hash = ? AND revoked_at IS NULL AND expires_at > ?", auth.HashRefreshToken(request.Refresh_Token), time.Now()).
+			First(&record).Error; err != nil {
+			return err
+		}
+
+		// Rotate: the presented refresh token can only be used once
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", record.ID).
+			Update("revoked_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		email, err := accountEmail(tx, record.Role, record.Subject_ID)
+		if err != nil {
+			return err
+		}
+		session, err = issueSession(tx, tokens, auth.Principal{ID: record.Subject_ID, Email: email, Role: record.Role})
+		return err
+	})
+	if err == gorm.ErrRecordNotFound {
+		middleware.RespondProblem(c, http.StatusUnauthorized, "Invalid or expired refresh token")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// accountEmail returns the current email of the account a refresh token was issued to, so that
+// a session picks up a change of email when it is refreshed. It returns gorm.ErrRecordNotFound
+// when the account has been deleted.
+func accountEmail(db *gorm.DB, role string, id uint) (string, error) {
+	var account interface{}
+	switch role {
+	case auth.RoleVolunteer:
+		account = &models.Volunteer{}
+	case auth.RoleOrganization:
+		account = &models.Organization{}
+	case auth.RoleAdmin:
+		account = &models.User{}
+	default:
+		return "", gorm.ErrRecordNotFound
+	}
+
+	var emails []string
+	if err := db.Model(account).Where("id = ?", id).Pluck("email", &emails).Error; err != nil {
+		return "", err
+	}
+	if len(emails) == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+	return emails[0], nil
+}
+
+// logoutSession godoc
+// @Summary Revoke a refresh token
+// @Description Revoke the given refresh token so it can no longer be used
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param refresh body models.RefreshRequest true "Refresh token"
+// @Success 200 {object} map[string]string
+// @Router /auth/logout [post]
+func logoutSession(c *gin.Context, db *gorm.DB) {
+	var request models.RefreshRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	if err := db.Model(&models.RefreshToken{}).
+		Where("token_hash = ? AND revoked_at IS NULL", auth.HashRefreshToken(request.Refresh_Token)).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
@@ -0,0 +1,177 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/internal/metrics"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newTestTokenManager() *auth.TokenManager {
+	return auth.NewTokenManager([]byte("test-secret"), 15*time.Minute, time.Hour)
+}
+
+func setupRouterForAuth(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	tokens := newTestTokenManager()
+
+	r.POST("/login/volunteer", func(c *gin.Context) {
+		loginVolunteer(c, db, tokens)
+	})
+	r.POST("/auth/refresh", func(c *gin.Context) {
+		refreshSession(c, db, tokens)
+	})
+	r.POST("/auth/logout", func(c *gin.Context) {
+		logoutSession(c, db)
+	})
+
+	return r
+}
+
+func loginTestVolunteer(t *testing.T, router *gin.Engine) map[string]interface{} {
+	jsonData, _ := json.Marshal(LoginRequest{
+		Email:    "test@volunteer.com",
+		Password: "testpassword",
+		Role:     "volunteer",
+	})
+
+	req, _ := http.NewRequest("POST", "/login/volunteer", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response
+}
+
+func postRefreshToken(router *gin.Engine, path string, refreshToken string) *httptest.ResponseRecorder {
+	jsonData, _ := json.Marshal(models.RefreshRequest{Refresh_Token: refreshToken})
+
+	req, _ := http.NewRequest("POST", path, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRefreshSession(t *testing.T) {
+	db := setupTestDBForVolunteer()
+	router := setupRouterForAuth(db)
+	defer cleanupTestVolunteers(db)
+
+	volunteer := createTestVolunteer(db)
+	session := loginTestVolunteer(t, router)
+	refreshToken, _ := session["refresh_token"].(string)
+
+	w := postRefreshToken(router, "/auth/refresh", refreshToken)
+	t.Logf("Response Status: %d", w.Code)
+	t.Logf("Response Body: %s", w.Body.String())
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err, "Response should be valid JSON")
+	assert.NotEmpty(t, response["access_token"])
+	assert.NotEqual(t, refreshToken, response["refresh_token"], "Refresh token should be rotated")
+
+	// The new access token identifies the same volunteer
+	principal, err := newTestTokenManager().ParseAccessToken(response["access_token"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, volunteer.ID, principal.ID)
+	assert.Equal(t, auth.RoleVolunteer, principal.Role)
+
+	// The old refresh token cannot be used again
+	w = postRefreshToken(router, "/auth/refresh", refreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRefreshSessionAfterAccountChange(t *testing.T) {
+	db := setupTestDBForVolunteer()
+	router := setupRouterForAuth(db)
+	defer cleanupTestVolunteers(db)
+
+	volunteer := createTestVolunteer(db)
+	session := loginTestVolunteer(t, router)
+	refreshToken, _ := session["refresh_token"].(string)
+
+	// A refreshed session carries the email the volunteer changed to
+	db.Model(&volunteer).Update("email", "renamed@volunteer.com")
+	w := postRefreshToken(router, "/auth/refresh", refreshToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	principal, err := newTestTokenManager().ParseAccessToken(response["access_token"].(string))
+	assert.NoError(t, err)
+	assert.Equal(t, "renamed@volunteer.com", principal.Email)
+
+	// The sessions of a deleted volunteer cannot be refreshed
+	db.Delete(&volunteer)
+	w = postRefreshToken(router, "/auth/refresh", response["refresh_token"].(string))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRefreshSessionInvalidToken(t *testing.T) {
+	db := setupTestDBForVolunteer()
+	router := setupRouterForAuth(db)
+	defer cleanupTestVolunteers(db)
+
+	w := postRefreshToken(router, "/auth/refresh", "not-a-refresh-token")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestLogoutSession(t *testing.T) {
+	db := setupTestDBForVolunteer()
+	router := setupRouterForAuth(db)
+	defer cleanupTestVolunteers(db)
+
+	createTestVolunteer(db)
+	session := loginTestVolunteer(t, router)
+	refreshToken, _ := session["refresh_token"].(string)
+
+	w := postRefreshToken(router, "/auth/logout", refreshToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// A revoked refresh token cannot be exchanged
+	w = postRefreshToken(router, "/auth/refresh", refreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestCountLogins(t *testing.T) {
+	db := setupTestDBForVolunteer()
+	defer cleanupTestVolunteers(db)
+	createTestVolunteer(db)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	tokens := newTestTokenManager()
+	router.POST("/login/volunteer", countLogins(auth.RoleVolunteer), func(c *gin.Context) {
+		loginVolunteer(c, db, tokens)
+	})
+
+	successes := testutil.ToFloat64(metrics.Logins.WithLabelValues(auth.RoleVolunteer, metrics.LoginSuccess))
+	failures := testutil.ToFloat64(metrics.Logins.WithLabelValues(auth.RoleVolunteer, metrics.LoginFailure))
+
+	loginTestVolunteer(t, router)
+	w := sendJSON(router, "POST", "/login/volunteer", LoginRequest{Email: "test@volunteer.com", Password: "wrongpassword", Role: "volunteer"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	// Malformed requests are not login attempts
+	w = sendJSON(router, "POST", "/login/volunteer", gin.H{"email": 7})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	assert.Equal(t, successes+1, testutil.ToFloat64(metrics.Logins.WithLabelValues(auth.RoleVolunteer, metrics.LoginSuccess)))
+	assert.Equal(t, failures+1, testutil.ToFloat64(metrics.Logins.WithLabelValues(auth.RoleVolunteer, metrics.LoginFailure)))
+}
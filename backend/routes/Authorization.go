@@ -0,0 +1,260 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"github.com/prathamrao021/HelperHub/models"
+	"gorm.io/gorm"
+)
+
+// Ownership checks for routes that act on a single record. They run after middleware.RequireAuth.
+// Admins pass every check. When the target record does not exist the request is passed on
+// so the handler can answer with its usual 404. When the record cannot be read the request is
+// refused with a 500, never passed on.
+
+// requireSelf only lets the principal act on the account identified by the given path
+// parameter, an email or an ID depending on the lookup. The account is matched on its ID, which
+// unlike the email in the token does not change when the account is updated.
+func requireSelf(role string, param string, lookup accountLookup) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, _ := middleware.CurrentPrincipal(c)
+		if middleware.IsAdmin(principal) {
+			c.Next()
+			return
+		}
+		if principal == nil || principal.Role != role {
+			middleware.AbortForbidden(c)
+			return
+		}
+
+		id, err := lookup(c.Request.Context(), c.Param(param))
+		if err != nil {
+			passMissing(c, err)
+			return
+		}
+		if id != principal.ID {
+			middleware.AbortForbidden(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// accountLookup returns the ID of the account identified by a path parameter
+type accountLookup func(ctx context.Context, param string) (uint, error)
+
+// accountByID is the lookup of routes that identify the account by its ID. IDs that are not
+// numbers match no principal.
+func accountByID(ctx context.Context, param string) (uint, error) {
+	id, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return 0, nil
+	}
+	return uint(id), nil
+}
+
+func volunteerAccount(volunteers store.VolunteerStore) accountLookup {
+	return func(ctx context.Context, email string) (uint, error) {
+		volunteer, err := volunteers.GetByEmail(ctx, normalizeEmail(email))
+		return volunteer.ID, err
+	}
+}
+
+func organizationAccount(organizations store.OrganizationStore) accountLookup {
+	return func(ctx context.Context, email string) (uint, error) {
+		organization, err := organizations.GetByEmail(ctx, normalizeEmail(email))
+		return organization.ID, err
+	}
+}
+
+// requireOpportunityOwner only lets the organization that posted the opportunity act on it
+func requireOpportunityOwner(opportunities store.OpportunityStore, organizations store.OrganizationStore, param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, _ := middleware.CurrentPrincipal(c)
+		if middleware.IsAdmin(principal) {
+			c.Next()
+			return
+		}
+
+		// IDs that are not numbers match no opportunity
+		id, ok := paramID(c, param)
+		if !ok {
+			c.Next()
+			return
+		}
+		opportunity, err := opportunities.Get(c.Request.Context(), id)
+		if err != nil {
+			passMissing(c, err)
+			return
+		}
+
+		email, err := organizationEmail(c.Request.Context(), organizations, principal)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if !ownsOpportunity(email, opportunity) {
+			middleware.AbortForbidden(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireApplicationAccess lets the applying volunteer and the organization that posted the
+// opportunity act on an application. With volunteerOnly set, the organization is refused.
+func requireApplicationAccess(applications store.ApplicationStore, opportunities store.OpportunityStore, organizations store.OrganizationStore, volunteerOnly bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, _ := middleware.CurrentPrincipal(c)
+		if middleware.IsAdmin(principal) {
+			c.Next()
+			return
+		}
+
+		id, ok := paramID(c, "id")
+		if !ok {
+			c.Next()
+			return
+		}
+		application, err := applications.Get(c.Request.Context(), id)
+		if err != nil {
+			passMissing(c, err)
+			return
+		}
+
+		if ownsApplication(principal, application) {
+			c.Next()
+			return
+		}
+
+		if !volunteerOnly {
+			owner, err := ownsApplicationOpportunity(c.Request.Context(), opportunities, organizations, principal, application)
+			if err != nil {
+				abortWithError(c, err)
+				return
+			}
+			if owner {
+				c.Next()
+				return
+			}
+		}
+
+		middleware.AbortForbidden(c)
+	}
+}
+
+// requireTimeEntryAccess lets the volunteer who logged a time entry (role volunteer) or the
+// organization that posted the opportunity (role organization) act on it
+func requireTimeEntryAccess(db *gorm.DB, applications store.ApplicationStore, opportunities store.OpportunityStore, organizations store.OrganizationStore, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := requestDB(c, db)
+		principal, _ := middleware.CurrentPrincipal(c)
+		if middleware.IsAdmin(principal) {
+			c.Next()
+			return
+		}
+
+		var entry models.TimeEntry
+		if err := db.Where("id = ?", c.Param("id")).First(&entry).Error; err != nil {
+			passMissing(c, err)
+			return
+		}
+
+		application, err := applications.Get(c.Request.Context(), entry.Application_ID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				middleware.AbortForbidden(c)
+			} else {
+				abortWithError(c, err)
+			}
+			return
+		}
+
+		switch role {
+		case auth.RoleVolunteer:
+			if ownsApplication(principal, application) {
+				c.Next()
+				return
+			}
+		case auth.RoleOrganization:
+			owner, err := ownsApplicationOpportunity(c.Request.Context(), opportunities, organizations, principal, application)
+			if err != nil {
+				abortWithError(c, err)
+				return
+			}
+			if owner {
+				c.Next()
+				return
+			}
+		}
+
+		middleware.AbortForbidden(c)
+	}
+}
+
+// ownsApplicationOpportunity reports whether the principal is the organization that posted the
+// opportunity of an application
+func ownsApplicationOpportunity(ctx context.Context, opportunities store.OpportunityStore, organizations store.OrganizationStore, principal *auth.Principal, application models.Application) (bool, error) {
+	if principal == nil || principal.Role != auth.RoleOrganization {
+		return false, nil
+	}
+	opportunity, err := opportunities.Get(ctx, application.Opportunity_ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	email, err := organizationEmail(ctx, organizations, principal)
+	if err != nil {
+		return false, err
+	}
+	return ownsOpportunity(email, opportunity), nil
+}
+
+// passMissing passes the request on when the record it acts on is missing, and refuses it
+// when the record could not be read
+func passMissing(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, store.ErrNotFound) {
+		c.Next()
+		return
+	}
+	abortWithError(c, err)
+}
+
+// abortWithError stops the request with the response for an error from the database
+func abortWithError(c *gin.Context, err error) {
+	c.Abort()
+	respondError(c, err)
+}
+
+// organizationEmail returns the current email of the organization signed in as the principal.
+// Opportunities belong to that email rather than to the one in the token, which is out of date
+// once the organization changes it. Other principals, and organizations that have been
+// deleted, have none.
+func organizationEmail(ctx context.Context, organizations store.OrganizationStore, principal *auth.Principal) (string, error) {
+	if principal == nil || principal.Role != auth.RoleOrganization {
+		return "", nil
+	}
+	organization, err := organizations.Get(ctx, principal.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return "", nil
+	}
+	return organization.Email, err
+}
+
+// ownsOpportunity reports whether the organization with the given current email posted the
+// opportunity
+func ownsOpportunity(email string, opportunity models.Opportunity) bool {
+	return email != "" && email == opportunity.Organization_mail
+}
+
+func ownsApplication(principal *auth.Principal, application models.Application) bool {
+	return principal != nil && principal.Role == auth.RoleVolunteer && principal.ID == application.Volunteer_ID
+}
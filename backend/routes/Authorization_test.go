@@ -0,0 +1,405 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// withPrincipal stands in for middleware.RequireAuth in tests
+func withPrincipal(principal *auth.Principal) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(middleware.PrincipalKey, principal)
+		c.Next()
+	}
+}
+
+func setupRouterForAuthorization(db *gorm.DB, principal *auth.Principal) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	stores := store.NewGorm(db)
+	r.Use(withPrincipal(principal))
+
+	r.PUT("/volunteers/update/:volunteer_mail", requireSelf(auth.RoleVolunteer, "volunteer_mail", volunteerAccount(stores.Volunteers)), func(c *gin.Context) {
+		updateVolunteer(c, stores.Volunteers, stores.Categories, testGeocoder, testBcryptCost)
+	})
+	r.PUT("/opportunities/update/:id", requireOpportunityOwner(stores.Opportunities, stores.Organizations, "id"), func(c *gin.Context) {
+		updateOpportunity(c, db, stores.Categories, testGeocoder)
+	})
+	r.POST("/opportunities/create", func(c *gin.Context) {
+		createOpportunity(c, stores.Opportunities, stores.Organizations, stores.Categories, testGeocoder)
+	})
+	r.GET("/applications/:id", requireApplicationAccess(stores.Applications, stores.Opportunities, stores.Organizations, false), func(c *gin.Context) {
+		getApplicationByID(c, stores.Applications)
+	})
+	r.GET("/applications/opportunity/:opportunity_id", requireOpportunityOwner(stores.Opportunities, stores.Organizations, "opportunity_id"), func(c *gin.Context) {
+		getApplicationsByOpportunityWithVolunteerDetails(c, db)
+	})
+	r.PUT("/applications/:id", requireApplicationAccess(stores.Applications, stores.Opportunities, stores.Organizations, false), func(c *gin.Context) {
+		updateApplication(c, db)
+	})
+	r.DELETE("/applications/:id", requireApplicationAccess(stores.Applications, stores.Opportunities, stores.Organizations, true), func(c *gin.Context) {
+		deleteApplication(c, db)
+	})
+
+	return r
+}
+
+// testOrganizationPrincipal returns the principal of the organization with the given email
+func testOrganizationPrincipal(db *gorm.DB, email string) *auth.Principal {
+	var organization models.Organization
+	if err := db.Where("email = ?", email).First(&organization).Error; err != nil {
+		panic("Failed to find test organization: " + err.Error())
+	}
+	return &auth.Principal{ID: organization.ID, Email: organization.Email, Role: auth.RoleOrganization}
+}
+
+func sendJSON(router *gin.Engine, method string, path string, body interface{}) *httptest.ResponseRecorder {
+	jsonData, _ := json.Marshal(body)
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestUpdateVolunteerForbiddenForOtherVolunteer(t *testing.T) {
+	db := setupTestDBForVolunteer()
+	defer cleanupTestVolunteers(db)
+
+	volunteer := createTestVolunteer(db)
+	router := setupRouterForAuthorization(db, &auth.Principal{ID: volunteer.ID + 1, Email: "other@volunteer.com", Role: auth.RoleVolunteer})
+
+	w := sendJSON(router, "PUT", fmt.Sprintf("/volunteers/update/%s", volunteer.Email), map[string]interface{}{"name": "Hijacked"})
+	t.Logf("Response Body: %s", w.Body.String())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, middleware.ForbiddenMessage, response["detail"])
+
+	var unchanged models.Volunteer
+	db.Where("email = ?", volunteer.Email).First(&unchanged)
+	assert.Equal(t, volunteer.Name, unchanged.Name)
+}
+
+func TestUpdateVolunteerAllowedForSelf(t *testing.T) {
+	db := setupTestDBForVolunteer()
+	defer cleanupTestVolunteers(db)
+
+	volunteer := createTestVolunteer(db)
+	router := setupRouterForAuthorization(db, &auth.Principal{ID: volunteer.ID, Email: volunteer.Email, Role: auth.RoleVolunteer})
+
+	w := sendJSON(router, "PUT", fmt.Sprintf("/volunteers/update/%s", volunteer.Email), map[string]interface{}{"name": "Renamed"})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestUpdateVolunteerAfterEmailChange(t *testing.T) {
+	db := setupTestDBForVolunteer()
+	defer cleanupTestVolunteers(db)
+
+	// The token still carries the email the volunteer had when it was issued
+	volunteer := createTestVolunteer(db)
+	router := setupRouterForAuthorization(db, &auth.Principal{ID: volunteer.ID, Email: volunteer.Email, Role: auth.RoleVolunteer})
+
+	w := sendJSON(router, "PUT", "/volunteers/update/"+volunteer.Email, map[string]interface{}{"email": "renamed@volunteer.com"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = sendJSON(router, "PUT", "/volunteers/update/renamed@volunteer.com", map[string]interface{}{"name": "Renamed"})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestUpdateOpportunityOwnership(t *testing.T) {
+	db := setupTestDBOpportunity()
+	defer cleanupTestOpportunities(db)
+
+	opp := createTestOpportunity(db)
+	path := fmt.Sprintf("/opportunities/update/%d", opp.ID)
+	body := map[string]interface{}{"title": "Updated Title"}
+
+	// Another organization cannot edit it
+	router := setupRouterForAuthorization(db, testOrganizationPrincipal(db, "test2@org.com"))
+	w := sendJSON(router, "PUT", path, body)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Volunteers cannot edit it
+	router = setupRouterForAuthorization(db, &auth.Principal{Email: "test@volunteer.com", Role: auth.RoleVolunteer})
+	w = sendJSON(router, "PUT", path, body)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// The owning organization can
+	router = setupRouterForAuthorization(db, testOrganizationPrincipal(db, opp.Organization_mail))
+	w = sendJSON(router, "PUT", path, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// And so can an admin
+	router = setupRouterForAuthorization(db, &auth.Principal{Email: "admin@helperhub.com", Role: auth.RoleAdmin})
+	w = sendJSON(router, "PUT", path, map[string]interface{}{"title": "Admin Title"})
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCreateOpportunityForOtherOrganizationForbidden(t *testing.T) {
+	db := setupTestDBOpportunity()
+	defer cleanupTestOpportunities(db)
+
+	router := setupRouterForAuthorization(db, testOrganizationPrincipal(db, "test2@org.com"))
+	w := sendJSON(router, "POST", "/opportunities/create", models.Opportunity{
+		Organization_mail: "test@org.com",
+		Category:          "Education",
+		Title:             "Not Mine",
+		Description:       "Posted for someone else",
+		Location:          "Test Location",
+		Hours_Required:    5,
+		Start_Date:        models.CustomDate(time.Now().AddDate(0, 0, 1)),
+		End_Date:          models.CustomDate(time.Now().AddDate(0, 0, 30)),
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var count int64
+	db.Model(&models.Opportunity{}).Where("title = ?", "Not Mine").Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestOpportunityOwnershipAfterEmailChange(t *testing.T) {
+	db := setupTestDBOpportunity()
+	defer cleanupTestOpportunities(db)
+
+	// The token still carries the email the organization had when it was issued
+	opp := createTestOpportunity(db)
+	owner := testOrganizationPrincipal(db, opp.Organization_mail)
+	organization, _ := store.NewGorm(db).Organizations.Get(context.Background(), owner.ID)
+	organization.Email = "renamed@org.com"
+	assert.NoError(t, store.NewGorm(db).Organizations.Update(context.Background(), &organization))
+	router := setupRouterForAuthorization(db, owner)
+
+	w := sendJSON(router, "PUT", fmt.Sprintf("/opportunities/update/%d", opp.ID), map[string]interface{}{"title": "Still Mine"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// New opportunities are posted under the current email, never the old one
+	posted := models.Opportunity{
+		Category:       "Education",
+		Title:          "Posted After Rename",
+		Description:    "Posted with an old token",
+		Location:       "Test Location",
+		Hours_Required: 5,
+		Start_Date:     models.CustomDate(time.Now().AddDate(0, 0, 1)),
+		End_Date:       models.CustomDate(time.Now().AddDate(0, 0, 30)),
+	}
+	w = sendJSON(router, "POST", "/opportunities/create", posted)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var created models.Opportunity
+	assert.NoError(t, db.Where("title = ?", posted.Title).First(&created).Error)
+	assert.Equal(t, "renamed@org.com", created.Organization_mail)
+
+	posted.Organization_mail = "test@org.com"
+	w = sendJSON(router, "POST", "/opportunities/create", posted)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestUpdateApplicationOwnership(t *testing.T) {
+	db := setupTestDBOpportunity()
+	defer cleanupTestOpportunities(db)
+
+	opp := createTestOpportunity(db)
+	app := createTestAppForOpp(db, opp.ID, "Pending")
+	path := fmt.Sprintf("/applications/%d", app.ID)
+
+	volunteer := &auth.Principal{ID: app.Volunteer_ID, Email: "test@volunteer.com", Role: auth.RoleVolunteer}
+	owner := testOrganizationPrincipal(db, opp.Organization_mail)
+	stranger := testOrganizationPrincipal(db, "test2@org.com")
+
+	// An unrelated organization cannot touch the application
+	w := sendJSON(setupRouterForAuthorization(db, stranger), "PUT", path, map[string]interface{}{"status": "Accepted"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// The volunteer cannot change the status
+	w = sendJSON(setupRouterForAuthorization(db, volunteer), "PUT", path, map[string]interface{}{"status": "Accepted"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// The organization cannot rewrite the cover letter
+	w = sendJSON(setupRouterForAuthorization(db, owner), "PUT", path, map[string]interface{}{"cover_Letter": "Rewritten"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// The volunteer can edit the cover letter
+	w = sendJSON(setupRouterForAuthorization(db, volunteer), "PUT", path, map[string]interface{}{"cover_Letter": "Updated cover letter"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The organization can change the status
+	w = sendJSON(setupRouterForAuthorization(db, owner), "PUT", path, map[string]interface{}{"status": "Accepted"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Only the volunteer (or an admin) can delete it
+	req, _ := http.NewRequest("DELETE", path, nil)
+	w = httptest.NewRecorder()
+	setupRouterForAuthorization(db, owner).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestReadApplicationOwnership(t *testing.T) {
+	db := setupTestDBOpportunity()
+	defer cleanupTestOpportunities(db)
+
+	opp := createTestOpportunity(db)
+	app := createTestAppForOpp(db, opp.ID, models.ApplicationPending)
+	path := fmt.Sprintf("/applications/%d", app.ID)
+	listPath := fmt.Sprintf("/applications/opportunity/%d", opp.ID)
+
+	volunteer := &auth.Principal{ID: app.Volunteer_ID, Email: "test@volunteer.com", Role: auth.RoleVolunteer}
+	otherVolunteer := &auth.Principal{ID: app.Volunteer_ID + 1, Email: "other@volunteer.com", Role: auth.RoleVolunteer}
+	owner := testOrganizationPrincipal(db, opp.Organization_mail)
+	stranger := testOrganizationPrincipal(db, "test2@org.com")
+
+	// Only the applicant and the organization can read the application
+	for principal, status := range map[*auth.Principal]int{volunteer: http.StatusOK, owner: http.StatusOK, otherVolunteer: http.StatusForbidden, stranger: http.StatusForbidden} {
+		w := sendJSON(setupRouterForAuthorization(db, principal), "GET", path, nil)
+		assert.Equal(t, status, w.Code, principal.Email)
+	}
+
+	// Only the organization can list the applicants, with their contact details
+	for principal, status := range map[*auth.Principal]int{owner: http.StatusOK, volunteer: http.StatusForbidden, stranger: http.StatusForbidden} {
+		w := sendJSON(setupRouterForAuthorization(db, principal), "GET", listPath, nil)
+		assert.Equal(t, status, w.Code, principal.Email)
+	}
+}
+
+func TestWithdrawApplication(t *testing.T) {
+	db := setupTestDBOpportunity()
+	defer cleanupTestOpportunities(db)
+
+	opp := createTestOpportunity(db)
+	app := createTestAppForOpp(db, opp.ID, models.ApplicationPending)
+	path := fmt.Sprintf("/applications/%d", app.ID)
+
+	volunteer := &auth.Principal{ID: app.Volunteer_ID, Email: "test@volunteer.com", Role: auth.RoleVolunteer}
+	owner := testOrganizationPrincipal(db, opp.Organization_mail)
+
+	// Organizations cannot withdraw on the volunteer's behalf
+	w := sendJSON(setupRouterForAuthorization(db, owner), "PUT", path, map[string]interface{}{"status": models.ApplicationWithdrawn})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = sendJSON(setupRouterForAuthorization(db, volunteer), "PUT", path, map[string]interface{}{"status": models.ApplicationWithdrawn})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var history models.ApplicationStatusHistory
+	db.Where("application_id = ?", app.ID).Last(&history)
+	assert.Equal(t, models.ApplicationWithdrawn, history.To_Status)
+	assert.Equal(t, volunteer.ID, history.Actor_ID)
+	assert.Equal(t, auth.RoleVolunteer, history.Actor_Role)
+
+	// Withdrawn applications cannot be accepted afterwards
+	w = sendJSON(setupRouterForAuthorization(db, owner), "PUT", path, map[string]interface{}{"status": models.ApplicationAccepted})
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestOwnershipLookupFailureRefused(t *testing.T) {
+	// A database without the tables fails every lookup with an error other than a missing record
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	stores := store.NewGorm(db)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(withPrincipal(&auth.Principal{ID: 1, Email: "test@org.com", Role: auth.RoleOrganization}))
+	reached := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/opportunities/:id", requireOpportunityOwner(stores.Opportunities, stores.Organizations, "id"), reached)
+	r.GET("/applications/:id", requireApplicationAccess(stores.Applications, stores.Opportunities, stores.Organizations, false), reached)
+	r.GET("/time-entries/:id", requireTimeEntryAccess(db, stores.Applications, stores.Opportunities, stores.Organizations, auth.RoleOrganization), reached)
+
+	for _, path := range []string{"/opportunities/1", "/applications/1", "/time-entries/1"} {
+		w := sendJSON(r, "GET", path, nil)
+		assert.Equal(t, http.StatusInternalServerError, w.Code, path)
+	}
+}
+
+func TestOpportunityOwnerWithoutDatabase(t *testing.T) {
+	ctx := context.Background()
+	stores := store.NewMemory()
+	owner := models.Organization{Email: "owner@org.com", Name: "Owner"}
+	assert.NoError(t, stores.Organizations.Create(ctx, &owner))
+	other := models.Organization{Email: "other@org.com", Name: "Other"}
+	assert.NoError(t, stores.Organizations.Create(ctx, &other))
+	opportunity := models.Opportunity{Organization_mail: owner.Email, Title: "Tutoring"}
+	assert.NoError(t, stores.Opportunities.Create(ctx, &opportunity))
+
+	router := func(principal *auth.Principal) *gin.Engine {
+		gin.SetMode(gin.TestMode)
+		r := gin.New()
+		r.Use(withPrincipal(principal))
+		r.GET("/opportunities/get/:id", func(c *gin.Context) { getOpportunity(c, stores.Opportunities) })
+		r.DELETE("/opportunities/delete/:id", requireOpportunityOwner(stores.Opportunities, stores.Organizations, "id"), func(c *gin.Context) {
+			deleteOpportunity(c, stores.Opportunities)
+		})
+		return r
+	}
+	ownerRouter := router(&auth.Principal{ID: owner.ID, Email: owner.Email, Role: auth.RoleOrganization})
+	path := fmt.Sprintf("/opportunities/delete/%d", opportunity.ID)
+
+	w := sendJSON(router(&auth.Principal{ID: other.ID, Email: other.Email, Role: auth.RoleOrganization}), "DELETE", path, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = sendJSON(ownerRouter, "DELETE", "/opportunities/delete/abc", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = sendJSON(ownerRouter, "DELETE", path, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = sendJSON(ownerRouter, "DELETE", path, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = sendJSON(ownerRouter, "GET", fmt.Sprintf("/opportunities/get/%d", opportunity.ID), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// A store that cannot be read is not mistaken for a missing opportunity
+	r := gin.New()
+	r.GET("/opportunities/get/:id", func(c *gin.Context) { getOpportunity(c, failingOpportunities{}) })
+	w = sendJSON(r, "GET", "/opportunities/get/1", nil)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestVolunteerRoutesRequireSelf(t *testing.T) {
+	db := setupTestDBForVolunteer()
+	defer cleanupTestVolunteers(db)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	tokens := newTestTokenManager()
+	SetupRoutes(router, db, store.NewGorm(db), testConfig(), tokens, testGeocoder)
+
+	volunteer := createTestVolunteer(db)
+	principals := map[auth.Principal]int{
+		{ID: volunteer.ID, Email: volunteer.Email, Role: auth.RoleVolunteer}:           http.StatusOK,
+		{ID: volunteer.ID, Email: "admin@helperhub.com", Role: auth.RoleAdmin}:         http.StatusOK,
+		{ID: volunteer.ID + 1, Email: "other@volunteer.com", Role: auth.RoleVolunteer}: http.StatusForbidden,
+		{ID: volunteer.ID, Email: "test@org.com", Role: auth.RoleOrganization}:         http.StatusForbidden,
+	}
+
+	// Only the volunteer and admins can see the stats, recommendations and applications of a
+	// volunteer
+	paths := []string{
+		"/volunteers/%d/stats",
+		"/volunteers/%d/recommendations",
+		"/applications/volunteer/%d",
+		"/applications/volunteer/%d/approved?n=5",
+		"/applications/volunteer/%d/completed?n=5",
+	}
+	for principal, status := range principals {
+		token, _, _ := tokens.IssueAccessToken(principal)
+		for _, path := range paths {
+			req, _ := http.NewRequest("GET", fmt.Sprintf(path, volunteer.ID), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, status, w.Code, principal.Email+" "+path)
+		}
+	}
+}
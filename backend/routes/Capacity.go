@@ -0,0 +1,154 @@
+package routes
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Capacity and waitlist handling. An opportunity's capacity is the number of volunteers it
+// can accept, and each of its shifts can have a capacity of its own; once the seats are taken,
+// new applications are Waitlisted. When an accepted volunteer leaves, waitlisted applications
+// are accepted in the order they were made. Every change that can take or free a seat locks
+// the opportunity row first so concurrent requests see a consistent count.
+
+var (
+	errOpportunityFull    = errors.New("Opportunity is at capacity")
+	errApplicationChanged = errors.New("Application was changed by another request, please retry")
+)
+
+// systemActor is recorded as the actor of status changes the platform makes on its own, such
+// as waitlist promotions
+var systemActor = &auth.Principal{Role: "system"}
+
+// lockOpportunity loads an opportunity and locks its row until the transaction ends
+func lockOpportunity(tx *gorm.DB, id uint) (models.Opportunity, error) {
+	var opportunity models.Opportunity
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&opportunity).Error
+	return opportunity, err
+}
+
+// countAcceptedApplications returns the number of seats taken on an opportunity
+func countAcceptedApplications(tx *gorm.DB, opportunityID uint) (int64, error) {
+	var accepted int64
+	err := tx.Model(&models.Application{}).
+		Where("opportunity_id = ? AND status IN ?", opportunityID, models.ApprovedApplicationStatuses).
+		Count(&accepted).Error
+	return accepted, err
+}
+
+// countAcceptedShiftApplications returns the number of seats taken on each shift of an opportunity
+func countAcceptedShiftApplications(tx *gorm.DB, opportunityID uint) (map[uint]int64, error) {
+	var counts []struct {
+		Shift_ID uint
+		Count    int64
+	}
+	if err := tx.Model(&models.Application{}).
+		Select("shift_id, COUNT(*) AS count").
+		Where("opportunity_id = ? AND shift_id IS NOT NULL AND status IN ?", opportunityID, models.ApprovedApplicationStatuses).
+		Group("shift_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	accepted := map[uint]int64{}
+	for _, count := range counts {
+		accepted[count.Shift_ID] = count.Count
+	}
+	return accepted, nil
+}
+
+// hasOpenSeat reports whether another application can be accepted on the opportunity and, for
+// applications made for a shift, on that shift
+func hasOpenSeat(tx *gorm.DB, opportunity models.Opportunity, shiftID *uint) (bool, error) {
+	if opportunity.Capacity > 0 {
+		accepted, err := countAcceptedApplications(tx, opportunity.ID)
+		if err != nil {
+			return false, err
+		}
+		if accepted >= int64(opportunity.Capacity) {
+			return false, nil
+		}
+	}
+	if shiftID == nil {
+		return true, nil
+	}
+
+	var shift models.Shift
+	if err := tx.Where("id = ?", *shiftID).First(&shift).Error; err != nil {
+		return false, err
+	}
+	if shift.Capacity == 0 {
+		return true, nil
+	}
+	var accepted int64
+	if err := tx.Model(&models.Application{}).
+		Where("shift_id = ? AND status IN ?", shift.ID, models.ApprovedApplicationStatuses).
+		Count(&accepted).Error; err != nil {
+		return false, err
+	}
+	return accepted < int64(shift.Capacity), nil
+}
+
+// promoteWaitlisted accepts waitlisted applications, oldest first, while their opportunity and
+// shift have open seats. The caller must hold the opportunity lock.
+func promoteWaitlisted(tx *gorm.DB, opportunity models.Opportunity) error {
+	var waitlisted []models.Application
+	if err := tx.Where("opportunity_id = ? AND status = ?", opportunity.ID, models.ApplicationWaitlisted).
+		Order("created_at ASC, id ASC").
+		Find(&waitlisted).Error; err != nil {
+		return err
+	}
+	if len(waitlisted) == 0 {
+		return nil
+	}
+
+	accepted, err := countAcceptedApplications(tx, opportunity.ID)
+	if err != nil {
+		return err
+	}
+	acceptedByShift, err := countAcceptedShiftApplications(tx, opportunity.ID)
+	if err != nil {
+		return err
+	}
+	var shifts []models.Shift
+	if err := tx.Where("opportunity_id = ?", opportunity.ID).Find(&shifts).Error; err != nil {
+		return err
+	}
+	shiftCapacity := map[uint]uint{}
+	for _, shift := range shifts {
+		shiftCapacity[shift.ID] = shift.Capacity
+	}
+
+	for _, application := range waitlisted {
+		if opportunity.Capacity > 0 && accepted >= int64(opportunity.Capacity) {
+			return nil
+		}
+		// A full shift does not hold up applications for other shifts
+		if application.Shift_ID != nil {
+			capacity := shiftCapacity[*application.Shift_ID]
+			if capacity > 0 && acceptedByShift[*application.Shift_ID] >= int64(capacity) {
+				continue
+			}
+		}
+
+		if err := tx.Model(&models.Application{}).
+			Where("id = ? AND status = ?", application.ID, models.ApplicationWaitlisted).
+			Updates(map[string]interface{}{"status": models.ApplicationAccepted, "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		if err := recordApplicationStatus(tx, application.ID, models.ApplicationWaitlisted, models.ApplicationAccepted, systemActor); err != nil {
+			return err
+		}
+
+		accepted++
+		if application.Shift_ID != nil {
+			acceptedByShift[*application.Shift_ID]++
+		}
+	}
+	return nil
+}
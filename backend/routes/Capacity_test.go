@@ -0,0 +1,214 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupRouterForCapacity(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+
+	r.POST("/applications", func(c *gin.Context) {
+		createApplication(c, db)
+	})
+	r.PUT("/applications/:id", func(c *gin.Context) {
+		updateApplication(c, db)
+	})
+	r.DELETE("/applications/:id", func(c *gin.Context) {
+		deleteApplication(c, db)
+	})
+	r.PUT("/opportunities/update/:id", func(c *gin.Context) {
+		updateOpportunity(c, db, store.NewGorm(db).Categories, testGeocoder)
+	})
+
+	return r
+}
+
+func createTestOpportunityWithCapacity(db *gorm.DB, capacity uint) models.Opportunity {
+	opp := createTestOpportunity(db)
+	db.Model(&opp).Update("capacity", capacity)
+	opp.Capacity = capacity
+	return opp
+}
+
+func createCapacityTestVolunteers(db *gorm.DB, n int) []models.Volunteer {
+	volunteers := make([]models.Volunteer, 0, n)
+	for i := 0; i < n; i++ {
+		volunteer := models.Volunteer{
+			Email:         fmt.Sprintf("capacity%d@volunteer.com", i),
+			Password:      "unused",
+			Name:          fmt.Sprintf("Capacity Volunteer %d", i),
+			Phone:         fmt.Sprintf("555-01%02d", i),
+			Category_List: models.StringList{},
+			Created_At:    time.Now(),
+			Updated_At:    time.Now(),
+		}
+		if err := db.Create(&volunteer).Error; err != nil {
+			panic("Failed to create test volunteer: " + err.Error())
+		}
+		volunteers = append(volunteers, volunteer)
+	}
+	return volunteers
+}
+
+func cleanupCapacityTest(db *gorm.DB) {
+	cleanupTestOpportunities(db)
+	db.Exec("DELETE FROM application_status_histories")
+	db.Exec("DELETE FROM volunteers WHERE email LIKE 'capacity%'")
+}
+
+func applyForOpportunity(t *testing.T, router *gin.Engine, volunteerID uint, opportunityID uint) models.ApplicationResponse {
+	w := sendJSON(router, "POST", "/applications", models.ApplicationCreateRequest{
+		Volunteer_ID:   volunteerID,
+		Opportunity_ID: opportunityID,
+		Cover_Letter:   "Capacity test",
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ApplicationResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response
+}
+
+func setApplicationStatus(router *gin.Engine, applicationID uint, status string) int {
+	w := sendJSON(router, "PUT", fmt.Sprintf("/applications/%d", applicationID), map[string]interface{}{"status": status})
+	return w.Code
+}
+
+func applicationStatus(db *gorm.DB, id uint) string {
+	var application models.Application
+	db.First(&application, id)
+	return application.Status
+}
+
+func TestApplicationsAreWaitlistedAtCapacity(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForCapacity(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createTestOpportunityWithCapacity(db, 1)
+	volunteers := createCapacityTestVolunteers(db, 3)
+
+	first := applyForOpportunity(t, router, volunteers[0].ID, opp.ID)
+	assert.Equal(t, models.ApplicationPending, first.Status)
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, first.ID, models.ApplicationAccepted))
+
+	// The only seat is taken, so later applicants join the waitlist in order
+	second := applyForOpportunity(t, router, volunteers[1].ID, opp.ID)
+	third := applyForOpportunity(t, router, volunteers[2].ID, opp.ID)
+	assert.Equal(t, models.ApplicationWaitlisted, second.Status)
+	assert.Equal(t, models.ApplicationWaitlisted, third.Status)
+
+	// Waitlisted applications cannot jump the queue while the opportunity is full
+	assert.Equal(t, http.StatusConflict, setApplicationStatus(router, third.ID, models.ApplicationAccepted))
+
+	// When the accepted volunteer withdraws, the oldest waitlisted application takes the seat
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, first.ID, models.ApplicationWithdrawn))
+	assert.Equal(t, models.ApplicationAccepted, applicationStatus(db, second.ID))
+	assert.Equal(t, models.ApplicationWaitlisted, applicationStatus(db, third.ID))
+
+	var promotion models.ApplicationStatusHistory
+	db.Where("application_id = ?", second.ID).Last(&promotion)
+	assert.Equal(t, models.ApplicationWaitlisted, promotion.From_Status)
+	assert.Equal(t, models.ApplicationAccepted, promotion.To_Status)
+	assert.Equal(t, "system", promotion.Actor_Role)
+
+	// Removing an accepted volunteer promotes the next one
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, second.ID, models.ApplicationRemoved))
+	assert.Equal(t, models.ApplicationAccepted, applicationStatus(db, third.ID))
+}
+
+func TestDeleteAcceptedApplicationRefused(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForCapacity(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createTestOpportunityWithCapacity(db, 1)
+	volunteers := createCapacityTestVolunteers(db, 2)
+
+	first := applyForOpportunity(t, router, volunteers[0].ID, opp.ID)
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, first.ID, models.ApplicationAccepted))
+	second := applyForOpportunity(t, router, volunteers[1].ID, opp.ID)
+	assert.Equal(t, models.ApplicationWaitlisted, second.Status)
+
+	// An accepted application keeps its seat and history until it is withdrawn
+	w := sendJSON(router, "DELETE", fmt.Sprintf("/applications/%d", first.ID), nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, models.ApplicationAccepted, applicationStatus(db, first.ID))
+	assert.Equal(t, models.ApplicationWaitlisted, applicationStatus(db, second.ID))
+
+	// A waitlisted application can still be deleted
+	w = sendJSON(router, "DELETE", fmt.Sprintf("/applications/%d", second.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRaisingCapacityPromotesWaitlist(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForCapacity(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createTestOpportunityWithCapacity(db, 1)
+	volunteers := createCapacityTestVolunteers(db, 3)
+
+	first := applyForOpportunity(t, router, volunteers[0].ID, opp.ID)
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, first.ID, models.ApplicationAccepted))
+	second := applyForOpportunity(t, router, volunteers[1].ID, opp.ID)
+	third := applyForOpportunity(t, router, volunteers[2].ID, opp.ID)
+
+	w := sendJSON(router, "PUT", fmt.Sprintf("/opportunities/update/%d", opp.ID), map[string]interface{}{"capacity": 2})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, models.ApplicationAccepted, applicationStatus(db, second.ID))
+	assert.Equal(t, models.ApplicationWaitlisted, applicationStatus(db, third.ID))
+}
+
+func TestConcurrentAcceptsRespectCapacity(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForCapacity(db)
+	defer cleanupCapacityTest(db)
+
+	const capacity = 2
+	opp := createTestOpportunityWithCapacity(db, capacity)
+	volunteers := createCapacityTestVolunteers(db, 5)
+
+	applications := make([]models.ApplicationResponse, 0, len(volunteers))
+	for _, volunteer := range volunteers {
+		applications = append(applications, applyForOpportunity(t, router, volunteer.ID, opp.ID))
+	}
+
+	var wg sync.WaitGroup
+	codes := make([]int, len(applications))
+	for i, application := range applications {
+		wg.Add(1)
+		go func(i int, id uint) {
+			defer wg.Done()
+			codes[i] = setApplicationStatus(router, id, models.ApplicationAccepted)
+		}(i, application.ID)
+	}
+	wg.Wait()
+
+	accepted := 0
+	for _, code := range codes {
+		if code == http.StatusOK {
+			accepted++
+		} else {
+			assert.Equal(t, http.StatusConflict, code)
+		}
+	}
+	assert.Equal(t, capacity, accepted)
+
+	var count int64
+	db.Model(&models.Application{}).Where("opportunity_id = ? AND status = ?", opp.ID, models.ApplicationAccepted).Count(&count)
+	assert.Equal(t, int64(capacity), count)
+}
@@ -1,12 +1,14 @@
 package routes
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/store"
 	"github.com/prathamrao021/HelperHub/models"
-	"gorm.io/gorm"
 )
 
 // CreateCategory godoc
@@ -16,10 +18,12 @@ import (
 // @Accept json
 // @Produce json
 // @Success 200 {object} map[string]string
+// @Failure 403 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /categories/create [post]
-func CreateCategory(c *gin.Context, db *gorm.DB) {
+func CreateCategory(c *gin.Context, categories store.CategoryStore) {
 	// Define the static categories
-	categories := []string{
+	names := []string{
 		"Web Development",
 		"Graphic Design",
 		"Content Writing",
@@ -32,24 +36,28 @@ func CreateCategory(c *gin.Context, db *gorm.DB) {
 		"Project Management",
 	}
 
+	ctx := context.Background()
+	if c != nil {
+		ctx = c.Request.Context()
+	}
+
 	// Iterate over the categories and insert them into the database if they do not already exist
-	for _, category := range categories {
-		var existingCategory models.Category
-		if err := db.Where("category = ?", category).First(&existingCategory).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
+	for _, category := range names {
+		if _, err := categories.GetByName(ctx, category); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
 				newCategory := models.Category{
 					Category:   category,
 					Created_At: time.Now(),
 				}
-				if err := db.Create(&newCategory).Error; err != nil {
+				if err := categories.Create(ctx, &newCategory); err != nil {
 					if c != nil {
-						c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+						respondError(c, err)
 					}
 					return
 				}
 			} else {
 				if c != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					respondError(c, err)
 				}
 				return
 			}
@@ -63,18 +71,34 @@ func CreateCategory(c *gin.Context, db *gorm.DB) {
 
 // getCategories godoc
 // @Summary Get all categories
-// @Description Get all categories
+// @Description Get all categories. Sortable and filterable by id, category and created_at.
 // @Tags categories
 // @Accept json
 // @Produce json
-// @Success 200 {object} models.Category
+// @Param sort query string false "Comma-separated fields to sort by, - for descending"
+// @Param filter query []string false "field:op:value, with op one of eq, ne, lt, lte, gt, gte, in and like" collectionFormat(multi)
+// @Param limit query int false "Page size, 20 by default and at most 100"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} listing.Page{data=[]models.CategoryResponse}
+// @Failure 400 {object} middleware.Problem
 // @Router /categories/get [get]
-func getCategories(c *gin.Context, db *gorm.DB) {
-	var categories []models.Category
-	if err := db.Find(&categories).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+func getCategories(c *gin.Context, categories store.CategoryStore) {
+	options, ok := parseListOptions(c, categoryListSpec)
+	if !ok {
+		return
+	}
+
+	page, total, err := categories.List(c.Request.Context(), options)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, categories)
+	response := make([]models.CategoryResponse, 0, len(page))
+	for _, category := range page {
+		response = append(response, models.NewCategoryResponse(category))
+	}
+
+	c.JSON(http.StatusOK, options.Page(response, total, c.Request.URL))
 }
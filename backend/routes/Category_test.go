@@ -7,37 +7,22 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/store"
 	"github.com/prathamrao021/HelperHub/models"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 // setupTestDBForCategory initializes a PostgreSQL database for category testing
 func setupTestDBForCategory() (*gorm.DB, error) {
-	// Use the same PostgreSQL connection as in main.go but with a test database
-	dsn := "host=localhost user=postgres password=admin dbname=Helperhub_test port=5432 sslmode=prefer TimeZone=Asia/Shanghai"
-	
-	// Configure gorm with minimal logging during tests
-	config := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-	}
-
-	// Open connection to PostgreSQL
-	db, err := gorm.Open(postgres.Open(dsn), config)
+	// Connect to the test database configured like the real one
+	db, err := openTestDB()
 	if err != nil {
 		return nil, err
 	}
 
-	// Clean up existing tables
-	db.Exec("DROP TABLE IF EXISTS categories CASCADE")
-
-	// Migrate the schema
-	err = db.AutoMigrate(&models.Category{})
-	if err != nil {
-		return nil, err
-	}
+	// Start from an empty schema
+	resetTestDB(db)
 
 	return db, nil
 }
@@ -46,11 +31,12 @@ func setupTestDBForCategory() (*gorm.DB, error) {
 func setupCategoryRouter(db *gorm.DB) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	stores := store.NewGorm(db)
 	
 	// Set up the routes for categories
 	categoryRouter := router.Group("/categories")
-	categoryRouter.POST("/create", func(c *gin.Context) { CreateCategory(c, db) })
-	categoryRouter.GET("/get", func(c *gin.Context) { getCategories(c, db) })
+	categoryRouter.POST("/create", func(c *gin.Context) { CreateCategory(c, stores.Categories) })
+	categoryRouter.GET("/get", func(c *gin.Context) { getCategories(c, stores.Categories) })
 
 	return router
 }
@@ -128,7 +114,7 @@ func TestGetCategories(t *testing.T) {
 	router := setupCategoryRouter(db)
 
 	// First create the categories
-	CreateCategory(nil, db)
+	CreateCategory(nil, store.NewGorm(db).Categories)
 
 	// Create a test request to get categories
 	req, _ := http.NewRequest("GET", "/categories/get", nil)
@@ -144,8 +130,7 @@ func TestGetCategories(t *testing.T) {
 
 	// Parse response body
 	var categories []models.Category
-	err = json.Unmarshal(w.Body.Bytes(), &categories)
-	assert.Nil(t, err)
+	decodePage(t, w, &categories)
 
 	// Verify we have the expected number of categories
 	expectedCategories := []string{
@@ -256,4 +241,25 @@ func TestCategoryDatabaseError(t *testing.T) {
 
 	// Should get an internal server error
 	assert.Equal(t, http.StatusInternalServerError, w2.Code)
-}
\ No newline at end of file
+}
+func TestCategoriesInMemory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	categories := store.NewMemory().Categories
+	router.GET("/categories/get", func(c *gin.Context) { getCategories(c, categories) })
+
+	// Creating the categories twice leaves one of each
+	CreateCategory(nil, categories)
+	CreateCategory(nil, categories)
+
+	w := sendJSON(router, "GET", "/categories/get?sort=-category&limit=3", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response []models.CategoryResponse
+	page := decodePage(t, w, &response)
+	assert.Equal(t, int64(10), page.Total)
+	if assert.Len(t, response, 3) {
+		assert.Equal(t, "Web Development", response[0].Category)
+	}
+	assert.NotEmpty(t, page.Next)
+}
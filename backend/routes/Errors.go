@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"gorm.io/gorm"
+)
+
+// respondError responds to a request that failed with an error from a store or the database.
+// Missing records get a 404, duplicate keys a 409 and references to missing records a 422.
+// Any other error is unexpected: it is attached to the request to be logged under its ID, and
+// the client gets a 500 with the ID instead of the error, which can reveal queries and the
+// schema.
+func respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		middleware.RespondProblem(c, http.StatusNotFound, "Record not found")
+	case errors.Is(err, store.ErrDuplicate), errors.Is(err, gorm.ErrDuplicatedKey):
+		middleware.RespondProblem(c, http.StatusConflict, "A record with the same unique values already exists")
+	case errors.Is(err, store.ErrInvalidReference), errors.Is(err, gorm.ErrForeignKeyViolated):
+		middleware.RespondProblem(c, http.StatusUnprocessableEntity, "A referenced record does not exist")
+	default:
+		c.Error(err)
+		middleware.RespondInternalError(c)
+	}
+}
@@ -0,0 +1,83 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestInternalErrorHidesDatabaseError(t *testing.T) {
+	db, err := openTestDB()
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.Close()
+
+	var logs bytes.Buffer
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.Logger(slog.New(slog.NewJSONHandler(&logs, nil))))
+	router.GET("/opportunities/search", func(c *gin.Context) { searchOpportunities(c, db) })
+
+	w := sendJSON(router, "GET", "/opportunities/search", nil)
+	requestID := w.Header().Get(middleware.RequestIDHeader)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"instance":"/opportunities/search","code":"internal_error","request_id":"`+requestID+`"}`, w.Body.String())
+
+	// The database error is only in the log, under the same request ID
+	assert.Contains(t, logs.String(), `"request_id":"`+requestID+`"`)
+	assert.Contains(t, logs.String(), "database is closed")
+}
+
+func TestRespondError(t *testing.T) {
+	db := setupTestDBForVolunteer()
+	defer cleanupTestVolunteers(db)
+	volunteer := createTestVolunteer(db)
+
+	// Errors as the database reports them, translated by GORM
+	duplicate := db.Create(&models.Volunteer{Email: volunteer.Email, Phone: "5550001111"}).Error
+	missingReference := db.Create(&models.Application{Volunteer_ID: volunteer.ID, Opportunity_ID: 9999, Status: models.ApplicationPending}).Error
+
+	tests := map[string]struct {
+		err    error
+		status int
+		code   string
+	}{
+		"Store Not Found":      {store.ErrNotFound, http.StatusNotFound, middleware.CodeNotFound},
+		"Record Not Found":     {gorm.ErrRecordNotFound, http.StatusNotFound, middleware.CodeNotFound},
+		"Store Duplicate":      {store.ErrDuplicate, http.StatusConflict, middleware.CodeConflict},
+		"Duplicate Key":        {duplicate, http.StatusConflict, middleware.CodeConflict},
+		"Invalid Reference":    {store.ErrInvalidReference, http.StatusUnprocessableEntity, middleware.CodeValidationFailed},
+		"Foreign Key Violated": {missingReference, http.StatusUnprocessableEntity, middleware.CodeValidationFailed},
+		"Unexpected":           {errors.New("connection reset by peer"), http.StatusInternalServerError, middleware.CodeInternal},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Error(t, test.err)
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/fail", func(c *gin.Context) { respondError(c, test.err) })
+
+			w := sendJSON(router, "GET", "/fail", nil)
+			assert.Equal(t, test.status, w.Code)
+			assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"))
+
+			var problem middleware.Problem
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, test.status, problem.Status)
+			assert.Equal(t, test.code, problem.Code)
+		})
+	}
+}
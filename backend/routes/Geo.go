@@ -0,0 +1,141 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/geo"
+	"github.com/prathamrao021/HelperHub/internal/listing"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"github.com/prathamrao021/HelperHub/models"
+	"gorm.io/gorm"
+)
+
+// Locations are stored as typed and geocoded into coordinates alongside. A location the
+// geocoder does not know is stored without coordinates rather than refused, so it is simply
+// left out of distance queries.
+
+// kmPerDegreeLatitude is used to turn a radius into a bounding box the coordinates index can use
+const kmPerDegreeLatitude = 111.0
+
+// distanceSQL is the great-circle distance in kilometers from a point given as (latitude,
+// latitude, longitude) arguments to an opportunity. It is NULL for opportunities without
+// coordinates.
+const distanceSQL = "(2 * 6371 * ASIN(SQRT(" +
+	"POWER(SIN(RADIANS(opportunities.latitude - ?) / 2), 2) + " +
+	"COS(RADIANS(?)) * COS(RADIANS(opportunities.latitude)) * POWER(SIN(RADIANS(opportunities.longitude - ?) / 2), 2))))"
+
+// geocodeLocation returns the coordinates of a location, or nil coordinates when the location
+// is empty or unknown to the geocoder
+func geocodeLocation(ctx context.Context, geocoder geo.Geocoder, location string) (*float64, *float64) {
+	if geocoder == nil || strings.TrimSpace(location) == "" {
+		return nil, nil
+	}
+
+	point, err := geocoder.Geocode(ctx, location)
+	if err != nil {
+		if !errors.Is(err, geo.ErrNotFound) {
+			log.Printf("Failed to geocode %q: %v", location, err)
+		}
+		return nil, nil
+	}
+	return &point.Latitude, &point.Longitude
+}
+
+// distanceFilter narrows opportunity lists by distance from a point and lets them be sorted by
+// it. The point is either given as lat and lng or taken from a volunteer's location with
+// volunteer_id.
+type distanceFilter struct {
+	origin        *geo.Point
+	radiusKm      float64 // 0 means any distance
+	includeRemote bool    // Keep remote opportunities when filtering by radius
+}
+
+// parseDistanceFilter reads the distance query parameters, responding with an error and
+// returning false when they are invalid
+func parseDistanceFilter(c *gin.Context, db *gorm.DB) (distanceFilter, bool) {
+	var filter distanceFilter
+
+	latStr, lngStr, volunteerID := c.Query("lat"), c.Query("lng"), c.Query("volunteer_id")
+	switch {
+	case latStr != "" || lngStr != "":
+		lat, latErr := strconv.ParseFloat(latStr, 64)
+		lng, lngErr := strconv.ParseFloat(lngStr, 64)
+		if latErr != nil || lngErr != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+			middleware.RespondProblem(c, http.StatusBadRequest, "lat and lng must be valid coordinates")
+			return filter, false
+		}
+		filter.origin = &geo.Point{Latitude: lat, Longitude: lng}
+	case volunteerID != "":
+		var volunteer models.Volunteer
+		if err := db.Where("id = ?", volunteerID).First(&volunteer).Error; err != nil {
+			middleware.RespondProblem(c, http.StatusNotFound, "Volunteer not found")
+			return filter, false
+		}
+		if volunteer.Latitude == nil || volunteer.Longitude == nil {
+			middleware.RespondProblem(c, http.StatusUnprocessableEntity, "The volunteer's location could not be geocoded")
+			return filter, false
+		}
+		filter.origin = &geo.Point{Latitude: *volunteer.Latitude, Longitude: *volunteer.Longitude}
+	}
+
+	if radiusStr := c.Query("radius_km"); radiusStr != "" {
+		radius, err := strconv.ParseFloat(radiusStr, 64)
+		if err != nil || radius <= 0 {
+			middleware.RespondProblem(c, http.StatusBadRequest, "Invalid radius_km")
+			return filter, false
+		}
+		filter.radiusKm = radius
+	}
+	filter.includeRemote = c.Query("include_remote") == "true"
+
+	if filter.origin == nil && filter.radiusKm > 0 {
+		middleware.RespondProblem(c, http.StatusBadRequest, "lat and lng or volunteer_id are required to filter by distance")
+		return filter, false
+	}
+	return filter, true
+}
+
+// distanceArgs are the arguments of distanceSQL
+func (f distanceFilter) distanceArgs() []interface{} {
+	return []interface{}{f.origin.Latitude, f.origin.Latitude, f.origin.Longitude}
+}
+
+// selectDistance returns the distance column to add to a select, and its arguments
+func (f distanceFilter) selectDistance() (string, []interface{}) {
+	if f.origin == nil {
+		return "", nil
+	}
+	return ", " + distanceSQL + " AS distance_km", f.distanceArgs()
+}
+
+// where keeps the opportunities within the radius
+func (f distanceFilter) where(query *gorm.DB) *gorm.DB {
+	if f.origin == nil || f.radiusKm == 0 {
+		return query
+	}
+
+	// The bounding box lets the database skip far away rows before computing distances
+	latDelta := f.radiusKm / kmPerDegreeLatitude
+	nearby := query.Session(&gorm.Session{NewDB: true}).
+		Where("opportunities.latitude BETWEEN ? AND ?", f.origin.Latitude-latDelta, f.origin.Latitude+latDelta).
+		Where(distanceSQL+" <= ?", append(f.distanceArgs(), f.radiusKm)...)
+	if f.includeRemote {
+		return query.Where(query.Session(&gorm.Session{NewDB: true}).Where("opportunities.remote = ?", true).Or(nearby))
+	}
+	return query.Where(nearby)
+}
+
+// listSpec lets a list be sorted by distance_km when there is a point to measure from.
+// Opportunities without coordinates come last.
+func (f distanceFilter) listSpec(spec listing.Spec) listing.Spec {
+	if f.origin == nil {
+		return spec
+	}
+	return spec.With("distance_km", listing.Field{Column: "distance_km", Type: listing.Number, Computed: true, Nullable: true})
+}
@@ -0,0 +1,111 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prathamrao021/HelperHub/internal/geo"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// testGeocoder knows a couple of places; every other location is stored without coordinates
+var testGeocoder = geo.NewStaticGeocoder(map[string]geo.Point{
+	"Gainesville, FL": geo.Places["Gainesville, FL"],
+	"Orlando, FL":     geo.Places["Orlando, FL"],
+})
+
+func setTestCoordinates(db *gorm.DB, model interface{}, place string) {
+	point := geo.Places[place]
+	db.Model(model).Updates(map[string]interface{}{"location": place, "latitude": point.Latitude, "longitude": point.Longitude})
+}
+
+func TestOpportunityGeocoding(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterOpportunity(db)
+	defer cleanupTestOpportunities(db)
+
+	request := models.OpportunityCreateRequest{
+		Organization_mail: "test@org.com",
+		Category:          "Education",
+		Title:             "Geocoded Opportunity",
+		Description:       "Test Description",
+		Location:          "1 University Ave, Gainesville, FL",
+		Hours_Required:    5,
+		Start_Date:        models.CustomDate(time.Now().AddDate(0, 0, 1)),
+		End_Date:          models.CustomDate(time.Now().AddDate(0, 0, 30)),
+	}
+	w := sendJSON(router, "POST", "/opportunities/create", request)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var opportunity models.OpportunityResponse
+	json.Unmarshal(w.Body.Bytes(), &opportunity)
+	assert.NotNil(t, opportunity.Latitude)
+	assert.Equal(t, geo.Places["Gainesville, FL"].Longitude, *opportunity.Longitude)
+
+	// Unknown locations are kept without coordinates
+	w = sendJSON(router, "PUT", fmt.Sprintf("/opportunities/update/%d", opportunity.ID), map[string]interface{}{"location": "Somewhere Else"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &opportunity)
+	assert.Nil(t, opportunity.Latitude)
+
+	// Remote opportunities have no coordinates even at a known location
+	request.Title, request.Remote = "Remote Opportunity", true
+	w = sendJSON(router, "POST", "/opportunities/create", request)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &opportunity)
+	assert.True(t, opportunity.Remote)
+	assert.Nil(t, opportunity.Latitude)
+}
+
+func TestAvailableOpportunitiesByDistance(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterOpportunity(db)
+	defer cleanupCapacityTest(db)
+
+	volunteers := createCapacityTestVolunteers(db, 1)
+	setTestCoordinates(db, &volunteers[0], "Gainesville, FL")
+
+	nearby := createTestOpportunity(db)
+	setTestCoordinates(db, &nearby, "Gainesville, FL")
+	farAway := createTestOpportunity(db)
+	setTestCoordinates(db, &farAway, "Orlando, FL")
+	remote := createTestOpportunity(db)
+	db.Model(&remote).Update("remote", true)
+
+	getAvailable := func(query string) []models.AvailableOpportunityResponse {
+		w := sendJSON(router, "GET", "/opportunities/available"+query, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var opportunities []models.AvailableOpportunityResponse
+		decodePage(t, w, &opportunities)
+		return opportunities
+	}
+
+	// Within 50 km of the volunteer only the opportunity in the same city is left
+	opportunities := getAvailable(fmt.Sprintf("?volunteer_id=%d&radius_km=50", volunteers[0].ID))
+	assert.Len(t, opportunities, 1)
+	assert.Equal(t, nearby.ID, opportunities[0].ID)
+	assert.InDelta(t, 0, *opportunities[0].Distance_Km, 0.01)
+
+	opportunities = getAvailable(fmt.Sprintf("?volunteer_id=%d&radius_km=50&include_remote=true", volunteers[0].ID))
+	assert.Len(t, opportunities, 2)
+
+	// Sorted by distance, opportunities without coordinates come last
+	orlando := geo.Places["Orlando, FL"]
+	opportunities = getAvailable(fmt.Sprintf("?lat=%f&lng=%f&sort=distance_km", orlando.Latitude, orlando.Longitude))
+	assert.Len(t, opportunities, 3)
+	assert.Equal(t, farAway.ID, opportunities[0].ID)
+	assert.Equal(t, nearby.ID, opportunities[1].ID)
+	assert.InDelta(t, 154, *opportunities[1].Distance_Km, 5)
+	assert.Nil(t, opportunities[2].Distance_Km)
+
+	w := sendJSON(router, "GET", "/opportunities/available?radius_km=10", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = sendJSON(router, "GET", "/opportunities/available?sort=distance_km", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = sendJSON(router, "GET", "/opportunities/available?lat=100&lng=0", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
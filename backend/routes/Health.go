@@ -0,0 +1,66 @@
+package routes
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/migrate"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"gorm.io/gorm"
+)
+
+// readinessTimeout bounds the database checks of a readiness probe
+const readinessTimeout = 2 * time.Second
+
+// SetupHealthRoutes registers the liveness and readiness probes. They are public, and
+// registered apart from the API so that they can be served without it.
+func SetupHealthRoutes(router *gin.Engine, db *gorm.DB, migrator *migrate.Migrator) {
+	router.GET("/healthz", healthz)
+	router.GET("/readyz", func(c *gin.Context) { readyz(c, db, migrator) })
+}
+
+// healthz godoc
+// @Summary Liveness probe
+// @Description Reports that the server is running. It does not check the database.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// readyz godoc
+// @Summary Readiness probe
+// @Description Reports whether the server can take traffic: the database answers and its schema is at the version this build expects. The check only reads, so a database that has not been migrated is reported as not ready.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} middleware.Problem
+// @Router /readyz [get]
+func readyz(c *gin.Context, db *gorm.DB, migrator *migrate.Migrator) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	sqlDB, err := db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		log.Printf("Readiness check failed: %v", err)
+		middleware.RespondProblem(c, http.StatusServiceUnavailable, "Database is unavailable")
+		return
+	}
+
+	migrator = migrator.WithContext(ctx)
+	if err := migrator.Check(); err != nil {
+		log.Printf("Readiness check failed: %v", err)
+		middleware.RespondProblem(c, http.StatusServiceUnavailable, "Database schema is not at the expected version")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "schema_version": migrator.Latest()})
+}
@@ -0,0 +1,70 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/migrate"
+	"github.com/prathamrao021/HelperHub/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupHealthRoutes(router, nil, nil)
+
+	w := sendJSON(router, "GET", "/healthz", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+func TestReadyz(t *testing.T) {
+	db, err := openTestDB()
+	require.NoError(t, err)
+	resetTestDB(db)
+
+	migrator, err := migrate.New(db, migrations.For(db.Dialector.Name()))
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	SetupHealthRoutes(router, db, migrator)
+
+	w := sendJSON(router, "GET", "/readyz", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"ready"`)
+
+	t.Run("Schema Behind", func(t *testing.T) {
+		_, err := migrator.Down(1)
+		require.NoError(t, err)
+		defer migrator.Up()
+
+		w := sendJSON(router, "GET", "/readyz", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("Not Migrated", func(t *testing.T) {
+		require.NoError(t, db.Migrator().DropTable("schema_migrations"))
+		defer resetTestDB(db)
+
+		w := sendJSON(router, "GET", "/readyz", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.False(t, db.Migrator().HasTable("schema_migrations"), "The probe must not create the table")
+	})
+
+	t.Run("Database Closed", func(t *testing.T) {
+		closed, err := openTestDB()
+		require.NoError(t, err)
+		sqlDB, err := closed.DB()
+		require.NoError(t, err)
+		sqlDB.Close()
+
+		router := gin.New()
+		SetupHealthRoutes(router, closed, migrator)
+		w := sendJSON(router, "GET", "/readyz", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
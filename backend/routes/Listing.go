@@ -0,0 +1,123 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/listing"
+	"github.com/prathamrao021/HelperHub/middleware"
+)
+
+// Sorting, filtering and pagination of the list endpoints. Each spec below whitelists the fields
+// of one kind of list; see the listing package for the query parameters.
+
+var applicationListSpec = listing.Spec{
+	Fields: map[string]listing.Field{
+		"id":             {Column: "applications.id", Type: listing.Number},
+		"volunteer_id":   {Column: "applications.volunteer_id", Type: listing.Number},
+		"opportunity_id": {Column: "applications.opportunity_id", Type: listing.Number},
+		"shift_id":       {Column: "applications.shift_id", Type: listing.Number, Nullable: true},
+		"status":         {Column: "applications.status", Type: listing.String},
+		"created_at":     {Column: "applications.created_at", Type: listing.Time},
+		"updated_at":     {Column: "applications.updated_at", Type: listing.Time},
+	},
+	DefaultSort: "-created_at",
+	Key:         "id",
+}
+
+var opportunityListFields = map[string]listing.Field{
+	"id":                {Column: "opportunities.id", Type: listing.Number},
+	"organization_mail": {Column: "opportunities.organization_mail", Type: listing.String},
+	"category":          {Column: "opportunities.category", Type: listing.String},
+	"title":             {Column: "opportunities.title", Type: listing.String},
+	"location":          {Column: "opportunities.location", Type: listing.String},
+	"remote":            {Column: "opportunities.remote", Type: listing.Bool},
+	"hours_required":    {Column: "opportunities.hours_required", Type: listing.Number},
+	"capacity":          {Column: "opportunities.capacity", Type: listing.Number},
+	"start_date":        {Column: "opportunities.start_date", Type: listing.Date},
+	"end_date":          {Column: "opportunities.end_date", Type: listing.Date},
+	"created_at":        {Column: "opportunities.created_at", Type: listing.Time},
+}
+
+var opportunityListSpec = listing.Spec{
+	Fields:      opportunityListFields,
+	DefaultSort: "start_date",
+	Key:         "id",
+}
+
+// availableOpportunityListSpec is for the lists joined with the organizations offering the
+// opportunities
+var availableOpportunityListSpec = opportunityListSpec.With("organization_name", listing.Field{Column: "organizations.name", Type: listing.String})
+
+var adminOpportunityListSpec = listing.Spec{
+	Fields:      opportunityListFields,
+	DefaultSort: "id",
+	Key:         "id",
+}
+
+var categoryListSpec = listing.Spec{
+	Fields: map[string]listing.Field{
+		"id":         {Column: "id", Type: listing.Number},
+		"category":   {Column: "category", Type: listing.String},
+		"created_at": {Column: "created_at", Type: listing.Time},
+	},
+	DefaultSort: "id",
+	Key:         "id",
+}
+
+var volunteerListSpec = listing.Spec{
+	Fields: map[string]listing.Field{
+		"id":              {Column: "id", Type: listing.Number},
+		"email":           {Column: "email", Type: listing.String},
+		"name":            {Column: "name", Type: listing.String},
+		"location":        {Column: "location", Type: listing.String},
+		"available_hours": {Column: "available_hours", Type: listing.Number},
+		"created_at":      {Column: "created_at", Type: listing.Time},
+		"updated_at":      {Column: "updated_at", Type: listing.Time},
+	},
+	DefaultSort: "id",
+	Key:         "id",
+}
+
+var organizationListSpec = listing.Spec{
+	Fields: map[string]listing.Field{
+		"id":         {Column: "id", Type: listing.Number},
+		"email":      {Column: "email", Type: listing.String},
+		"name":       {Column: "name", Type: listing.String},
+		"location":   {Column: "location", Type: listing.String},
+		"created_at": {Column: "created_at", Type: listing.Time},
+		"updated_at": {Column: "updated_at", Type: listing.Time},
+	},
+	DefaultSort: "id",
+	Key:         "id",
+}
+
+var userListSpec = listing.Spec{
+	Fields: map[string]listing.Field{
+		"id":         {Column: "id", Type: listing.Number},
+		"email":      {Column: "email", Type: listing.String},
+		"full_name":  {Column: "full_name", Type: listing.String},
+		"role":       {Column: "role", Type: listing.String},
+		"created_at": {Column: "created_at", Type: listing.Time},
+	},
+	DefaultSort: "id",
+	Key:         "id",
+}
+
+// parseListOptions reads the list options of the request, responding with an error and
+// returning false when they are invalid
+func parseListOptions(c *gin.Context, spec listing.Spec) (listing.Options, bool) {
+	options, err := listing.Parse(c.Request.URL.Query(), spec)
+	if err != nil {
+		middleware.RespondProblem(c, http.StatusBadRequest, err.Error())
+		return options, false
+	}
+	return options, true
+}
+
+// paramID reads a numeric ID from a path parameter, returning false when it is not one
+func paramID(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	return uint(id), err == nil
+}
@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prathamrao021/HelperHub/internal/listing"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// decodePage decodes a list response, putting its items into data
+func decodePage(t *testing.T, w *httptest.ResponseRecorder, data interface{}) listing.Page {
+	page := listing.Page{Data: data}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	return page
+}
+
+func TestListPagination(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterOpportunity(db)
+	defer cleanupTestOpportunities(db)
+
+	var created []uint
+	for i := 0; i < 3; i++ {
+		created = append(created, createTestOpportunity(db).ID)
+	}
+	db.Model(&models.Opportunity{}).Where("id = ?", created[2]).Update("hours_required", 20)
+
+	// Walking the pages with the next links visits every opportunity once
+	var seen []uint
+	next := "/opportunities/available?sort=id&limit=2"
+	for next != "" {
+		w := sendJSON(router, "GET", next, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var opportunities []models.AvailableOpportunityResponse
+		page := decodePage(t, w, &opportunities)
+		assert.Equal(t, int64(3), page.Total)
+		for _, opportunity := range opportunities {
+			seen = append(seen, opportunity.ID)
+		}
+		next = page.Next
+	}
+	assert.Equal(t, created, seen)
+
+	// Offsets page the same list
+	w := sendJSON(router, "GET", "/opportunities/available?sort=-id&limit=2&offset=2", nil)
+	var opportunities []models.AvailableOpportunityResponse
+	page := decodePage(t, w, &opportunities)
+	assert.Len(t, opportunities, 1)
+	assert.Equal(t, created[0], opportunities[0].ID)
+	assert.Equal(t, 2, *page.Offset)
+	assert.Empty(t, page.Next)
+
+	// Filters narrow the list and its total
+	w = sendJSON(router, "GET", "/opportunities/available?filter=hours_required:gt:10&filter=organization_name:like:organization", nil)
+	page = decodePage(t, w, &opportunities)
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, created[2], opportunities[0].ID)
+
+	for _, query := range []string{"?sort=password", "?filter=id:eq:one", "?limit=0", "?offset=1&cursor=abc", "?cursor=abc"} {
+		w = sendJSON(router, "GET", "/opportunities/available"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -10,38 +10,23 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/store"
 	"github.com/prathamrao021/HelperHub/models"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 func setupTestDBOpportunity() *gorm.DB {
-	// Use the same PostgreSQL connection as in main.go but with a test database
-	dsn := "host=localhost user=postgres password=admin dbname=Helperhub_test port=5432 sslmode=prefer TimeZone=Asia/Shanghai"
-
-	// Configure gorm with minimal logging during tests
-	config := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-	}
-
-	// Open connection to PostgreSQL
-	db, err := gorm.Open(postgres.Open(dsn), config)
+	// Connect to the test database configured like the real one
+	db, err := openTestDB()
 	if err != nil {
 		panic("Failed to connect to test database: " + err.Error())
 	}
 
-	// Clean up existing tables
-	db.Exec("DROP TABLE IF EXISTS opportunities CASCADE")
-	db.Exec("DROP TABLE IF EXISTS applications CASCADE")
-
-	// Auto migrate required models
-	db.AutoMigrate(&models.Opportunity{})
-	db.AutoMigrate(&models.Application{})
-	db.AutoMigrate(&models.Organization{})
-	db.AutoMigrate(&models.Volunteer{})
+	// Start from an empty schema
+	resetTestDB(db)
+	createTestCategories(db)
 
 	// Create test organization (required for foreign key constraint)
 	// First, check if organization already exists
@@ -84,16 +69,16 @@ func setupTestDBOpportunity() *gorm.DB {
 	if count == 0 {
 		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
 		testVolunteer := models.Volunteer{
-			Email:            "test@volunteer.com",
-			Password:         string(hashedPassword),
-			Name:             "Test Volunteer",
-			Phone:            "1234567890",
-			Location:         "Test Location",
-			Bio_Data:         "Test Bio",
-			Category_List:    models.StringList{"Education", "Environment"},
-			Availabile_Hours: 10,
-			Created_At:       time.Now(),
-			Updated_At:       time.Now(),
+			Email:           "test@volunteer.com",
+			Password:        string(hashedPassword),
+			Name:            "Test Volunteer",
+			Phone:           "1234567890",
+			Location:        "Test Location",
+			Bio_Data:        "Test Bio",
+			Category_List:   models.StringList{"Education", "Environment"},
+			Available_Hours: 10,
+			Created_At:      time.Now(),
+			Updated_At:      time.Now(),
 		}
 		db.Create(&testVolunteer)
 	}
@@ -104,23 +89,24 @@ func setupTestDBOpportunity() *gorm.DB {
 func setupRouterOpportunity(db *gorm.DB) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	stores := store.NewGorm(db)
 
 	// Register routes with injected database
 	r.POST("/opportunities/create", func(c *gin.Context) {
-		createOpportunity(c, db)
+		createOpportunity(c, stores.Opportunities, stores.Organizations, stores.Categories, testGeocoder)
 	})
 	r.DELETE("/opportunities/delete/:id", func(c *gin.Context) {
-		deleteOpportunity(c, db)
+		deleteOpportunity(c, stores.Opportunities)
 	})
 	r.PUT("/opportunities/update/:id", func(c *gin.Context) {
-		updateOpportunity(c, db)
+		updateOpportunity(c, db, stores.Categories, testGeocoder)
 	})
 	r.GET("/opportunities/get/:id", func(c *gin.Context) {
-		getOpportunity(c, db)
+		getOpportunity(c, stores.Opportunities)
 	})
 
 	// Additional routes from Opportunity.go
-	r.GET("/opportunities/organization/:organization_id/expired", func(c *gin.Context) {
+	r.GET("/opportunities/organization/:organization_mail/expired", func(c *gin.Context) {
 		getLastNExpiredOpportunitiesByOrganization(c, db)
 	})
 	r.GET("/opportunities", func(c *gin.Context) {
@@ -208,8 +194,12 @@ func createTestAppForOpp(db *gorm.DB, opportunityID uint, status string) models.
 	var volunteer models.Volunteer
 	db.Where("email = ?", "test@volunteer.com").First(&volunteer)
 
+	return createTestAppForOppByVolunteer(db, opportunityID, volunteer.ID, status)
+}
+
+func createTestAppForOppByVolunteer(db *gorm.DB, opportunityID uint, volunteerID uint, status string) models.Application {
 	app := models.Application{
-		Volunteer_ID:   volunteer.ID,
+		Volunteer_ID:   volunteerID,
 		Opportunity_ID: opportunityID,
 		Status:         status,
 		Cover_Letter:   "Test Cover Letter",
@@ -231,6 +221,7 @@ func createTestAppForOpp(db *gorm.DB, opportunityID uint, status string) models.
 
 func cleanupTestOpportunities(db *gorm.DB) {
 	db.Exec("DELETE FROM applications")
+	db.Exec("DELETE FROM shifts")
 	db.Exec("DELETE FROM opportunities")
 }
 
@@ -504,8 +495,10 @@ func TestGetOpportunitiesByOrganization(t *testing.T) {
 	opp2 := createTestOpportunity(db)
 
 	// Create applications for the opportunities
+	secondVolunteer := createCapacityTestVolunteers(db, 1)[0]
+	defer db.Delete(&secondVolunteer)
 	createTestAppForOpp(db, opp1.ID, "pending")
-	createTestAppForOpp(db, opp1.ID, "accepted")
+	createTestAppForOppByVolunteer(db, opp1.ID, secondVolunteer.ID, "accepted")
 	createTestAppForOpp(db, opp2.ID, "pending")
 
 	// Create request
@@ -523,7 +516,7 @@ func TestGetOpportunitiesByOrganization(t *testing.T) {
 
 	// Parse response - since it returns a custom map structure
 	var response []map[string]interface{}
-	json.Unmarshal(w.Body.Bytes(), &response)
+	decodePage(t, w, &response)
 
 	// Verify we got at least 2 opportunities
 	assert.GreaterOrEqual(t, len(response), 2)
@@ -566,7 +559,7 @@ func TestGetAvailableOpportunities(t *testing.T) {
 
 	// Parse response - custom map structure with organization name
 	var response []map[string]interface{}
-	json.Unmarshal(w.Body.Bytes(), &response)
+	decodePage(t, w, &response)
 
 	// Verify we got at least one opportunity
 	assert.GreaterOrEqual(t, len(response), 1)
@@ -779,7 +772,7 @@ func TestGetApplicationsByOpportunityWithVolunteerDetails(t *testing.T) {
 	db.Limit(2).Find(&existingVolunteers)
 	
 	if len(existingVolunteers) < 2 {
-		t.Fatalf("Not enough existing volunteers found, need at least 2 for this test")
+		existingVolunteers = createCapacityTestVolunteers(db, 2)
 	}
 	
 	volunteer1 := existingVolunteers[0]
@@ -977,8 +970,8 @@ func TestGetApplicationsByOpportunityWithVolunteerDetailsEmptyID(t *testing.T) {
 	// Verify error message - since we're manually setting an empty ID, 
 	// the function should return a 400 Bad Request with the appropriate error message
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Contains(t, response, "error")
-	assert.Equal(t, "opportunity_id is required", response["error"])
+	assert.Equal(t, "bad_request", response["code"])
+	assert.Equal(t, "opportunity_id is required", response["detail"])
 }
 
 func TestGetApplicationsByOpportunityWithVolunteerDetailsNoApplications(t *testing.T) {
@@ -1108,7 +1101,7 @@ func TestGetApplicationsByOpportunityWithVolunteerDetailsDatabaseError(t *testin
 	// Verify response contains an error message
 	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Contains(t, response, "error")
+	assert.Equal(t, "internal_error", response["code"])
 }
 
 
@@ -1154,16 +1147,16 @@ func TestGetOpportunityWithStats(t *testing.T) {
 		
 		// Create first volunteer
 		volunteer1 := models.Volunteer{
-			Email:            fmt.Sprintf("teststats1_%d@example.com", time.Now().UnixNano()),
-			Password:         "hashed_password",
-			Name:             "Stats Test Volunteer 1",
-			Phone:            fmt.Sprintf("1%d", time.Now().UnixNano()%1000000000), // Ensure unique phone
-			Location:         "Test Location 1",
-			Bio_Data:         "Test Bio 1",
-			Category_List:    models.StringList{"Education"},
-			Availabile_Hours: 5,
-			Created_At:       time.Now(),
-			Updated_At:       time.Now(),
+			Email:           fmt.Sprintf("teststats1_%d@example.com", time.Now().UnixNano()),
+			Password:        "hashed_password",
+			Name:            "Stats Test Volunteer 1",
+			Phone:           fmt.Sprintf("1%d", time.Now().UnixNano()%1000000000), // Ensure unique phone
+			Location:        "Test Location 1",
+			Bio_Data:        "Test Bio 1",
+			Category_List:   models.StringList{"Education"},
+			Available_Hours: 5,
+			Created_At:      time.Now(),
+			Updated_At:      time.Now(),
 		}
 		result = db.Create(&volunteer1)
 		if result.Error != nil {
@@ -1173,16 +1166,16 @@ func TestGetOpportunityWithStats(t *testing.T) {
 		
 		// Create second volunteer
 		volunteer2 := models.Volunteer{
-			Email:            fmt.Sprintf("teststats2_%d@example.com", time.Now().UnixNano()),
-			Password:         "hashed_password",
-			Name:             "Stats Test Volunteer 2",
-			Phone:            fmt.Sprintf("2%d", time.Now().UnixNano()%1000000000), // Ensure unique phone
-			Location:         "Test Location 2",
-			Bio_Data:         "Test Bio 2",
-			Category_List:    models.StringList{"Environment"},
-			Availabile_Hours: 10,
-			Created_At:       time.Now(),
-			Updated_At:       time.Now(),
+			Email:           fmt.Sprintf("teststats2_%d@example.com", time.Now().UnixNano()),
+			Password:        "hashed_password",
+			Name:            "Stats Test Volunteer 2",
+			Phone:           fmt.Sprintf("2%d", time.Now().UnixNano()%1000000000), // Ensure unique phone
+			Location:        "Test Location 2",
+			Bio_Data:        "Test Bio 2",
+			Category_List:   models.StringList{"Environment"},
+			Available_Hours: 10,
+			Created_At:      time.Now(),
+			Updated_At:      time.Now(),
 		}
 		result = db.Create(&volunteer2)
 		if result.Error != nil {
@@ -1192,16 +1185,16 @@ func TestGetOpportunityWithStats(t *testing.T) {
 		
 		// Create third volunteer
 		volunteer3 := models.Volunteer{
-			Email:            fmt.Sprintf("teststats3_%d@example.com", time.Now().UnixNano()),
-			Password:         "hashed_password",
-			Name:             "Stats Test Volunteer 3",
-			Phone:            fmt.Sprintf("3%d", time.Now().UnixNano()%1000000000), // Ensure unique phone
-			Location:         "Test Location 3",
-			Bio_Data:         "Test Bio 3",
-			Category_List:    models.StringList{"Health"},
-			Availabile_Hours: 15,
-			Created_At:       time.Now(),
-			Updated_At:       time.Now(),
+			Email:           fmt.Sprintf("teststats3_%d@example.com", time.Now().UnixNano()),
+			Password:        "hashed_password",
+			Name:            "Stats Test Volunteer 3",
+			Phone:           fmt.Sprintf("3%d", time.Now().UnixNano()%1000000000), // Ensure unique phone
+			Location:        "Test Location 3",
+			Bio_Data:        "Test Bio 3",
+			Category_List:   models.StringList{"Health"},
+			Available_Hours: 15,
+			Created_At:      time.Now(),
+			Updated_At:      time.Now(),
 		}
 		result = db.Create(&volunteer3)
 		if result.Error != nil {
@@ -1269,8 +1262,9 @@ func TestGetOpportunityWithStats(t *testing.T) {
 	// Setup router with our test endpoint
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
-	router.GET("/opportunities/:id", func(c *gin.Context) {
-		getOpportunityWithStats(c, db)
+	organizations := store.NewGorm(db).Organizations
+	router.GET("/opportunities/:opportunity_id", func(c *gin.Context) {
+		getOpportunityWithStats(c, db, organizations)
 	})
 	
 	// Create request
@@ -1338,8 +1332,9 @@ func TestGetOpportunityWithStatsNoApplications(t *testing.T) {
 	// Setup router with our test endpoint
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
-	router.GET("/opportunities/:id", func(c *gin.Context) {
-		getOpportunityWithStats(c, db)
+	organizations := store.NewGorm(db).Organizations
+	router.GET("/opportunities/:opportunity_id", func(c *gin.Context) {
+		getOpportunityWithStats(c, db, organizations)
 	})
 	
 	// Create request
@@ -1378,8 +1373,9 @@ func TestGetOpportunityWithStatsNonExistent(t *testing.T) {
 	// Setup router with our test endpoint
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
-	router.GET("/opportunities/:id", func(c *gin.Context) {
-		getOpportunityWithStats(c, db)
+	organizations := store.NewGorm(db).Organizations
+	router.GET("/opportunities/:opportunity_id", func(c *gin.Context) {
+		getOpportunityWithStats(c, db, organizations)
 	})
 	
 	// Create request with non-existent ID
@@ -1400,8 +1396,8 @@ func TestGetOpportunityWithStatsNonExistent(t *testing.T) {
 	assert.NoError(t, err, "Response should be valid JSON")
 	
 	// Verify error message
-	assert.Contains(t, response, "error")
-	assert.Equal(t, "Opportunity not found", response["error"])
+	assert.Equal(t, "not_found", response["code"])
+	assert.Equal(t, "Opportunity not found", response["detail"])
 }
 
 func TestGetOpportunityWithStatsMixedCaseStatus(t *testing.T) {
@@ -1456,8 +1452,9 @@ func TestGetOpportunityWithStatsMixedCaseStatus(t *testing.T) {
 	// Setup router with our test endpoint
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
-	router.GET("/opportunities/:id", func(c *gin.Context) {
-		getOpportunityWithStats(c, db)
+	organizations := store.NewGorm(db).Organizations
+	router.GET("/opportunities/:opportunity_id", func(c *gin.Context) {
+		getOpportunityWithStats(c, db, organizations)
 	})
 	
 	// Create request
@@ -1,12 +1,19 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/internal/geo"
+	"github.com/prathamrao021/HelperHub/internal/listing"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/internal/validation"
+	"github.com/prathamrao021/HelperHub/middleware"
 	"github.com/prathamrao021/HelperHub/models"
 	"gorm.io/gorm"
 )
@@ -17,25 +24,73 @@ import (
 // @Tags opportunities
 // @Accept json
 // @Produce json
-// @Param opportunity body models.Opportunity true "Opportunity data"
-// @Success 200 {object} models.Opportunity
+// @Param opportunity body models.OpportunityCreateRequest true "Opportunity data"
+// @Success 200 {object} models.OpportunityResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /opportunities/create [post]
-func createOpportunity(c *gin.Context, db *gorm.DB) {
-	var opportunity models.Opportunity
-	if err := c.ShouldBindJSON(&opportunity); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func createOpportunity(c *gin.Context, opportunities store.OpportunityStore, organizations store.OrganizationStore, categories store.CategoryStore, geocoder geo.Geocoder) {
+	var request models.OpportunityCreateRequest
+	fields, ok := decodeJSON(c, &request)
+	if !ok {
+		return
+	}
+	if request.Category != "" {
+		unknown, err := unknownCategory(c.Request.Context(), categories, "category", request.Category)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		fields = append(fields, unknown...)
+	}
+	if respondInvalidFields(c, fields) {
 		return
 	}
 
+	opportunity := models.Opportunity{
+		Organization_mail: normalizeEmail(request.Organization_mail),
+		Category:          request.Category,
+		Title:             request.Title,
+		Description:       request.Description,
+		Location:          request.Location,
+		Remote:            request.Remote,
+		Hours_Required:    request.Hours_Required,
+		Capacity:          request.Capacity,
+		Start_Date:        request.Start_Date,
+		End_Date:          request.End_Date,
+	}
+
+	// Organizations can only post opportunities under the current email of their own account
+	if principal, ok := middleware.CurrentPrincipal(c); ok && principal.Role == auth.RoleOrganization {
+		email, err := organizationEmail(c.Request.Context(), organizations, principal)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if opportunity.Organization_mail == "" {
+			opportunity.Organization_mail = email
+		}
+		if email == "" || opportunity.Organization_mail != email {
+			middleware.AbortForbidden(c)
+			return
+		}
+	}
+
+	// Remote opportunities have no place to measure distances to
+	if !opportunity.Remote {
+		opportunity.Latitude, opportunity.Longitude = geocodeLocation(c.Request.Context(), geocoder, opportunity.Location)
+	}
 	opportunity.Created_At = time.Now()
 	opportunity.Updated_At = time.Now()
 
-	if err := db.Create(&opportunity).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := opportunities.Create(c.Request.Context(), &opportunity); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, opportunity)
+	c.JSON(http.StatusOK, models.NewOpportunityResponse(opportunity))
 }
 
 // deleteOpportunity godoc
@@ -46,18 +101,23 @@ func createOpportunity(c *gin.Context, db *gorm.DB) {
 // @Produce json
 // @Param id path uint true "Opportunity ID"
 // @Success 200 {object} map[string]string
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /opportunities/delete/{id} [delete]
-func deleteOpportunity(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	var opportunity models.Opportunity
-	if err := db.Where("id = ?", id).First(&opportunity).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Opportunity not found"})
+func deleteOpportunity(c *gin.Context, opportunities store.OpportunityStore) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
 		return
-	} else {
-		if err := db.Where("id = ?", id).Delete(&opportunity).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+	}
+	if err := opportunities.Delete(c.Request.Context(), id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		} else {
+			respondError(c, err)
 		}
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Opportunity deleted successfully"})
@@ -65,37 +125,126 @@ func deleteOpportunity(c *gin.Context, db *gorm.DB) {
 
 // updateOpportunity godoc
 // @Summary Update an existing opportunity
-// @Description Update an existing opportunity with the provided details
+// @Description Update an existing opportunity with the provided details. Raising the capacity accepts waitlisted applications.
 // @Tags opportunities
 // @Accept json
 // @Produce json
 // @Param id path uint true "Opportunity ID"
-// @Param opportunity body models.Opportunity true "Opportunity data"
-// @Success 200 {object} models.Opportunity
+// @Param opportunity body models.OpportunityUpdateRequest true "Opportunity data"
+// @Success 200 {object} models.OpportunityResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /opportunities/update/{id} [put]
-func updateOpportunity(c *gin.Context, db *gorm.DB) {
+func updateOpportunity(c *gin.Context, db *gorm.DB, categories store.CategoryStore, geocoder geo.Geocoder) {
 	id := c.Param("id")
 	var opportunity models.Opportunity
 
 	if err := db.Where("id = ?", id).First(&opportunity).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Opportunity not found"})
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
 		return
 	}
 
-	var updatedOpportunity map[string]interface{}
-	if err := c.ShouldBindJSON(&updatedOpportunity); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var request models.OpportunityUpdateRequest
+	fields, ok := decodeJSON(c, &request)
+	if !ok {
+		return
+	}
+	if request.Category != nil {
+		unknown, err := unknownCategory(c.Request.Context(), categories, "category", *request.Category)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		fields = append(fields, unknown...)
+	}
+	// The dates that are not changed still have to fit the ones that are
+	startDate, endDate := opportunity.Start_Date, opportunity.End_Date
+	if request.Start_Date != nil {
+		startDate = *request.Start_Date
+	}
+	if request.End_Date != nil {
+		endDate = *request.End_Date
+	}
+	if endDate.ToTime().Before(startDate.ToTime()) {
+		fields = append(fields, validation.FieldError{Field: "end_date", Message: "must not be before start_date"})
+	}
+	if respondInvalidFields(c, fields) {
 		return
 	}
 
+	updatedOpportunity := map[string]interface{}{}
+	if request.Category != nil {
+		updatedOpportunity["category"] = *request.Category
+	}
+	if request.Title != nil {
+		updatedOpportunity["title"] = *request.Title
+	}
+	if request.Description != nil {
+		updatedOpportunity["description"] = *request.Description
+	}
+	if request.Location != nil {
+		updatedOpportunity["location"] = *request.Location
+	}
+	if request.Remote != nil {
+		updatedOpportunity["remote"] = *request.Remote
+	}
+	if request.Location != nil || request.Remote != nil {
+		location, remote := opportunity.Location, opportunity.Remote
+		if request.Location != nil {
+			location = *request.Location
+		}
+		if request.Remote != nil {
+			remote = *request.Remote
+		}
+		updatedOpportunity["latitude"], updatedOpportunity["longitude"] = (*float64)(nil), (*float64)(nil)
+		if !remote {
+			updatedOpportunity["latitude"], updatedOpportunity["longitude"] = geocodeLocation(c.Request.Context(), geocoder, location)
+		}
+	}
+	if request.Hours_Required != nil {
+		updatedOpportunity["hours_required"] = *request.Hours_Required
+	}
+	if request.Capacity != nil {
+		updatedOpportunity["capacity"] = *request.Capacity
+	}
+	if request.Start_Date != nil {
+		updatedOpportunity["start_date"] = *request.Start_Date
+	}
+	if request.End_Date != nil {
+		updatedOpportunity["end_date"] = *request.End_Date
+	}
 	updatedOpportunity["updated_at"] = time.Now()
 
-	if err := db.Model(&opportunity).Updates(updatedOpportunity).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockOpportunity(tx, opportunity.ID); err != nil {
+			return err
+		}
+		if err := tx.Model(&opportunity).Updates(updatedOpportunity).Error; err != nil {
+			return err
+		}
+		if request.Capacity == nil {
+			return nil
+		}
+
+		// A larger capacity opens seats for the waitlist
+		var updated models.Opportunity
+		if err := tx.Where("id = ?", opportunity.ID).First(&updated).Error; err != nil {
+			return err
+		}
+		return promoteWaitlisted(tx, updated)
+	}); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := db.First(&opportunity, opportunity.ID).Error; err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, opportunity)
+	c.JSON(http.StatusOK, models.NewOpportunityResponse(opportunity))
 }
 
 // getOpportunity godoc
@@ -105,18 +254,27 @@ func updateOpportunity(c *gin.Context, db *gorm.DB) {
 // @Accept json
 // @Produce json
 // @Param id path uint true "Opportunity ID"
-// @Success 200 {object} models.Opportunity
+// @Success 200 {object} models.OpportunityResponse
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /opportunities/get/{id} [get]
-func getOpportunity(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	var opportunity models.Opportunity
-
-	if err := db.Where("id = ?", id).First(&opportunity).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Opportunity not found"})
+func getOpportunity(c *gin.Context, opportunities store.OpportunityStore) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		return
+	}
+	opportunity, err := opportunities.Get(c.Request.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, opportunity)
+	c.JSON(http.StatusOK, models.NewOpportunityResponse(opportunity))
 }
 
 // getLastNExpiredOpportunitiesByOrganization godoc
@@ -127,14 +285,15 @@ func getOpportunity(c *gin.Context, db *gorm.DB) {
 // @Produce json
 // @Param organization_mail path uint true "Organization ID"
 // @Param n query int true "Number of opportunities"
-// @Success 200 {array} models.Opportunity
+// @Success 200 {array} models.OpportunityResponse
+// @Security BearerAuth
 // @Router /opportunities/organization/{organization_mail}/expired [get]
 func getLastNExpiredOpportunitiesByOrganization(c *gin.Context, db *gorm.DB) {
-	organizationMail := c.Param("organizaion_mail")
+	organizationMail := normalizeEmail(c.Param("organization_mail"))
 	nStr := c.Query("n")
 	n, err := strconv.Atoi(nStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid number of opportunities"})
+		middleware.RespondProblem(c, http.StatusBadRequest, "Invalid number of opportunities")
 		return
 	}
 
@@ -145,168 +304,215 @@ func getLastNExpiredOpportunitiesByOrganization(c *gin.Context, db *gorm.DB) {
 		Order("end_date desc").
 		Limit(n).
 		Find(&opportunities).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, opportunities)
+	c.JSON(http.StatusOK, models.NewOpportunityResponses(opportunities))
 }
 
 // getOpportunitiesWithApplicationCount godoc
 // @Summary Retrieve all opportunities for an organization with application counts
-// @Description Retrieve all opportunities for a specific organization, including the number of applications each opportunity has received
+// @Description Retrieve all opportunities for a specific organization, including the number of applications each opportunity has received, soonest first.
+// @Description Sortable and filterable by the opportunity fields; application_count can be sorted by but not filtered.
 // @Tags opportunities
 // @Accept json
 // @Produce json
 // @Param organization_mail query string true "Organization Mail"
-// @Success 200 {array} map[string]interface{}
+// @Param sort query string false "Comma-separated fields to sort by, - for descending"
+// @Param filter query []string false "field:op:value, with op one of eq, ne, lt, lte, gt, gte, in and like" collectionFormat(multi)
+// @Param limit query int false "Page size, 20 by default and at most 100"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} listing.Page{data=[]models.OpportunityWithApplicationCountResponse}
+// @Failure 400 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /opportunities [get]
 func getOpportunitiesByOrganization(c *gin.Context, db *gorm.DB) {
-	organizationMail := c.Query("organization_mail")
+	organizationMail := normalizeEmail(c.Query("organization_mail"))
 	if organizationMail == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "organization_id is required"})
+		middleware.RespondProblem(c, http.StatusBadRequest, "organization_id is required")
+		return
+	}
+
+	options, ok := parseListOptions(c, opportunityListSpec.With("application_count", listing.Field{Column: "application_count", Type: listing.Number, Computed: true}))
+	if !ok {
 		return
 	}
 
-	var opportunities []map[string]interface{}
+	query := options.Filter(db.Table("opportunities").Where("opportunities.organization_mail = ?", organizationMail))
+
+	// Count the opportunities before joining their applications
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		respondError(c, err)
+		return
+	}
+
+	opportunities := []models.OpportunityWithApplicationCountResponse{}
 
 	// Query to retrieve opportunities with application counts
-	if err := db.Table("opportunities").
+	if err := options.Paginate(query).
 		Select("opportunities.*, COUNT(applications.id) AS application_count").
 		Joins("LEFT JOIN applications ON applications.opportunity_id = opportunities.id").
-		Where("opportunities.organization_mail = ?", organizationMail).
 		Group("opportunities.id").
 		Find(&opportunities).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, opportunities)
+	c.JSON(http.StatusOK, options.Page(opportunities, total, c.Request.URL))
 }
 
-// func getOpportunitiesByOrganization(c *gin.Context, db *gorm.DB) {
-// 	stringOrgID := c.Query("organization_id")
-
-// 	if stringOrgID == "" {
-// 		c.JSON(http.StatusBadRequest, gin.H{"error": "organization_id is required"})
-// 		return
-// 	}
-
-// 	pageStr := c.DefaultQuery("page", "1")
-// 	pageSizeStr := c.DefaultQuery("page_size", "10")
-
-// 	page, err := strconv.Atoi(pageStr)
-// 	if err != nil || page < 1 {
-// 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
-// 		return
-// 	}
-
-// 	pageSize, err := strconv.Atoi(pageSizeStr)
-// 	if err != nil || pageSize < 1 {
-// 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page size"})
-// 		return
-// 	}
-
-// 	offset := (page - 1) * pageSize
-
-// 	var opportunities []models.Opportunity
-// 	if err := db.Table("opportunities").
-// 		Select("opportunities.*, COUNT(applications.id) AS application_count").
-// 		Joins("LEFT JOIN applications ON applications.opportunity_id = opportunities.id").
-// 		Where("opportunities.organization_mail = ?", stringOrgID).
-// 		Group("opportunities.id").
-// 		Offset(offset).
-// 		Limit(pageSize).
-// 		Find(&opportunities).Error; err != nil {
-// 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-// 		return
-// 	}
-
-// 	c.JSON(http.StatusOK, opportunities)
-// }
-
 // getAvailableOpportunities godoc
 // @Summary Retrieve available volunteer opportunities
-// @Description Retrieve available volunteer opportunities, excluding expired ones, and include organization name
+// @Description Retrieve available volunteer opportunities, excluding expired and hidden ones and those of suspended organizations, and include organization name.
+// @Description Given a point as lat and lng, or as the location of volunteer_id, each opportunity carries its distance_km, radius_km keeps the ones within that distance
+// @Description and sort=distance_km lists the nearest first. Remote opportunities are only kept within a radius with include_remote=true.
+// @Description Soonest first by default, and sortable and filterable by the opportunity fields and organization_name.
 // @Tags opportunities
 // @Accept json
 // @Produce json
-// @Success 200 {array} map[string]interface{}
+// @Param lat query number false "Latitude of the point to measure distances from"
+// @Param lng query number false "Longitude of the point to measure distances from"
+// @Param volunteer_id query uint false "Measure distances from this volunteer's location"
+// @Param radius_km query number false "Only opportunities within this distance"
+// @Param include_remote query bool false "Keep remote opportunities when filtering by radius"
+// @Param sort query string false "Comma-separated fields to sort by, - for descending"
+// @Param filter query []string false "field:op:value, with op one of eq, ne, lt, lte, gt, gte, in and like" collectionFormat(multi)
+// @Param limit query int false "Page size, 20 by default and at most 100"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} listing.Page{data=[]models.AvailableOpportunityResponse}
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /opportunities/available [get]
 func getAvailableOpportunities(c *gin.Context, db *gorm.DB) {
 	currentDate := time.Now()
 
-	var opportunities []map[string]interface{}
+	distance, ok := parseDistanceFilter(c, db)
+	if !ok {
+		return
+	}
+	options, ok := parseListOptions(c, distance.listSpec(availableOpportunityListSpec))
+	if !ok {
+		return
+	}
 
 	// Query to retrieve available opportunities
-	if err := db.Table("opportunities").
-		Select("opportunities.*, organizations.name AS organization_name").
+	query := db.Table("opportunities").
 		Joins("INNER JOIN organizations ON opportunities.organization_mail = organizations.email").
 		Where("opportunities.end_date >= ?", currentDate).
-		Order("opportunities.start_date ASC").
+		Where("opportunities.hidden_at IS NULL AND organizations.suspended_at IS NULL")
+	query = options.Filter(distance.where(query))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		respondError(c, err)
+		return
+	}
+
+	opportunities := []models.AvailableOpportunityResponse{}
+	distanceColumn, distanceArgs := distance.selectDistance()
+	if err := options.Paginate(query).
+		Select("opportunities.*, organizations.name AS organization_name"+distanceColumn, distanceArgs...).
 		Find(&opportunities).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, opportunities)
+	c.JSON(http.StatusOK, options.Page(opportunities, total, c.Request.URL))
 }
 
 // getOpportunityWithStats godoc
 // @Summary Get opportunity details with application statistics
-// @Description Retrieve details of a specific opportunity including counts of applications by status
+// @Description Retrieve details of a specific opportunity including counts of applications by status, and the fill rate of each of its shifts.
+// @Description The roster of each shift, naming the volunteers who hold its seats, is only included for the organization that posted the opportunity and for admins.
 // @Tags opportunities
 // @Accept json
 // @Produce json
 // @Param id path uint true "Opportunity ID"
-// @Success 200 {object} map[string]interface{}
-// @Failure 404 {object} map[string]string
+// @Success 200 {object} models.OpportunityStatsResponse
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /opportunities/{id} [get]
-func getOpportunityWithStats(c *gin.Context, db *gorm.DB) {
+func getOpportunityWithStats(c *gin.Context, db *gorm.DB, organizations store.OrganizationStore) {
 	opportunityID := c.Param("opportunity_id")
 
 	// Get the basic opportunity details
 	var opportunity models.Opportunity
 	if err := db.First(&opportunity, opportunityID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Opportunity not found"})
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
 		return
 	}
 
 	// Get application counts by status
+	var counts []struct {
+		Status string
+		Count  int64
+	}
+	if err := db.Model(&models.Application{}).
+		Select("status, COUNT(*) AS count").
+		Where("opportunity_id = ?", opportunityID).
+		Group("status").
+		Scan(&counts).Error; err != nil {
+		respondError(c, err)
+		return
+	}
+
 	var totalApplications int64
-	var pendingApplications int64
-	var acceptedApplications int64
-	var rejectedApplications int64
-
-	// Count total applications
-	db.Model(&models.Application{}).Where("opportunity_id = ?", opportunityID).Count(&totalApplications)
-
-	// Count pending applications
-	db.Model(&models.Application{}).Where("opportunity_id = ? AND status = ?", opportunityID, "Pending").Count(&pendingApplications)
-
-	// Count accepted applications
-	db.Model(&models.Application{}).Where("opportunity_id = ? AND status = ?", opportunityID, "Accepted").Count(&acceptedApplications)
-
-	// Count rejected applications
-	db.Model(&models.Application{}).Where("opportunity_id = ? AND status = ?", opportunityID, "Rejected").Count(&rejectedApplications)
-
-	// Create a map for the response
-	response := map[string]interface{}{
-		"id":                    opportunity.ID,
-		"organization_id":       opportunity.Organization_mail,
-		"title":                 opportunity.Title,
-		"description":           opportunity.Description,
-		"location":              opportunity.Location,
-		"hours_required":        opportunity.Hours_Required,
-		"start_date":            opportunity.Start_Date,
-		"end_date":              opportunity.End_Date,
-		"created_at":            opportunity.Created_At,
-		"updated_at":            opportunity.Updated_At,
-		"category":              opportunity.Category,
-		"total_applications":    totalApplications,
-		"pending_applications":  pendingApplications,
-		"accepted_applications": acceptedApplications,
-		"rejected_applications": rejectedApplications,
+	byStatus := map[string]int64{}
+	for _, count := range counts {
+		totalApplications += count.Count
+		byStatus[count.Status] = count.Count
+	}
+
+	// Only the organization that posted the opportunity and admins see who holds the seats
+	withRoster := true
+	if principal, _ := middleware.CurrentPrincipal(c); principal != nil && !middleware.IsAdmin(principal) {
+		email, err := organizationEmail(c.Request.Context(), organizations, principal)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		withRoster = ownsOpportunity(email, opportunity)
+	}
+
+	shifts, err := getShiftStats(db, opportunity.ID, withRoster)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// Build the response
+	response := models.OpportunityStatsResponse{
+		ID:                      opportunity.ID,
+		Organization_ID:         opportunity.Organization_mail,
+		Title:                   opportunity.Title,
+		Description:             opportunity.Description,
+		Location:                opportunity.Location,
+		Latitude:                opportunity.Latitude,
+		Longitude:               opportunity.Longitude,
+		Remote:                  opportunity.Remote,
+		Hours_Required:          opportunity.Hours_Required,
+		Capacity:                opportunity.Capacity,
+		Start_Date:              opportunity.Start_Date,
+		End_Date:                opportunity.End_Date,
+		Created_At:              opportunity.Created_At,
+		Updated_At:              opportunity.Updated_At,
+		Category:                opportunity.Category,
+		Total_Applications:      totalApplications,
+		Pending_Applications:    byStatus[models.ApplicationPending],
+		Waitlisted_Applications: byStatus[models.ApplicationWaitlisted],
+		Accepted_Applications:   byStatus[models.ApplicationAccepted],
+		Rejected_Applications:   byStatus[models.ApplicationRejected],
+		Withdrawn_Applications:  byStatus[models.ApplicationWithdrawn],
+		Completed_Applications:  byStatus[models.ApplicationCompleted],
+		No_Show_Applications:    byStatus[models.ApplicationNoShow],
+		Removed_Applications:    byStatus[models.ApplicationRemoved],
+		Shifts:                  shifts,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -1,10 +1,15 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/internal/geo"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/middleware"
 	"github.com/prathamrao021/HelperHub/models"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -16,28 +21,39 @@ import (
 // @Tags organizations
 // @Accept json
 // @Produce json
-// @Param organization body models.Organization true "Organization data"
-// @Success 200 {object} models.Organization
+// @Param organization body models.OrganizationCreateRequest true "Organization data"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
 // @Router /organizations/create [post]
-func createOrganization(c *gin.Context, db *gorm.DB) {
-	var organization models.Organization
-	if err := c.ShouldBindJSON(&organization); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func createOrganization(c *gin.Context, organizations store.OrganizationStore, geocoder geo.Geocoder, bcryptCost int) {
+	var request models.OrganizationCreateRequest
+	if !bindJSON(c, &request) {
 		return
 	}
 
 	// Hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(organization.Password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcryptCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		middleware.RespondProblem(c, http.StatusInternalServerError, "Failed to hash password")
 		return
 	}
-	organization.Password = string(hashedPassword)
-	organization.Created_At = time.Now()
-	organization.Updated_At = time.Now()
 
-	if err := db.Create(&organization).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	organization := models.Organization{
+		Email:       normalizeEmail(request.Email),
+		Password:    string(hashedPassword),
+		Name:        request.Name,
+		Phone:       request.Phone,
+		Location:    request.Location,
+		Description: request.Description,
+		Website_Url: request.Website_Url,
+		Created_At:  time.Now(),
+		Updated_At:  time.Now(),
+	}
+	organization.Latitude, organization.Longitude = geocodeLocation(c.Request.Context(), geocoder, organization.Location)
+
+	if err := organizations.Create(c.Request.Context(), &organization); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -51,20 +67,26 @@ func createOrganization(c *gin.Context, db *gorm.DB) {
 // @Accept json
 // @Produce json
 // @Param organization_mail path string true "Email"
-// @Success 200 {object} models.Organization
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /organizations/delete/{organization_mail} [delete]
-func deleteOrganization(c *gin.Context, db *gorm.DB) {
-	mail := c.Param("organization_mail")
-	var organization models.Organization
+func deleteOrganization(c *gin.Context, organizations store.OrganizationStore) {
+	mail := normalizeEmail(c.Param("organization_mail"))
 
-	if err := db.Where("email = ?", mail).First(&organization).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+	organization, err := organizations.GetByEmail(c.Request.Context(), mail)
+	if errors.Is(err, store.ErrNotFound) {
+		middleware.RespondProblem(c, http.StatusNotFound, "Organization not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := organizations.Delete(c.Request.Context(), organization.ID); err != nil {
+		respondError(c, err)
 		return
-	} else {
-		if err := db.Where("email = ?", mail).Delete(&models.Organization{}).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Organization data deleted successfully"})
@@ -72,45 +94,71 @@ func deleteOrganization(c *gin.Context, db *gorm.DB) {
 
 // updateOrganization godoc
 // @Summary Update an existing organization
-// @Description Update an existing organization by organization_mail
+// @Description Update an existing organization by organization_mail. Changing the email moves the opportunities of the organization to the new email.
 // @Tags organizations
 // @Accept json
 // @Produce json
 // @Param organization_mail path string true "Email"
-// @Param organization body models.Organization true "Organization data"
-// @Success 200 {object} models.Organization
+// @Param organization body models.OrganizationUpdateRequest true "Organization data"
+// @Success 200 {object} models.OrganizationResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /organizations/update/{organization_mail} [put]
-func updateOrganization(c *gin.Context, db *gorm.DB) {
-	mail := c.Param("organization_mail")
-	var organization models.Organization
+func updateOrganization(c *gin.Context, organizations store.OrganizationStore, geocoder geo.Geocoder, bcryptCost int) {
+	mail := normalizeEmail(c.Param("organization_mail"))
 
-	if err := db.Where("email = ?", mail).First(&organization).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	organization, err := organizations.GetByEmail(c.Request.Context(), mail)
+	if errors.Is(err, store.ErrNotFound) {
+		middleware.RespondProblem(c, http.StatusNotFound, "Organization not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	var updatedOrganization map[string]interface{}
-	if err := c.ShouldBindJSON(&updatedOrganization); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var request models.OrganizationUpdateRequest
+	if !bindJSON(c, &request) {
 		return
 	}
 
-	if password, exists := updatedOrganization["password"]; exists && password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password.(string)), bcrypt.DefaultCost)
+	if request.Email != nil {
+		organization.Email = normalizeEmail(*request.Email)
+	}
+	if request.Name != nil {
+		organization.Name = *request.Name
+	}
+	if request.Phone != nil {
+		organization.Phone = *request.Phone
+	}
+	if request.Location != nil {
+		organization.Location = *request.Location
+		organization.Latitude, organization.Longitude = geocodeLocation(c.Request.Context(), geocoder, *request.Location)
+	}
+	if request.Description != nil {
+		organization.Description = *request.Description
+	}
+	if request.Website_Url != nil {
+		organization.Website_Url = *request.Website_Url
+	}
+	if request.Password != nil && *request.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*request.Password), bcryptCost)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			middleware.RespondProblem(c, http.StatusInternalServerError, "Failed to hash password")
 			return
 		}
-		updatedOrganization["password"] = string(hashedPassword)
+		organization.Password = string(hashedPassword)
 	}
-	updatedOrganization["updated_at"] = time.Now()
+	organization.Updated_At = time.Now()
 
-	if err := db.Model(&organization).Updates(&updatedOrganization).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := organizations.Update(c.Request.Context(), &organization); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, organization)
+	c.JSON(http.StatusOK, models.NewOrganizationResponse(organization))
 }
 
 // getOrganization godoc
@@ -120,44 +168,61 @@ func updateOrganization(c *gin.Context, db *gorm.DB) {
 // @Accept json
 // @Produce json
 // @Param organization_mail path string true "Email"
-// @Success 200 {object} models.Organization
+// @Success 200 {object} models.OrganizationResponse
+// @Security BearerAuth
 // @Router /organizations/get/{organization_mail} [get]
-func getOrganization(c *gin.Context, db *gorm.DB) {
-	mail := c.Param("organization_mail")
+func getOrganization(c *gin.Context, organizations store.OrganizationStore) {
+	mail := normalizeEmail(c.Param("organization_mail"))
 
-	var organization models.Organization
-	if err := db.Where("email = ?", mail).First(&organization).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	organization, err := organizations.GetByEmail(c.Request.Context(), mail)
+	if errors.Is(err, store.ErrNotFound) {
+		middleware.RespondProblem(c, http.StatusNotFound, "Organization not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, organization)
+	c.JSON(http.StatusOK, models.NewOrganizationResponse(organization))
 }
 
 // loginOrganization godoc
 // @Summary Login an organization
-// @Description Login an organization with the provided credentials
+// @Description Login an organization with the provided credentials and issue an access token and a refresh token
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param credentials body models.LoginRequest true "Login credentials"
-// @Success 200 {object} models.Organization
+// @Success 200 {object} models.LoginResponse
+// @Failure 403 {object} middleware.Problem
 // @Router /login/organization [post]
-func loginOrganization(c *gin.Context, db *gorm.DB) {
+func loginOrganization(c *gin.Context, db *gorm.DB, tokens *auth.TokenManager) {
 	var credentials models.LoginRequest
-	if err := c.ShouldBindJSON(&credentials); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &credentials) {
 		return
 	}
 
 	var organization models.Organization
-	if err := db.Where("email = ?", credentials.Email).First(&organization).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+	if err := db.Where("email = ?", normalizeEmail(credentials.Email)).First(&organization).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(organization.Password), []byte(credentials.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		middleware.RespondProblem(c, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"user": organization})
+
+	if organization.Suspended_At != nil {
+		middleware.RespondProblem(c, http.StatusForbidden, "Account suspended")
+		return
+	}
+
+	session, err := issueSession(db, tokens, auth.Principal{ID: organization.ID, Email: organization.Email, Role: auth.RoleOrganization})
+	if err != nil {
+		middleware.RespondProblem(c, http.StatusInternalServerError, "Failed to create session")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LoginResponse{SessionResponse: session, User: models.NewOrganizationResponse(organization)})
 }
@@ -10,12 +10,11 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/store"
 	"github.com/prathamrao021/HelperHub/models"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 // LoginRequestOrganization struct for testing
@@ -26,25 +25,14 @@ type LoginRequestOrganization struct {
 }
 
 func setupTestDBForOrganization() *gorm.DB {
-	// Use the same PostgreSQL connection as in main.go but with a test database
-	dsn := "host=localhost user=postgres password=admin dbname=Helperhub_test port=5432 sslmode=prefer TimeZone=Asia/Shanghai"
-
-	// Configure gorm with minimal logging during tests
-	config := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-	}
-
-	// Open connection to PostgreSQL
-	db, err := gorm.Open(postgres.Open(dsn), config)
+	// Connect to the test database configured like the real one
+	db, err := openTestDB()
 	if err != nil {
 		panic("Failed to connect to test database: " + err.Error())
 	}
 
-	// Clean up existing tables
-	db.Exec("DROP TABLE IF EXISTS organizations CASCADE")
-
-	// Auto migrate required models
-	db.AutoMigrate(&models.Organization{})
+	// Start from an empty schema
+	resetTestDB(db)
 
 	return db
 }
@@ -52,22 +40,23 @@ func setupTestDBForOrganization() *gorm.DB {
 func setupRouterForOrganization(db *gorm.DB) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	stores := store.NewGorm(db)
 
 	// Register routes with injected database
 	r.POST("/organizations/create", func(c *gin.Context) {
-		createOrganization(c, db)
+		createOrganization(c, stores.Organizations, testGeocoder, testBcryptCost)
 	})
 	r.DELETE("/organizations/delete/:organization_mail", func(c *gin.Context) {
-		deleteOrganization(c, db)
+		deleteOrganization(c, stores.Organizations)
 	})
 	r.PUT("/organizations/update/:organization_mail", func(c *gin.Context) {
-		updateOrganization(c, db)
+		updateOrganization(c, stores.Organizations, testGeocoder, testBcryptCost)
 	})
 	r.GET("/organizations/get/:organization_mail", func(c *gin.Context) {
-		getOrganization(c, db)
+		getOrganization(c, stores.Organizations)
 	})
 	r.POST("/login/organization", func(c *gin.Context) {
-		loginOrganization(c, db)
+		loginOrganization(c, db, newTestTokenManager())
 	})
 
 	return r
@@ -111,7 +100,7 @@ func TestCreateOrganization(t *testing.T) {
 	defer cleanupTestOrganizations(db)
 
 	// Test organization data
-	organization := models.Organization{
+	organization := models.OrganizationCreateRequest{
 		Email:       "new@org.com",
 		Password:    "password123",
 		Name:        "New Organization",
@@ -186,6 +175,7 @@ func TestGetOrganization(t *testing.T) {
 	assert.Equal(t, org.Location, response.Location)
 	assert.Equal(t, org.Description, response.Description)
 	assert.Equal(t, org.Website_Url, response.Website_Url)
+	assert.NotContains(t, w.Body.String(), "password", "Response should not expose the password hash")
 }
 
 func TestUpdateOrganization(t *testing.T) {
@@ -235,6 +225,59 @@ func TestUpdateOrganization(t *testing.T) {
 	assert.Equal(t, org.Password, updatedInDB.Password)
 }
 
+func TestUpdateOrganizationEmailKeepsOpportunities(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForOrganization(db)
+	defer cleanupTestOpportunities(db)
+
+	opportunity := createTestOpportunity(db)
+
+	w := sendJSON(router, "PUT", "/organizations/update/test@org.com", map[string]interface{}{"email": "renamed@org.com"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The opportunities of the organization move to its new email
+	var moved models.Opportunity
+	assert.NoError(t, db.First(&moved, opportunity.ID).Error)
+	assert.Equal(t, "renamed@org.com", moved.Organization_mail)
+	assert.True(t, ownsOpportunity("renamed@org.com", moved))
+}
+
+func TestOrganizationEmailsIgnoreCase(t *testing.T) {
+	db := setupTestDBForOrganization()
+	router := setupRouterForOrganization(db)
+	defer cleanupTestOrganizations(db)
+
+	organization := createTestOrganization(db)
+
+	// An email that only differs in case names the same account
+	w := sendJSON(router, "POST", "/organizations/create", models.OrganizationCreateRequest{
+		Email:       "TEST@org.com",
+		Password:    "password123",
+		Name:        "Impostor Organization",
+		Phone:       "5555555555",
+		Location:    "Test Location",
+		Description: "Test Description",
+		Website_Url: "https://impostor.org",
+	})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = sendJSON(router, "POST", "/login/organization", models.LoginRequest{Email: "Test@Org.com", Password: "testpassword", Role: "organization"})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = sendJSON(router, "PUT", "/organizations/update/TEST@org.com", map[string]interface{}{"email": "Renamed@Org.com"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	var renamed models.Organization
+	assert.NoError(t, db.First(&renamed, organization.ID).Error)
+	assert.Equal(t, "renamed@org.com", renamed.Email)
+
+	// The database refuses such emails even when they are written directly
+	impostor := organization
+	impostor.ID = 0
+	impostor.Email = "RENAMED@org.com"
+	impostor.Name = "Impostor Organization"
+	assert.Error(t, db.Create(&impostor).Error)
+}
+
 func TestUpdateOrganizationWithPassword(t *testing.T) {
 	db := setupTestDBForOrganization()
 	router := setupRouterForOrganization(db)
@@ -351,6 +394,11 @@ func TestLoginOrganization(t *testing.T) {
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err, "Response should be valid JSON")
 
+	// Verify the response carries a session
+	assert.NotEmpty(t, response["access_token"], "Response should contain an access token")
+	assert.NotEmpty(t, response["refresh_token"], "Response should contain a refresh token")
+	assert.Equal(t, "Bearer", response["token_type"])
+
 	// Verify the response has user data
 	userMap, exists := response["user"]
 	assert.True(t, exists, "Response should contain user data")
@@ -455,7 +503,7 @@ func TestGetNonExistentOrganization(t *testing.T) {
 	t.Logf("Response Body: %s", w.Body.String())
 
 	// Assertions
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
 func TestInvalidOrganizationData(t *testing.T) {
@@ -498,7 +546,7 @@ func TestUpdateNonExistentOrganization(t *testing.T) {
 	t.Logf("Response Body: %s", w.Body.String())
 
 	// Assertions
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
 func TestInvalidLoginData(t *testing.T) {
@@ -0,0 +1,123 @@
+package routes
+
+import (
+	"sort"
+
+	"github.com/prathamrao021/HelperHub/internal/matching"
+	"github.com/prathamrao021/HelperHub/models"
+	"gorm.io/gorm"
+)
+
+// Candidate ranking orders the applicants of an opportunity by how well they fit it: whether
+// they volunteer in its category, how many hours organizations verified for them, how often they
+// completed the work they were accepted for, and whether they have the hours it needs.
+
+// Sort modes of an opportunity's applicant list
+const (
+	applicantSortCreatedAt = "created_at"
+	applicantSortRank      = "rank"
+)
+
+// Weights of the candidate ranking factors
+const (
+	candidateCategoryWeight       = 0.3
+	candidateVerifiedHoursWeight  = 0.25
+	candidateCompletionRateWeight = 0.25
+	candidateHoursWeight          = 0.2
+)
+
+// candidateRecord is what a volunteer's past applications say about them. Applications to the
+// opportunity being ranked do not count.
+type candidateRecord struct {
+	Volunteer_ID   uint
+	Verified_Hours float64
+	Completed      int64
+	Concluded      int64
+}
+
+func loadCandidateRecords(db *gorm.DB, opportunityID uint, volunteerIDs []uint) (map[uint]*candidateRecord, error) {
+	records := map[uint]*candidateRecord{}
+	for _, id := range volunteerIDs {
+		records[id] = &candidateRecord{Volunteer_ID: id}
+	}
+
+	var hours []candidateRecord
+	if err := db.Table("time_entries").
+		Select("applications.volunteer_id, SUM(time_entries.hours) AS verified_hours").
+		Joins("join applications on time_entries.application_id = applications.id").
+		Where("applications.volunteer_id IN ? AND applications.opportunity_id <> ?", volunteerIDs, opportunityID).
+		Where("time_entries.status = ?", models.TimeEntryApproved).
+		Group("applications.volunteer_id").
+		Scan(&hours).Error; err != nil {
+		return nil, err
+	}
+	for _, h := range hours {
+		records[h.Volunteer_ID].Verified_Hours = h.Verified_Hours
+	}
+
+	var outcomes []candidateRecord
+	if err := db.Table("applications").
+		Select("volunteer_id, SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS completed, COUNT(*) AS concluded", models.ApplicationCompleted).
+		Where("volunteer_id IN ? AND opportunity_id <> ?", volunteerIDs, opportunityID).
+		Where("status IN ?", models.ConcludedApplicationStatuses).
+		Group("volunteer_id").
+		Scan(&outcomes).Error; err != nil {
+		return nil, err
+	}
+	for _, o := range outcomes {
+		records[o.Volunteer_ID].Completed = o.Completed
+		records[o.Volunteer_ID].Concluded = o.Concluded
+	}
+
+	return records, nil
+}
+
+// rankApplicants scores the applicants of an opportunity and sorts them best first. Equal
+// scores keep the earliest application first.
+func rankApplicants(db *gorm.DB, opportunity models.Opportunity, applicants []models.ApplicationWithVolunteerResponse) error {
+	if len(applicants) == 0 {
+		return nil
+	}
+
+	volunteerIDs := make([]uint, 0, len(applicants))
+	for _, a := range applicants {
+		volunteerIDs = append(volunteerIDs, a.Volunteer_ID)
+	}
+
+	var volunteers []models.Volunteer
+	if err := db.Where("id IN ?", volunteerIDs).Find(&volunteers).Error; err != nil {
+		return err
+	}
+	volunteersByID := map[uint]models.Volunteer{}
+	for _, v := range volunteers {
+		volunteersByID[v.ID] = v
+	}
+
+	records, err := loadCandidateRecords(db, opportunity.ID, volunteerIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range applicants {
+		volunteer := volunteersByID[applicants[i].Volunteer_ID]
+		record := records[applicants[i].Volunteer_ID]
+
+		match := matching.Combine(
+			matching.CategoryFactor(volunteer.Category_List, opportunity.Category, candidateCategoryWeight),
+			matching.VerifiedHoursFactor(record.Verified_Hours, candidateVerifiedHoursWeight),
+			matching.CompletionRateFactor(record.Completed, record.Concluded, candidateCompletionRateWeight),
+			matching.HoursFactor(volunteer.Available_Hours, opportunity.Hours_Required, candidateHoursWeight),
+		)
+		score := match.Score
+		applicants[i].Score = &score
+		applicants[i].Factors = models.NewMatchFactorResponses(match.Factors)
+	}
+
+	sort.SliceStable(applicants, func(i, j int) bool {
+		if *applicants[i].Score != *applicants[j].Score {
+			return *applicants[i].Score > *applicants[j].Score
+		}
+		return applicants[i].Created_At.Before(applicants[j].Created_At)
+	})
+	return nil
+}
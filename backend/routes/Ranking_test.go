@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupRouterForRanking(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+
+	r.POST("/applications", func(c *gin.Context) {
+		createApplication(c, db)
+	})
+	r.GET("/applications/opportunity/:opportunity_id", func(c *gin.Context) {
+		getApplicationsByOpportunityWithVolunteerDetails(c, db)
+	})
+
+	return r
+}
+
+func TestRankApplicants(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForRanking(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createTestOpportunity(db)
+	volunteers := createCapacityTestVolunteers(db, 2)
+	db.Model(&volunteers[1]).Updates(map[string]interface{}{
+		"category_list":   models.StringList{"Education"},
+		"available_hours": 10,
+	})
+
+	first := applyForOpportunity(t, router, volunteers[0].ID, opp.ID)
+	second := applyForOpportunity(t, router, volunteers[1].ID, opp.ID)
+
+	// The default order is unchanged and carries no scores
+	w := sendJSON(router, "GET", fmt.Sprintf("/applications/opportunity/%d", opp.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var applicants []models.ApplicationWithVolunteerResponse
+	json.Unmarshal(w.Body.Bytes(), &applicants)
+	assert.Len(t, applicants, 2)
+	assert.Nil(t, applicants[0].Score)
+
+	// Ranked, the volunteer in the opportunity's category with enough hours comes first
+	w = sendJSON(router, "GET", fmt.Sprintf("/applications/opportunity/%d?sort=rank", opp.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &applicants)
+	assert.Len(t, applicants, 2)
+	assert.Equal(t, second.ID, applicants[0].ID)
+	assert.Equal(t, first.ID, applicants[1].ID)
+	assert.Greater(t, *applicants[0].Score, *applicants[1].Score)
+	assert.Len(t, applicants[0].Factors, 4)
+	for _, factor := range applicants[0].Factors {
+		if factor.Name == "category" {
+			assert.Equal(t, 1.0, factor.Score)
+		}
+	}
+
+	w = sendJSON(router, "GET", fmt.Sprintf("/applications/opportunity/%d?sort=name", opp.ID), nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
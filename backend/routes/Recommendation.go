@@ -0,0 +1,183 @@
+package routes
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/geo"
+	"github.com/prathamrao021/HelperHub/internal/matching"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"github.com/prathamrao021/HelperHub/models"
+	"gorm.io/gorm"
+)
+
+// Recommendations rank the open opportunities a volunteer has not applied to yet by how well
+// they fit the volunteer's categories, location, availability and past applications.
+
+// Weights of the recommendation factors. Category matters most since it is what volunteers
+// pick when they sign up.
+const (
+	recommendationCategoryWeight = 0.4
+	recommendationLocationWeight = 0.25
+	recommendationHoursWeight    = 0.2
+	recommendationHistoryWeight  = 0.15
+)
+
+const (
+	defaultRecommendationLimit = 10
+	maxRecommendationLimit     = 50
+)
+
+// volunteerHistory counts a volunteer's past applications by organization and category
+type volunteerHistory struct {
+	completedByOrganization map[string]int64
+	appliedByCategory       map[string]int64
+}
+
+func loadVolunteerHistory(db *gorm.DB, volunteerID uint) (volunteerHistory, error) {
+	history := volunteerHistory{
+		completedByOrganization: map[string]int64{},
+		appliedByCategory:       map[string]int64{},
+	}
+
+	var completed []struct {
+		Organization_Mail string
+		Count             int64
+	}
+	if err := db.Table("applications").
+		Select("opportunities.organization_mail, COUNT(*) AS count").
+		Joins("join opportunities on applications.opportunity_id = opportunities.id").
+		Where("applications.volunteer_id = ? AND applications.status = ?", volunteerID, models.ApplicationCompleted).
+		Group("opportunities.organization_mail").
+		Scan(&completed).Error; err != nil {
+		return history, err
+	}
+	for _, c := range completed {
+		history.completedByOrganization[c.Organization_Mail] = c.Count
+	}
+
+	var applied []struct {
+		Category string
+		Count    int64
+	}
+	if err := db.Table("applications").
+		Select("opportunities.category, COUNT(*) AS count").
+		Joins("join opportunities on applications.opportunity_id = opportunities.id").
+		Where("applications.volunteer_id = ?", volunteerID).
+		Group("opportunities.category").
+		Scan(&applied).Error; err != nil {
+		return history, err
+	}
+	for _, a := range applied {
+		history.appliedByCategory[a.Category] = a.Count
+	}
+
+	return history, nil
+}
+
+// matchOpportunity scores how well an opportunity fits a volunteer. Locations are compared by
+// distance when both are geocoded, and by name otherwise.
+func matchOpportunity(volunteer models.Volunteer, history volunteerHistory, opportunity models.AvailableOpportunityResponse) matching.Match {
+	location := matching.LocationFactor(volunteer.Location, opportunity.Location, recommendationLocationWeight)
+	switch {
+	case opportunity.Remote:
+		location = matching.RemoteFactor(recommendationLocationWeight)
+	case volunteer.Latitude != nil && volunteer.Longitude != nil && opportunity.Latitude != nil && opportunity.Longitude != nil:
+		km := geo.DistanceKm(
+			geo.Point{Latitude: *volunteer.Latitude, Longitude: *volunteer.Longitude},
+			geo.Point{Latitude: *opportunity.Latitude, Longitude: *opportunity.Longitude},
+		)
+		location = matching.DistanceFactor(km, recommendationLocationWeight)
+	}
+
+	return matching.Combine(
+		matching.CategoryFactor(volunteer.Category_List, opportunity.Category, recommendationCategoryWeight),
+		location,
+		matching.HoursFactor(volunteer.Available_Hours, opportunity.Hours_Required, recommendationHoursWeight),
+		matching.HistoryFactor(
+			history.completedByOrganization[opportunity.Organization_mail],
+			history.appliedByCategory[opportunity.Category],
+			opportunity.Organization_Name,
+			opportunity.Category,
+			recommendationHistoryWeight,
+		),
+	)
+}
+
+// getVolunteerRecommendations godoc
+// @Summary Recommend opportunities to a volunteer
+// @Description Rank the open opportunities the volunteer has not applied to by category overlap, location proximity, hours fit and past application history.
+// @Description Every recommendation carries its score between 0 and 1, the reasons it matched and the score of each factor.
+// @Description Only the volunteer and admins can see them.
+// @Tags volunteers
+// @Accept json
+// @Produce json
+// @Param volunteer_id path uint true "Volunteer ID"
+// @Param limit query int false "Number of recommendations, 10 by default and at most 50"
+// @Success 200 {array} models.RecommendationResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /volunteers/{volunteer_id}/recommendations [get]
+func getVolunteerRecommendations(c *gin.Context, db *gorm.DB) {
+	limit := defaultRecommendationLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 {
+			middleware.RespondProblem(c, http.StatusBadRequest, "Invalid number of recommendations")
+			return
+		}
+		limit = min(n, maxRecommendationLimit)
+	}
+
+	var volunteer models.Volunteer
+	if err := db.Where("id = ?", c.Param("volunteer_id")).First(&volunteer).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Volunteer not found")
+		return
+	}
+
+	history, err := loadVolunteerHistory(db, volunteer.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// The same opportunities as getAvailableOpportunities, minus the ones already applied to
+	candidates := []models.AvailableOpportunityResponse{}
+	if err := db.Table("opportunities").
+		Select("opportunities.*, organizations.name AS organization_name").
+		Joins("INNER JOIN organizations ON opportunities.organization_mail = organizations.email").
+		Where("opportunities.end_date >= ?", time.Now()).
+		Where("opportunities.hidden_at IS NULL AND organizations.suspended_at IS NULL").
+		Where("opportunities.id NOT IN (?)", db.Table("applications").Select("opportunity_id").Where("volunteer_id = ?", volunteer.ID)).
+		Order("opportunities.start_date ASC").
+		Find(&candidates).Error; err != nil {
+		respondError(c, err)
+		return
+	}
+
+	recommendations := make([]models.RecommendationResponse, 0, len(candidates))
+	for _, candidate := range candidates {
+		match := matchOpportunity(volunteer, history, candidate)
+		recommendations = append(recommendations, models.RecommendationResponse{
+			AvailableOpportunityResponse: candidate,
+			Score:                        match.Score,
+			Reasons:                      match.Reasons(),
+			Factors:                      models.NewMatchFactorResponses(match.Factors),
+		})
+	}
+
+	// Equal scores keep the soonest opportunity first
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Score > recommendations[j].Score
+	})
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+
+	c.JSON(http.StatusOK, recommendations)
+}
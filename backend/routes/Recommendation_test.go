@@ -0,0 +1,81 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupRouterForRecommendations(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+
+	r.POST("/applications", func(c *gin.Context) {
+		createApplication(c, db)
+	})
+	r.GET("/volunteers/:volunteer_id/recommendations", func(c *gin.Context) {
+		getVolunteerRecommendations(c, db)
+	})
+
+	return r
+}
+
+func getTestRecommendations(t *testing.T, router *gin.Engine, volunteerID uint, query string) []models.RecommendationResponse {
+	w := sendJSON(router, "GET", fmt.Sprintf("/volunteers/%d/recommendations%s", volunteerID, query), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response []models.RecommendationResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response
+}
+
+func TestVolunteerRecommendations(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForRecommendations(db)
+	defer cleanupCapacityTest(db)
+
+	volunteers := createCapacityTestVolunteers(db, 1)
+	volunteer := volunteers[0]
+	db.Model(&volunteer).Updates(map[string]interface{}{
+		"category_list":   models.StringList{"Health"},
+		"location":        "Test Location",
+		"available_hours": 10,
+	})
+
+	education := createTestOpportunity(db)
+	health := createTestOpportunity(db)
+	db.Model(&health).Updates(map[string]interface{}{"category": "Health", "title": "Health Opportunity"})
+	applied := createTestOpportunity(db)
+	applyForOpportunity(t, router, volunteer.ID, applied.ID)
+
+	recommendations := getTestRecommendations(t, router, volunteer.ID, "")
+	assert.Len(t, recommendations, 2)
+
+	// The opportunity in one of the volunteer's categories comes first, and says why
+	assert.Equal(t, health.ID, recommendations[0].ID)
+	assert.Equal(t, education.ID, recommendations[1].ID)
+	assert.Greater(t, recommendations[0].Score, recommendations[1].Score)
+	assert.Contains(t, recommendations[0].Reasons, "Matches the Health category")
+	assert.Len(t, recommendations[0].Factors, 4)
+	assert.Equal(t, "Test Organization", recommendations[0].Organization_Name)
+
+	// The education opportunity gets a history boost from the earlier education application
+	for _, factor := range recommendations[1].Factors {
+		if factor.Name == "history" {
+			assert.Equal(t, 0.5, factor.Score)
+		}
+	}
+
+	assert.Len(t, getTestRecommendations(t, router, volunteer.ID, "?limit=1"), 1)
+
+	w := sendJSON(router, "GET", fmt.Sprintf("/volunteers/%d/recommendations?limit=zero", volunteer.ID), nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = sendJSON(router, "GET", "/volunteers/0/recommendations", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,276 @@
+package routes
+
+import (
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/database"
+	"github.com/prathamrao021/HelperHub/internal/listing"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"github.com/prathamrao021/HelperHub/models"
+	"gorm.io/gorm"
+)
+
+// Opportunity search. Keywords are matched with PostgreSQL full-text search against the
+// opportunity's indexed search_vector column, which the database keeps up to date from the
+// title, category and description, or against the organization's name. Matches in the title
+// count the most, then the category and organization, then the description.
+//
+// The headlines are built by the database with markers around the matched words. The text is
+// escaped as HTML before the markers are turned into <mark> tags, so opportunities cannot put
+// markup in the results.
+//
+// On SQLite the same columns are matched through the opportunity_search full-text table, which
+// stems words the same way but has no stop words, and the keywords are translated to its query
+// syntax.
+
+const (
+	searchQuery = "websearch_to_tsquery('english', ?)"
+	// The organization is matched on its own, so the index on search_vector can be used
+	searchMatch = "(opportunities.search_vector @@ " + searchQuery + " OR opportunities.organization_mail IN " +
+		"(SELECT email FROM organizations WHERE to_tsvector('english', name) @@ " + searchQuery + "))"
+	// Ranking only runs on the matches, and counts the organization's name with the category
+	searchRankVector = "(opportunities.search_vector || setweight(to_tsvector('english', coalesce(organizations.name, '')), 'B'))"
+
+	// Markers the database puts around matched words in headlines, from the private use area so
+	// they do not turn up in text
+	headlineStart = "\uE000"
+	headlineStop  = "\uE001"
+
+	titleHeadlineOptions       = `StartSel="` + headlineStart + `", StopSel="` + headlineStop + `", HighlightAll=true`
+	descriptionHeadlineOptions = `StartSel="` + headlineStart + `", StopSel="` + headlineStop + `", MinWords=15, MaxWords=35, MaxFragments=2`
+
+	sqliteSearchJoin          = "INNER JOIN opportunity_search ON opportunity_search.docid = opportunities.id"
+	sqliteSearchRank          = "search_rank(matchinfo(opportunity_search, 'pcx'), 1.0, 0.4, 0.2, 0.4)"
+	sqliteTitleHeadline       = "snippet(opportunity_search, '" + headlineStart + "', '" + headlineStop + "', '', 0, 64)"
+	sqliteDescriptionHeadline = "snippet(opportunity_search, '" + headlineStart + "', '" + headlineStop + "', ' ... ', 2, 35)"
+)
+
+var headlineMarks = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+
+// highlightHeadline escapes a headline from the database as HTML and wraps its matched words in
+// <mark> tags
+func highlightHeadline(headline string) string {
+	return headlineMarks.Replace(html.EscapeString(headline))
+}
+
+// ftsQuery translates keywords in the syntax of websearch_to_tsquery, with quoted phrases, OR
+// and -word, to an SQLite full-text query. It returns "" when nothing is left to match, as
+// when every keyword is excluded.
+func ftsQuery(text string) string {
+	var groups [][]string // Terms joined by OR, the groups joined by AND
+	var excluded []string
+	or := false
+
+	for rest := strings.TrimSpace(text); rest != ""; rest = strings.TrimSpace(rest) {
+		negated := strings.HasPrefix(rest, "-")
+		if negated {
+			rest = rest[1:]
+		}
+
+		var raw string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				raw, rest = rest[1:], ""
+			} else {
+				raw, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+			if end < 0 {
+				end = len(rest)
+			}
+			raw, rest = rest[:end], rest[end:]
+			if !negated && strings.EqualFold(raw, "or") && len(groups) > 0 {
+				or = true
+				continue
+			}
+		}
+
+		// Terms are quoted as phrases so that punctuation in them is not read as syntax
+		words := strings.FieldsFunc(raw, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) })
+		if len(words) == 0 {
+			continue
+		}
+		term := `"` + strings.Join(words, " ") + `"`
+
+		switch {
+		case negated:
+			excluded = append(excluded, term)
+		case or:
+			groups[len(groups)-1] = append(groups[len(groups)-1], term)
+		default:
+			groups = append(groups, []string{term})
+		}
+		or = false
+	}
+
+	parts := make([]string, len(groups))
+	for i, group := range groups {
+		parts[i] = strings.Join(group, " OR ")
+		if len(group) > 1 {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	expression := strings.Join(parts, " AND ")
+	if expression == "" {
+		return ""
+	}
+	for _, term := range excluded {
+		expression += " NOT " + term
+	}
+	return expression
+}
+
+// searchOpportunities godoc
+// @Summary Search open opportunities
+// @Description Search the open opportunities by keyword over the title, description, category and organization name, best matches first,
+// @Description with the matched words highlighted. Without q, every open opportunity matching the filters is returned, soonest first.
+// @Description Dates are YYYY-MM-DD; from and to keep the opportunities that run at some point in between.
+// @Description The distance parameters work as they do for /opportunities/available, and sort=distance_km lists the nearest matches first.
+// @Description Results can also be sorted and filtered like /opportunities/available, and by rank when searching by keyword.
+// @Tags opportunities
+// @Accept json
+// @Produce json
+// @Param q query string false "Keywords. Quoted phrases, OR and -word are supported."
+// @Param category query string false "Category"
+// @Param location query string false "Part of the location"
+// @Param from query string false "Earliest date"
+// @Param to query string false "Latest date"
+// @Param min_hours query int false "Minimum hours required"
+// @Param max_hours query int false "Maximum hours required"
+// @Param lat query number false "Latitude of the point to measure distances from"
+// @Param lng query number false "Longitude of the point to measure distances from"
+// @Param volunteer_id query uint false "Measure distances from this volunteer's location"
+// @Param radius_km query number false "Only opportunities within this distance"
+// @Param include_remote query bool false "Keep remote opportunities when filtering by radius"
+// @Param sort query string false "Comma-separated fields to sort by, - for descending"
+// @Param filter query []string false "field:op:value, with op one of eq, ne, lt, lte, gt, gte, in and like" collectionFormat(multi)
+// @Param limit query int false "Page size, 20 by default and at most 100"
+// @Param offset query int false "Number of results to skip"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} listing.Page{data=[]models.OpportunitySearchResult}
+// @Failure 400 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /opportunities/search [get]
+func searchOpportunities(c *gin.Context, db *gorm.DB) {
+	distance, ok := parseDistanceFilter(c, db)
+	if !ok {
+		return
+	}
+
+	// Keyword searches rank the best matches first
+	text := strings.TrimSpace(c.Query("q"))
+	spec := distance.listSpec(availableOpportunityListSpec)
+	if text != "" {
+		spec = spec.With("rank", listing.Field{Column: "rank", Type: listing.Number, Computed: true})
+		spec.DefaultSort = "-rank"
+	}
+	options, ok := parseListOptions(c, spec)
+	if !ok {
+		return
+	}
+
+	query := db.Table("opportunities").
+		Joins("INNER JOIN organizations ON opportunities.organization_mail = organizations.email").
+		Where("opportunities.end_date >= ?", time.Now()).
+		Where("opportunities.hidden_at IS NULL AND organizations.suspended_at IS NULL")
+
+	sqlite := database.IsSQLite(db)
+	if text != "" {
+		if sqlite {
+			query = query.Joins(sqliteSearchJoin).Where("opportunity_search MATCH ?", ftsQuery(text))
+		} else {
+			query = query.Where(searchMatch, text, text)
+		}
+	}
+
+	if category := strings.TrimSpace(c.Query("category")); category != "" {
+		query = query.Where("LOWER(opportunities.category) = LOWER(?)", category)
+	}
+	if location := strings.TrimSpace(c.Query("location")); location != "" {
+		query = query.Where("LOWER(opportunities.location) LIKE LOWER(?)", "%"+location+"%")
+	}
+
+	for _, param := range []string{"from", "to"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(customDateLayout, value); err != nil {
+			middleware.RespondProblem(c, http.StatusBadRequest, "Invalid "+param+" date, expected YYYY-MM-DD")
+			return
+		}
+		// Passed as text, which both databases compare with their dates
+		if param == "from" {
+			query = query.Where("opportunities.end_date >= ?", value)
+		} else {
+			query = query.Where("opportunities.start_date <= ?", value)
+		}
+	}
+
+	for _, param := range []string{"min_hours", "max_hours"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		hours, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			middleware.RespondProblem(c, http.StatusBadRequest, "Invalid "+param)
+			return
+		}
+		if param == "min_hours" {
+			query = query.Where("opportunities.hours_required >= ?", hours)
+		} else {
+			query = query.Where("opportunities.hours_required <= ?", hours)
+		}
+	}
+
+	// Count and fetch the page from the same filters
+	query = options.Filter(distance.where(query))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		respondError(c, err)
+		return
+	}
+
+	distanceColumn, distanceArgs := distance.selectDistance()
+	switch {
+	case text != "" && sqlite:
+		query = query.
+			Select("opportunities.*, organizations.name AS organization_name, "+
+				sqliteSearchRank+" AS rank, "+
+				sqliteTitleHeadline+" AS title_headline, "+
+				sqliteDescriptionHeadline+" AS description_headline"+distanceColumn, distanceArgs...)
+	case text != "":
+		query = query.
+			Select("opportunities.*, organizations.name AS organization_name, "+
+				"ts_rank("+searchRankVector+", "+searchQuery+") AS rank, "+
+				"ts_headline('english', opportunities.title, "+searchQuery+", ?) AS title_headline, "+
+				"ts_headline('english', opportunities.description, "+searchQuery+", ?) AS description_headline"+distanceColumn,
+				append([]interface{}{text, text, titleHeadlineOptions, text, descriptionHeadlineOptions}, distanceArgs...)...)
+	default:
+		query = query.Select("opportunities.*, organizations.name AS organization_name"+distanceColumn, distanceArgs...)
+	}
+
+	results := []models.OpportunitySearchResult{}
+	if err := options.Paginate(query).Find(&results).Error; err != nil {
+		respondError(c, err)
+		return
+	}
+	for i := range results {
+		results[i].Title_Headline = highlightHeadline(results[i].Title_Headline)
+		results[i].Description_Headline = highlightHeadline(results[i].Description_Headline)
+	}
+
+	c.JSON(http.StatusOK, options.Page(results, total, c.Request.URL))
+}
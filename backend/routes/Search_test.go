@@ -0,0 +1,115 @@
+package routes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/listing"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupRouterForSearch(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+
+	r.GET("/opportunities/search", func(c *gin.Context) {
+		searchOpportunities(c, db)
+	})
+
+	return r
+}
+
+func searchTestOpportunities(t *testing.T, router *gin.Engine, query string) ([]models.OpportunitySearchResult, listing.Page) {
+	w := sendJSON(router, "GET", "/opportunities/search"+query, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	results := []models.OpportunitySearchResult{}
+	page := decodePage(t, w, &results)
+	return results, page
+}
+
+func TestSearchOpportunities(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForSearch(db)
+	defer cleanupTestOpportunities(db)
+
+	garden := createTestOpportunity(db)
+	db.Model(&garden).Updates(map[string]interface{}{
+		"title":       "Community Garden Cleanup",
+		"description": "Help us plant vegetables and weed the community garden beds.",
+		"category":    "Environment",
+	})
+	tutoring := createTestOpportunity(db)
+	db.Model(&tutoring).Updates(map[string]interface{}{
+		"title":          "After School Tutoring",
+		"description":    "Tutor students in math after school. Gardening experience not needed.",
+		"hours_required": 20,
+	})
+
+	// The title match ranks above the description match, and matched words are highlighted
+	results, page := searchTestOpportunities(t, router, "?q=gardens")
+	assert.Equal(t, int64(2), page.Total)
+	assert.Len(t, results, 2)
+	assert.Equal(t, garden.ID, results[0].ID)
+	assert.Greater(t, results[0].Rank, results[1].Rank)
+	assert.Contains(t, results[0].Title_Headline, "<mark>Garden</mark>")
+	assert.Contains(t, results[1].Description_Headline, "<mark>Gardening</mark>")
+
+	// The organization name is searched too
+	_, page = searchTestOpportunities(t, router, "?q=organization")
+	assert.Equal(t, int64(2), page.Total)
+
+	// Filters narrow the matches
+	results, page = searchTestOpportunities(t, router, "?q=garden&category=education")
+	assert.Equal(t, int64(1), page.Total)
+	assert.Equal(t, tutoring.ID, results[0].ID)
+	_, page = searchTestOpportunities(t, router, "?min_hours=10")
+	assert.Equal(t, int64(1), page.Total)
+	_, page = searchTestOpportunities(t, router, "?filter=hours_required:gte:10")
+	assert.Equal(t, int64(1), page.Total)
+	results, page = searchTestOpportunities(t, router, "?q=tutoring&location=nowhere")
+	assert.Equal(t, int64(0), page.Total)
+	assert.Empty(t, results)
+
+	// Pages share the total
+	results, page = searchTestOpportunities(t, router, "?q=gardens&limit=1&offset=1")
+	assert.Equal(t, int64(2), page.Total)
+	assert.Len(t, results, 1)
+	assert.Equal(t, tutoring.ID, results[0].ID)
+	assert.Empty(t, page.Next)
+
+	// Headlines are escaped, so only the <mark> tags are markup
+	db.Model(&tutoring).Update("title", `Tutoring <img src=x onerror="alert(1)">`)
+	results, _ = searchTestOpportunities(t, router, "?q=tutoring")
+	assert.Len(t, results, 1)
+	assert.Equal(t, `<mark>Tutoring</mark> &lt;img src=x onerror=&#34;alert(1)&#34;&gt;`, results[0].Title_Headline)
+
+	w := sendJSON(router, "GET", "/opportunities/search?from=tomorrow", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = sendJSON(router, "GET", "/opportunities/search?sort=rank", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFTSQuery(t *testing.T) {
+	tests := map[string]string{
+		"garden":                        `"garden"`,
+		"community garden":              `"community" AND "garden"`,
+		`"community garden"`:            `"community garden"`,
+		"garden or park volunteers":     `("garden" OR "park") AND "volunteers"`,
+		"garden -weekend":               `"garden" NOT "weekend"`,
+		`garden -"food bank"`:           `"garden" NOT "food bank"`,
+		"covid-19 (testing)":            `"covid 19" AND "testing"`,
+		"-garden":                       "",
+		"or":                            `"or"`,
+		`unterminated "community garde`: `"unterminated" AND "community garde"`,
+	}
+
+	for text, expected := range tests {
+		t.Run(text, func(t *testing.T) {
+			assert.Equal(t, expected, ftsQuery(text))
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"github.com/prathamrao021/HelperHub/models"
+	"gorm.io/gorm"
+)
+
+// Shifts split an opportunity into dated time slots, each with its own capacity. Volunteers
+// apply to a shift rather than to the whole opportunity once it has any.
+
+const (
+	shiftTimeFormat  = "15:04"
+	customDateLayout = "2006-01-02"
+)
+
+var errShiftDecided = errors.New("Only shifts whose applications are all Pending or Waitlisted can be deleted")
+
+// validateShift checks that a shift has a date within its opportunity and a start time before
+// its end time
+func validateShift(opportunity models.Opportunity, shift models.Shift) error {
+	if shift.Date.ToTime().IsZero() {
+		return errors.New("date is required")
+	}
+
+	start, err := time.Parse(shiftTimeFormat, shift.Start_Time)
+	if err != nil {
+		return errors.New("start_time must be in HH:MM format")
+	}
+	end, err := time.Parse(shiftTimeFormat, shift.End_Time)
+	if err != nil {
+		return errors.New("end_time must be in HH:MM format")
+	}
+	if !end.After(start) {
+		return errors.New("end_time must be after start_time")
+	}
+
+	// Dates are compared as YYYY-MM-DD strings so the time zones they were parsed in do not matter
+	date := shift.Date.ToTime().Format(customDateLayout)
+	if date < opportunity.Start_Date.ToTime().Format(customDateLayout) || date > opportunity.End_Date.ToTime().Format(customDateLayout) {
+		return errors.New("Shift date must be within the opportunity's start and end dates")
+	}
+	return nil
+}
+
+// createShift godoc
+// @Summary Add a shift to an opportunity
+// @Description Add a dated time slot with its own capacity to an opportunity. The date must be within the opportunity's start and end dates and times use the 24-hour HH:MM format.
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param opportunity_id path uint true "Opportunity ID"
+// @Param shift body models.ShiftCreateRequest true "Shift data"
+// @Success 200 {object} models.ShiftResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /opportunities/{opportunity_id}/shifts [post]
+func createShift(c *gin.Context, db *gorm.DB) {
+	var opportunity models.Opportunity
+	if err := db.Where("id = ?", c.Param("opportunity_id")).First(&opportunity).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		return
+	}
+
+	var request models.ShiftCreateRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	shift := models.Shift{
+		Opportunity_ID: opportunity.ID,
+		Date:           request.Date,
+		Start_Time:     request.Start_Time,
+		End_Time:       request.End_Time,
+		Capacity:       request.Capacity,
+		Created_At:     time.Now(),
+		Updated_At:     time.Now(),
+	}
+	if err := validateShift(opportunity, shift); err != nil {
+		middleware.RespondProblem(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := db.Create(&shift).Error; err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewShiftResponse(shift))
+}
+
+// getShifts godoc
+// @Summary List the shifts of an opportunity
+// @Description Retrieve the shifts of an opportunity ordered by date and start time
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param opportunity_id path uint true "Opportunity ID"
+// @Success 200 {array} models.ShiftResponse
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /opportunities/{opportunity_id}/shifts [get]
+func getShifts(c *gin.Context, db *gorm.DB) {
+	var opportunity models.Opportunity
+	if err := db.Where("id = ?", c.Param("opportunity_id")).First(&opportunity).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		return
+	}
+
+	var shifts []models.Shift
+	if err := db.Where("opportunity_id = ?", opportunity.ID).Order("date ASC, start_time ASC, id ASC").Find(&shifts).Error; err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewShiftResponses(shifts))
+}
+
+// updateShift godoc
+// @Summary Update a shift
+// @Description Update the date, times or capacity of a shift. Raising the capacity accepts waitlisted applications for the shift.
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param opportunity_id path uint true "Opportunity ID"
+// @Param shift_id path uint true "Shift ID"
+// @Param shift body models.ShiftUpdateRequest true "Shift data"
+// @Success 200 {object} models.ShiftResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /opportunities/{opportunity_id}/shifts/{shift_id} [put]
+func updateShift(c *gin.Context, db *gorm.DB) {
+	var shift models.Shift
+	if err := db.Where("id = ? AND opportunity_id = ?", c.Param("shift_id"), c.Param("opportunity_id")).First(&shift).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Shift not found")
+		return
+	}
+
+	var request models.ShiftUpdateRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	updatedShift := map[string]interface{}{}
+	if request.Date != nil {
+		shift.Date = *request.Date
+		updatedShift["date"] = *request.Date
+	}
+	if request.Start_Time != nil {
+		shift.Start_Time = *request.Start_Time
+		updatedShift["start_time"] = *request.Start_Time
+	}
+	if request.End_Time != nil {
+		shift.End_Time = *request.End_Time
+		updatedShift["end_time"] = *request.End_Time
+	}
+	if request.Capacity != nil {
+		shift.Capacity = *request.Capacity
+		updatedShift["capacity"] = *request.Capacity
+	}
+	updatedShift["updated_at"] = time.Now()
+
+	var opportunity models.Opportunity
+	if err := db.Where("id = ?", shift.Opportunity_ID).First(&opportunity).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		return
+	}
+	if err := validateShift(opportunity, shift); err != nil {
+		middleware.RespondProblem(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		locked, err := lockOpportunity(tx, shift.Opportunity_ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Shift{}).Where("id = ?", shift.ID).Updates(updatedShift).Error; err != nil {
+			return err
+		}
+
+		// A larger capacity opens seats for the waitlist
+		if request.Capacity != nil {
+			return promoteWaitlisted(tx, locked)
+		}
+		return nil
+	}); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := db.First(&shift, shift.ID).Error; err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewShiftResponse(shift))
+}
+
+// deleteShift godoc
+// @Summary Delete a shift
+// @Description Delete a shift together with the applications made for it. Seats freed on the opportunity go to its waitlist.
+// @Description Shifts with applications that have been decided on, such as accepted or completed ones, cannot be deleted, so their history, hours and attendance are kept.
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param opportunity_id path uint true "Opportunity ID"
+// @Param shift_id path uint true "Shift ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /opportunities/{opportunity_id}/shifts/{shift_id} [delete]
+func deleteShift(c *gin.Context, db *gorm.DB) {
+	var shift models.Shift
+	if err := db.Where("id = ? AND opportunity_id = ?", c.Param("shift_id"), c.Param("opportunity_id")).First(&shift).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Shift not found")
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		opportunity, err := lockOpportunity(tx, shift.Opportunity_ID)
+		if err != nil {
+			return err
+		}
+
+		// Decided applications are kept like the ones deleteApplication refuses to delete
+		var decided int64
+		if err := tx.Model(&models.Application{}).
+			Where("shift_id = ? AND status NOT IN ?", shift.ID, models.DeletableApplicationStatuses).
+			Count(&decided).Error; err != nil {
+			return err
+		}
+		if decided > 0 {
+			return errShiftDecided
+		}
+
+		// The undecided applications for the shift and their history go with it
+		if err := tx.Where("id = ?", shift.ID).Delete(&models.Shift{}).Error; err != nil {
+			return err
+		}
+		return promoteWaitlisted(tx, opportunity)
+	})
+	if err == errShiftDecided {
+		middleware.RespondProblem(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Shift deleted successfully"})
+}
+
+// getShiftStats returns every shift of an opportunity with its waitlist size, its fill rate and,
+// when withRoster is set, its roster of volunteers holding a seat
+func getShiftStats(db *gorm.DB, opportunityID uint, withRoster bool) ([]models.ShiftStatsResponse, error) {
+	var shifts []models.Shift
+	if err := db.Where("opportunity_id = ?", opportunityID).Order("date ASC, start_time ASC, id ASC").Find(&shifts).Error; err != nil {
+		return nil, err
+	}
+	stats := make([]models.ShiftStatsResponse, 0, len(shifts))
+	if len(shifts) == 0 {
+		return stats, nil
+	}
+
+	var roster []struct {
+		Shift_ID uint
+		models.ShiftRosterEntry
+	}
+	if err := db.Table("applications").
+		Select(`
+            applications.shift_id,
+            applications.id AS application_id,
+            applications.volunteer_id,
+            volunteers.name AS volunteer_name,
+            volunteers.email AS volunteer_email,
+            applications.status
+        `).
+		Joins("INNER JOIN volunteers ON applications.volunteer_id = volunteers.id").
+		Where("applications.opportunity_id = ? AND applications.shift_id IS NOT NULL AND applications.status IN ?", opportunityID, models.ApprovedApplicationStatuses).
+		Order("applications.created_at ASC, applications.id ASC").
+		Scan(&roster).Error; err != nil {
+		return nil, err
+	}
+
+	var waitlisted []struct {
+		Shift_ID uint
+		Count    int64
+	}
+	if err := db.Model(&models.Application{}).
+		Select("shift_id, COUNT(*) AS count").
+		Where("opportunity_id = ? AND shift_id IS NOT NULL AND status = ?", opportunityID, models.ApplicationWaitlisted).
+		Group("shift_id").
+		Scan(&waitlisted).Error; err != nil {
+		return nil, err
+	}
+
+	rosterByShift := map[uint][]models.ShiftRosterEntry{}
+	for _, entry := range roster {
+		rosterByShift[entry.Shift_ID] = append(rosterByShift[entry.Shift_ID], entry.ShiftRosterEntry)
+	}
+	waitlistedByShift := map[uint]int64{}
+	for _, count := range waitlisted {
+		waitlistedByShift[count.Shift_ID] = count.Count
+	}
+
+	for _, shift := range shifts {
+		entries := rosterByShift[shift.ID]
+		if entries == nil {
+			entries = []models.ShiftRosterEntry{}
+		}
+		stat := models.ShiftStatsResponse{
+			ShiftResponse:           models.NewShiftResponse(shift),
+			Filled_Seats:            int64(len(entries)),
+			Waitlisted_Applications: waitlistedByShift[shift.ID],
+		}
+		if withRoster {
+			stat.Roster = entries
+		}
+		if shift.Capacity > 0 {
+			fillRate := float64(len(entries)) / float64(shift.Capacity)
+			stat.Fill_Rate = &fillRate
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
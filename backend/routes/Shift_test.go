@@ -0,0 +1,253 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupRouterForShifts(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	stores := store.NewGorm(db)
+
+	r.POST("/opportunities/:opportunity_id/shifts", func(c *gin.Context) {
+		createShift(c, db)
+	})
+	r.GET("/opportunities/:opportunity_id/shifts", func(c *gin.Context) {
+		getShifts(c, db)
+	})
+	r.PUT("/opportunities/:opportunity_id/shifts/:shift_id", func(c *gin.Context) {
+		updateShift(c, db)
+	})
+	r.DELETE("/opportunities/:opportunity_id/shifts/:shift_id", func(c *gin.Context) {
+		deleteShift(c, db)
+	})
+	r.GET("/opportunities/:opportunity_id", func(c *gin.Context) {
+		getOpportunityWithStats(c, db, stores.Organizations)
+	})
+	r.POST("/applications", func(c *gin.Context) {
+		createApplication(c, db)
+	})
+	r.PUT("/applications/:id", func(c *gin.Context) {
+		updateApplication(c, db)
+	})
+
+	return r
+}
+
+func createTestShift(t *testing.T, router *gin.Engine, opportunityID uint, daysFromNow int, start string, end string, capacity uint) models.ShiftResponse {
+	w := sendJSON(router, "POST", fmt.Sprintf("/opportunities/%d/shifts", opportunityID), models.ShiftCreateRequest{
+		Date:       models.CustomDate(time.Now().AddDate(0, 0, daysFromNow)),
+		Start_Time: start,
+		End_Time:   end,
+		Capacity:   capacity,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.ShiftResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response
+}
+
+func applyForShift(router *gin.Engine, volunteerID uint, opportunityID uint, shiftID *uint) (int, models.ApplicationResponse) {
+	w := sendJSON(router, "POST", "/applications", models.ApplicationCreateRequest{
+		Volunteer_ID:   volunteerID,
+		Opportunity_ID: opportunityID,
+		Shift_ID:       shiftID,
+		Cover_Letter:   "Shift test",
+	})
+
+	var response models.ApplicationResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w.Code, response
+}
+
+func TestCreateAndListShifts(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForShifts(db)
+	defer cleanupTestOpportunities(db)
+
+	opp := createTestOpportunity(db)
+
+	afternoon := createTestShift(t, router, opp.ID, 2, "13:00", "17:00", 3)
+	morning := createTestShift(t, router, opp.ID, 2, "09:00", "12:00", 5)
+	assert.Equal(t, opp.ID, afternoon.Opportunity_ID)
+
+	w := sendJSON(router, "GET", fmt.Sprintf("/opportunities/%d/shifts", opp.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var shifts []models.ShiftResponse
+	json.Unmarshal(w.Body.Bytes(), &shifts)
+	assert.Len(t, shifts, 2)
+	assert.Equal(t, morning.ID, shifts[0].ID)
+	assert.Equal(t, afternoon.ID, shifts[1].ID)
+
+	// Times must be valid and in order
+	w = sendJSON(router, "POST", fmt.Sprintf("/opportunities/%d/shifts", opp.ID), models.ShiftCreateRequest{
+		Date:       models.CustomDate(time.Now().AddDate(0, 0, 2)),
+		Start_Time: "17:00",
+		End_Time:   "13:00",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = sendJSON(router, "POST", fmt.Sprintf("/opportunities/%d/shifts", opp.ID), models.ShiftCreateRequest{
+		Date:       models.CustomDate(time.Now().AddDate(0, 0, 2)),
+		Start_Time: "9am",
+		End_Time:   "13:00",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// Shifts must fall within the opportunity
+	w = sendJSON(router, "POST", fmt.Sprintf("/opportunities/%d/shifts", opp.ID), models.ShiftCreateRequest{
+		Date:       models.CustomDate(time.Now().AddDate(0, 0, 60)),
+		Start_Time: "09:00",
+		End_Time:   "12:00",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = sendJSON(router, "GET", "/opportunities/99999/shifts", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestApplyToShift(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForShifts(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createTestOpportunity(db)
+	volunteers := createCapacityTestVolunteers(db, 1)
+	morning := createTestShift(t, router, opp.ID, 2, "09:00", "12:00", 0)
+	afternoon := createTestShift(t, router, opp.ID, 2, "13:00", "17:00", 0)
+
+	// Once an opportunity has shifts, applications must name one
+	code, _ := applyForShift(router, volunteers[0].ID, opp.ID, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	code, application := applyForShift(router, volunteers[0].ID, opp.ID, &morning.ID)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, morning.ID, *application.Shift_ID)
+
+	// A volunteer can apply to each shift once
+	code, _ = applyForShift(router, volunteers[0].ID, opp.ID, &morning.ID)
+	assert.Equal(t, http.StatusConflict, code)
+	code, _ = applyForShift(router, volunteers[0].ID, opp.ID, &afternoon.ID)
+	assert.Equal(t, http.StatusOK, code)
+
+	// The shift has to belong to the opportunity
+	other := createTestOpportunity(db)
+	code, _ = applyForShift(router, volunteers[0].ID, other.ID, &morning.ID)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestShiftCapacityAndStats(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForShifts(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createTestOpportunity(db)
+	volunteers := createCapacityTestVolunteers(db, 3)
+	shift := createTestShift(t, router, opp.ID, 3, "09:00", "12:00", 1)
+	open := createTestShift(t, router, opp.ID, 4, "09:00", "12:00", 0)
+
+	_, first := applyForShift(router, volunteers[0].ID, opp.ID, &shift.ID)
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, first.ID, models.ApplicationAccepted))
+
+	// The shift is full, but other shifts of the opportunity are not
+	_, second := applyForShift(router, volunteers[1].ID, opp.ID, &shift.ID)
+	assert.Equal(t, models.ApplicationWaitlisted, second.Status)
+	_, third := applyForShift(router, volunteers[2].ID, opp.ID, &open.ID)
+	assert.Equal(t, models.ApplicationPending, third.Status)
+
+	w := sendJSON(router, "GET", fmt.Sprintf("/opportunities/%d", opp.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var stats models.OpportunityStatsResponse
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	assert.Len(t, stats.Shifts, 2)
+	assert.Equal(t, shift.ID, stats.Shifts[0].ID)
+	assert.Equal(t, int64(1), stats.Shifts[0].Filled_Seats)
+	assert.Equal(t, int64(1), stats.Shifts[0].Waitlisted_Applications)
+	assert.Equal(t, 1.0, *stats.Shifts[0].Fill_Rate)
+	assert.Len(t, stats.Shifts[0].Roster, 1)
+	assert.Equal(t, volunteers[0].Email, stats.Shifts[0].Roster[0].Volunteer_Email)
+	assert.Nil(t, stats.Shifts[1].Fill_Rate)
+	assert.Len(t, stats.Shifts[1].Roster, 0)
+
+	// Raising the shift capacity accepts the waitlisted volunteer
+	w = sendJSON(router, "PUT", fmt.Sprintf("/opportunities/%d/shifts/%d", opp.ID, shift.ID), map[string]interface{}{"capacity": 2})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.ApplicationAccepted, applicationStatus(db, second.ID))
+}
+
+func TestShiftRosterOnlyForOwner(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForShifts(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createTestOpportunity(db)
+	volunteers := createCapacityTestVolunteers(db, 1)
+	shift := createTestShift(t, router, opp.ID, 3, "09:00", "12:00", 2)
+	_, application := applyForShift(router, volunteers[0].ID, opp.ID, &shift.ID)
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, application.ID, models.ApplicationAccepted))
+
+	// Everyone sees how full the shift is, but only the organization and admins see who is on it
+	principals := map[*auth.Principal]bool{
+		testOrganizationPrincipal(db, opp.Organization_mail):                         true,
+		{Email: "admin@helperhub.com", Role: auth.RoleAdmin}:                         true,
+		testOrganizationPrincipal(db, "test2@org.com"):                               false,
+		{ID: volunteers[0].ID, Email: volunteers[0].Email, Role: auth.RoleVolunteer}: false,
+	}
+	organizations := store.NewGorm(db).Organizations
+	for principal, withRoster := range principals {
+		r := gin.New()
+		r.Use(withPrincipal(principal))
+		r.GET("/opportunities/:opportunity_id", func(c *gin.Context) { getOpportunityWithStats(c, db, organizations) })
+
+		w := sendJSON(r, "GET", fmt.Sprintf("/opportunities/%d", opp.ID), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var stats models.OpportunityStatsResponse
+		json.Unmarshal(w.Body.Bytes(), &stats)
+		if assert.Len(t, stats.Shifts, 1) {
+			assert.Equal(t, int64(1), stats.Shifts[0].Filled_Seats)
+			assert.Equal(t, withRoster, len(stats.Shifts[0].Roster) == 1, principal.Email)
+		}
+		assert.Equal(t, withRoster, strings.Contains(w.Body.String(), volunteers[0].Email), principal.Email)
+	}
+}
+
+func TestDeleteShiftKeepsDecidedApplications(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForShifts(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createTestOpportunity(db)
+	volunteers := createCapacityTestVolunteers(db, 2)
+	decided := createTestShift(t, router, opp.ID, 3, "09:00", "12:00", 0)
+	undecided := createTestShift(t, router, opp.ID, 4, "09:00", "12:00", 0)
+	_, accepted := applyForShift(router, volunteers[0].ID, opp.ID, &decided.ID)
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, accepted.ID, models.ApplicationAccepted))
+	_, pending := applyForShift(router, volunteers[1].ID, opp.ID, &undecided.ID)
+
+	// A shift someone was accepted for is kept with its application
+	w := sendJSON(router, "DELETE", fmt.Sprintf("/opportunities/%d/shifts/%d", opp.ID, decided.ID), nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, models.ApplicationAccepted, applicationStatus(db, accepted.ID))
+
+	// A shift with only undecided applications is deleted with them
+	w = sendJSON(router, "DELETE", fmt.Sprintf("/opportunities/%d/shifts/%d", opp.ID, undecided.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var count int64
+	db.Model(&models.Application{}).Where("id = ?", pending.ID).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
@@ -0,0 +1,358 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"github.com/prathamrao021/HelperHub/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Hour logging. Volunteers log the hours they worked against an accepted application and the
+// organization that posted the opportunity approves or disputes them. Disputed entries can be
+// corrected by the volunteer, which sends them back for review; approved entries are final.
+
+var (
+	errTimeEntryApproved = errors.New("Approved time entries cannot be changed")
+	errTooManyHours      = errors.New("hours logged for one work_date cannot add up to more than 24")
+)
+
+// validateTimeEntry checks the date and hours of a time entry against its opportunity
+func validateTimeEntry(opportunity models.Opportunity, entry models.TimeEntry) error {
+	if entry.Hours <= 0 || entry.Hours > models.MaxHoursPerEntry {
+		return errors.New("hours must be greater than 0 and at most 24")
+	}
+
+	workDate := entry.Work_Date.ToTime()
+	if workDate.IsZero() {
+		return errors.New("work_date is required")
+	}
+
+	// Dates are compared as YYYY-MM-DD strings so the time zones they were parsed in do not matter
+	date := workDate.Format(customDateLayout)
+	if date > time.Now().Format(customDateLayout) {
+		return errors.New("work_date cannot be in the future")
+	}
+	if date < opportunity.Start_Date.ToTime().Format(customDateLayout) {
+		return errors.New("work_date cannot be before the opportunity starts")
+	}
+	if date > opportunity.End_Date.ToTime().Format(customDateLayout) {
+		return errors.New("work_date cannot be after the opportunity ends")
+	}
+	return nil
+}
+
+// checkDailyHours returns errTooManyHours when a time entry would take the hours its volunteer
+// logged for its date, on any of their applications, over models.MaxHoursPerDay. It locks the
+// volunteer's row, so that entries logged at the same time are counted one after the other.
+func checkDailyHours(tx *gorm.DB, entry models.TimeEntry) error {
+	var application models.Application
+	if err := tx.Where("id = ?", entry.Application_ID).First(&application).Error; err != nil {
+		return err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", application.Volunteer_ID).First(&models.Volunteer{}).Error; err != nil {
+		return err
+	}
+
+	// The entry itself is left out, since a correction replaces its hours
+	var logged float64
+	if err := tx.Table("time_entries").
+		Select("COALESCE(SUM(time_entries.hours), 0)").
+		Joins("INNER JOIN applications ON applications.id = time_entries.application_id").
+		Where("applications.volunteer_id = ? AND time_entries.work_date = ? AND time_entries.id <> ?", application.Volunteer_ID, entry.Work_Date, entry.ID).
+		Scan(&logged).Error; err != nil {
+		return err
+	}
+	if logged+entry.Hours > models.MaxHoursPerDay {
+		return errTooManyHours
+	}
+	return nil
+}
+
+// logHours godoc
+// @Summary Log hours worked
+// @Description Log hours worked on an accepted application. The entry stays Pending until the organization approves or disputes it.
+// @Description The work date must be within the opportunity's dates and not in the future, and the volunteer's entries for one date cannot add up to more than 24 hours.
+// @Tags hours
+// @Accept json
+// @Produce json
+// @Param id path uint true "Application ID"
+// @Param entry body models.TimeEntryCreateRequest true "Time entry data"
+// @Success 200 {object} models.TimeEntryResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /applications/{id}/hours [post]
+func logHours(c *gin.Context, db *gorm.DB) {
+	var application models.Application
+	if err := db.Where("id = ?", c.Param("id")).First(&application).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Application not found")
+		return
+	}
+
+	var request models.TimeEntryCreateRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	if !models.HoldsSeat(application.Status) {
+		middleware.RespondProblem(c, http.StatusConflict, "Hours can only be logged for accepted applications")
+		return
+	}
+
+	var opportunity models.Opportunity
+	if err := db.Where("id = ?", application.Opportunity_ID).First(&opportunity).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		return
+	}
+
+	entry := models.TimeEntry{
+		Application_ID: application.ID,
+		Work_Date:      request.Work_Date,
+		Hours:          request.Hours,
+		Note:           request.Note,
+		Status:         models.TimeEntryPending,
+		Created_At:     time.Now(),
+		Updated_At:     time.Now(),
+	}
+	if err := validateTimeEntry(opportunity, entry); err != nil {
+		middleware.RespondProblem(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := checkDailyHours(tx, entry); err != nil {
+			return err
+		}
+		return tx.Create(&entry).Error
+	})
+	if err == errTooManyHours {
+		middleware.RespondProblem(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewTimeEntryResponse(entry))
+}
+
+// getApplicationHours godoc
+// @Summary List the hours logged on an application
+// @Description Retrieve every time entry of an application ordered by work date
+// @Tags hours
+// @Accept json
+// @Produce json
+// @Param id path uint true "Application ID"
+// @Success 200 {array} models.TimeEntryResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /applications/{id}/hours [get]
+func getApplicationHours(c *gin.Context, db *gorm.DB) {
+	var application models.Application
+	if err := db.Where("id = ?", c.Param("id")).First(&application).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Application not found")
+		return
+	}
+
+	var entries []models.TimeEntry
+	if err := db.Where("application_id = ?", application.ID).Order("work_date ASC, id ASC").Find(&entries).Error; err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewTimeEntryResponses(entries))
+}
+
+// updateTimeEntry godoc
+// @Summary Correct a time entry
+// @Description Correct the date, hours or note of a pending or disputed time entry. The entry goes back to Pending for review. The same limits apply as when logging hours.
+// @Tags hours
+// @Accept json
+// @Produce json
+// @Param id path uint true "Time entry ID"
+// @Param entry body models.TimeEntryUpdateRequest true "Time entry data"
+// @Success 200 {object} models.TimeEntryResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /hours/{id} [put]
+func updateTimeEntry(c *gin.Context, db *gorm.DB) {
+	var entry models.TimeEntry
+	if err := db.Where("id = ?", c.Param("id")).First(&entry).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Time entry not found")
+		return
+	}
+
+	var request models.TimeEntryUpdateRequest
+	if !bindJSON(c, &request) {
+		return
+	}
+
+	if entry.Status == models.TimeEntryApproved {
+		middleware.RespondProblem(c, http.StatusConflict, errTimeEntryApproved.Error())
+		return
+	}
+
+	updatedEntry := map[string]interface{}{}
+	if request.Work_Date != nil {
+		entry.Work_Date = *request.Work_Date
+		updatedEntry["work_date"] = *request.Work_Date
+	}
+	if request.Hours != nil {
+		entry.Hours = *request.Hours
+		updatedEntry["hours"] = *request.Hours
+	}
+	if request.Note != nil {
+		updatedEntry["note"] = *request.Note
+	}
+	updatedEntry["status"] = models.TimeEntryPending
+	updatedEntry["updated_at"] = time.Now()
+
+	var opportunity models.Opportunity
+	if err := db.Table("opportunities").
+		Select("opportunities.*").
+		Joins("INNER JOIN applications ON applications.opportunity_id = opportunities.id").
+		Where("applications.id = ?", entry.Application_ID).
+		First(&opportunity).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Opportunity not found")
+		return
+	}
+	if err := validateTimeEntry(opportunity, entry); err != nil {
+		middleware.RespondProblem(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := checkDailyHours(tx, entry); err != nil {
+			return err
+		}
+
+		// Only apply the correction if the entry was not approved in the meantime
+		result := tx.Model(&models.TimeEntry{}).
+			Where("id = ? AND status <> ?", entry.ID, models.TimeEntryApproved).
+			Updates(updatedEntry)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errTimeEntryApproved
+		}
+		return nil
+	})
+	if err == errTooManyHours {
+		middleware.RespondProblem(c, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err == errTimeEntryApproved {
+		middleware.RespondProblem(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if err := db.First(&entry, entry.ID).Error; err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewTimeEntryResponse(entry))
+}
+
+// approveTimeEntry godoc
+// @Summary Approve a time entry
+// @Description Approve a pending or disputed time entry, counting its hours as verified
+// @Tags hours
+// @Accept json
+// @Produce json
+// @Param id path uint true "Time entry ID"
+// @Param review body models.TimeEntryReviewRequest false "Review note"
+// @Success 200 {object} models.TimeEntryResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /hours/{id}/approve [post]
+func approveTimeEntry(c *gin.Context, db *gorm.DB) {
+	reviewTimeEntry(c, db, models.TimeEntryApproved, []string{models.TimeEntryPending, models.TimeEntryDisputed})
+}
+
+// disputeTimeEntry godoc
+// @Summary Dispute a time entry
+// @Description Dispute a pending time entry. The note telling the volunteer what is wrong is required.
+// @Tags hours
+// @Accept json
+// @Produce json
+// @Param id path uint true "Time entry ID"
+// @Param review body models.TimeEntryReviewRequest true "Review note"
+// @Success 200 {object} models.TimeEntryResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 409 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /hours/{id}/dispute [post]
+func disputeTimeEntry(c *gin.Context, db *gorm.DB) {
+	reviewTimeEntry(c, db, models.TimeEntryDisputed, []string{models.TimeEntryPending})
+}
+
+func reviewTimeEntry(c *gin.Context, db *gorm.DB, status string, from []string) {
+	var entry models.TimeEntry
+	if err := db.Where("id = ?", c.Param("id")).First(&entry).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Time entry not found")
+		return
+	}
+
+	var request models.TimeEntryReviewRequest
+	if c.Request.ContentLength > 0 {
+		if !bindJSON(c, &request) {
+			return
+		}
+	}
+	if status == models.TimeEntryDisputed && request.Note == "" {
+		middleware.RespondProblem(c, http.StatusUnprocessableEntity, "A note explaining the dispute is required")
+		return
+	}
+
+	updates := map[string]interface{}{
+		"status":      status,
+		"review_note": request.Note,
+		"reviewed_at": time.Now(),
+		"updated_at":  time.Now(),
+	}
+	if principal, _ := middleware.CurrentPrincipal(c); principal != nil {
+		updates["reviewer_id"] = principal.ID
+	}
+
+	// Only apply the review if nobody else reviewed the entry in the meantime
+	result := db.Model(&models.TimeEntry{}).
+		Where("id = ? AND status IN ?", entry.ID, from).
+		Updates(updates)
+	if result.Error != nil {
+		respondError(c, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		middleware.RespondProblem(c, http.StatusConflict, "Cannot change time entry status from "+entry.Status+" to "+status)
+		return
+	}
+
+	if err := db.First(&entry, entry.ID).Error; err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewTimeEntryResponse(entry))
+}
@@ -0,0 +1,204 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupRouterForTimeEntries(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+
+	r.POST("/applications", func(c *gin.Context) {
+		createApplication(c, db)
+	})
+	r.PUT("/applications/:id", func(c *gin.Context) {
+		updateApplication(c, db)
+	})
+	r.POST("/applications/:id/hours", func(c *gin.Context) {
+		logHours(c, db)
+	})
+	r.GET("/applications/:id/hours", func(c *gin.Context) {
+		getApplicationHours(c, db)
+	})
+	r.PUT("/hours/:id", func(c *gin.Context) {
+		updateTimeEntry(c, db)
+	})
+	r.POST("/hours/:id/approve", func(c *gin.Context) {
+		approveTimeEntry(c, db)
+	})
+	r.POST("/hours/:id/dispute", func(c *gin.Context) {
+		disputeTimeEntry(c, db)
+	})
+	r.GET("/volunteers/:volunteer_id/stats", func(c *gin.Context) {
+		getVolunteerStats(c, db)
+	})
+
+	return r
+}
+
+// createStartedTestOpportunity creates an opportunity that started a week ago, so hours can be logged on it
+func createStartedTestOpportunity(db *gorm.DB) models.Opportunity {
+	opp := createTestOpportunity(db)
+	opp.Start_Date = models.CustomDate(time.Now().AddDate(0, 0, -7))
+	db.Model(&opp).Update("start_date", opp.Start_Date)
+	return opp
+}
+
+func logTestHours(router *gin.Engine, applicationID uint, daysAgo int, hours float64) (int, models.TimeEntryResponse) {
+	w := sendJSON(router, "POST", fmt.Sprintf("/applications/%d/hours", applicationID), models.TimeEntryCreateRequest{
+		Work_Date: models.CustomDate(time.Now().AddDate(0, 0, -daysAgo)),
+		Hours:     hours,
+		Note:      "Sorted donations",
+	})
+
+	var response models.TimeEntryResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return w.Code, response
+}
+
+func TestLogAndReviewHours(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForTimeEntries(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createStartedTestOpportunity(db)
+	volunteers := createCapacityTestVolunteers(db, 1)
+	application := applyForOpportunity(t, router, volunteers[0].ID, opp.ID)
+
+	// Hours can only be logged once the application is accepted
+	code, _ := logTestHours(router, application.ID, 1, 3)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, application.ID, models.ApplicationAccepted))
+
+	code, entry := logTestHours(router, application.ID, 1, 3)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.TimeEntryPending, entry.Status)
+
+	// Hours must be positive, and must not be logged ahead of time or before the opportunity started
+	code, _ = logTestHours(router, application.ID, 1, 0)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	code, _ = logTestHours(router, application.ID, -1, 3)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	code, _ = logTestHours(router, application.ID, 30, 3)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	// A dispute needs a reason
+	w := sendJSON(router, "POST", fmt.Sprintf("/hours/%d/dispute", entry.ID), nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = sendJSON(router, "POST", fmt.Sprintf("/hours/%d/dispute", entry.ID), models.TimeEntryReviewRequest{Note: "The shift was 2.5 hours"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &entry)
+	assert.Equal(t, models.TimeEntryDisputed, entry.Status)
+	assert.Equal(t, "The shift was 2.5 hours", entry.Review_Note)
+
+	// Correcting a disputed entry sends it back for review
+	w = sendJSON(router, "PUT", fmt.Sprintf("/hours/%d", entry.ID), map[string]interface{}{"hours": 2.5})
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &entry)
+	assert.Equal(t, models.TimeEntryPending, entry.Status)
+	assert.Equal(t, 2.5, entry.Hours)
+
+	w = sendJSON(router, "POST", fmt.Sprintf("/hours/%d/approve", entry.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &entry)
+	assert.Equal(t, models.TimeEntryApproved, entry.Status)
+	assert.NotNil(t, entry.Reviewed_At)
+
+	// Approved entries are final
+	w = sendJSON(router, "PUT", fmt.Sprintf("/hours/%d", entry.ID), map[string]interface{}{"hours": 8})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = sendJSON(router, "POST", fmt.Sprintf("/hours/%d/dispute", entry.ID), models.TimeEntryReviewRequest{Note: "Changed my mind"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = sendJSON(router, "GET", fmt.Sprintf("/applications/%d/hours", application.ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var entries []models.TimeEntryResponse
+	json.Unmarshal(w.Body.Bytes(), &entries)
+	assert.Len(t, entries, 1)
+}
+
+func TestVolunteerStatsSplitHours(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForTimeEntries(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createStartedTestOpportunity(db)
+	volunteers := createCapacityTestVolunteers(db, 1)
+	application := applyForOpportunity(t, router, volunteers[0].ID, opp.ID)
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, application.ID, models.ApplicationAccepted))
+
+	_, approved := logTestHours(router, application.ID, 2, 3)
+	sendJSON(router, "POST", fmt.Sprintf("/hours/%d/approve", approved.ID), nil)
+	logTestHours(router, application.ID, 1, 1.5)
+
+	// Accepted jobs count before the work is done
+	w := sendJSON(router, "GET", fmt.Sprintf("/volunteers/%d/stats", volunteers[0].ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var stats models.VolunteerStatsResponse
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	assert.Equal(t, int64(1), stats.Total_Jobs)
+	assert.Equal(t, int64(opp.Hours_Required), stats.Estimated_Hours)
+
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, application.ID, models.ApplicationCompleted))
+
+	w = sendJSON(router, "GET", fmt.Sprintf("/volunteers/%d/stats", volunteers[0].ID), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	stats = models.VolunteerStatsResponse{}
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	assert.Equal(t, int64(1), stats.Total_Jobs)
+	assert.Equal(t, 3.0, stats.Verified_Hours)
+	assert.Equal(t, 1.5, stats.Pending_Hours)
+	assert.Equal(t, 0.0, stats.Disputed_Hours)
+	assert.Equal(t, int64(opp.Hours_Required), stats.Estimated_Hours)
+}
+
+func TestLoggedHoursLimits(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterForTimeEntries(db)
+	defer cleanupCapacityTest(db)
+
+	opp := createStartedTestOpportunity(db)
+	other := createStartedTestOpportunity(db)
+	volunteers := createCapacityTestVolunteers(db, 1)
+	application := applyForOpportunity(t, router, volunteers[0].ID, opp.ID)
+	otherApplication := applyForOpportunity(t, router, volunteers[0].ID, other.ID)
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, application.ID, models.ApplicationAccepted))
+	assert.Equal(t, http.StatusOK, setApplicationStatus(router, otherApplication.ID, models.ApplicationAccepted))
+
+	// A day has 24 hours, however many entries they are split into
+	code, _ := logTestHours(router, application.ID, 1, 20)
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = logTestHours(router, application.ID, 1, 5)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	code, entry := logTestHours(router, application.ID, 1, 4)
+	assert.Equal(t, http.StatusOK, code)
+
+	// and however many opportunities they are worked on
+	code, _ = logTestHours(router, otherApplication.ID, 1, 1)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	code, _ = logTestHours(router, otherApplication.ID, 2, 1)
+	assert.Equal(t, http.StatusOK, code)
+
+	// Corrections replace the hours of the entry they correct
+	w := sendJSON(router, "PUT", fmt.Sprintf("/hours/%d", entry.ID), map[string]interface{}{"hours": 5})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = sendJSON(router, "PUT", fmt.Sprintf("/hours/%d", entry.ID), map[string]interface{}{"hours": 3})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Hours cannot be logged after the opportunity ended
+	db.Model(&opp).Update("end_date", models.CustomDate(time.Now().AddDate(0, 0, -3)))
+	code, _ = logTestHours(router, application.ID, 2, 1)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	code, _ = logTestHours(router, application.ID, 4, 1)
+	assert.Equal(t, http.StatusOK, code)
+}
@@ -5,59 +5,124 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/middleware"
 	"github.com/prathamrao021/HelperHub/models"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+// Users are platform admin accounts. They are managed by other admins under /admin/users.
+
 // createUser godoc
-// @Summary Create a new user
-// @Description Create a new user with the provided details
-// @Tags users
+// @Summary Create a new admin account
+// @Description Create a new platform admin account with the provided details
+// @Tags admin
 // @Accept json
 // @Produce json
-// @Param user body models.User true "User data"
-// @Success 200 {object} models.User
-// @Router /users/create [post]
-func createUser(c *gin.Context, db *gorm.DB) {
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+// @Param user body models.UserCreateRequest true "User data"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/users [post]
+func createUser(c *gin.Context, db *gorm.DB, bcryptCost int) {
+	var request models.UserCreateRequest
+	if !bindJSON(c, &request) {
 		return
 	}
 
 	// Hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password_Hash), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcryptCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		middleware.RespondProblem(c, http.StatusInternalServerError, "Failed to hash password")
 		return
 	}
-	user.Password_Hash = string(hashedPassword)
-	user.Created_At = time.Now()
-	user.Updated_At = time.Now()
+
+	user := models.User{
+		Email:         normalizeEmail(request.Email),
+		Password_Hash: string(hashedPassword),
+		Full_Name:     request.Full_Name,
+		Role:          auth.RoleAdmin,
+		Created_At:    time.Now(),
+		Updated_At:    time.Now(),
+	}
 
 	if err := db.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewUserResponse(user))
+}
+
+// getUsers godoc
+// @Summary List admin accounts
+// @Description Retrieve all platform admin accounts. Sortable and filterable by id, email, full_name, role and created_at.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param sort query string false "Comma-separated fields to sort by, - for descending"
+// @Param filter query []string false "field:op:value, with op one of eq, ne, lt, lte, gt, gte, in and like" collectionFormat(multi)
+// @Param limit query int false "Page size, 20 by default and at most 100"
+// @Param offset query int false "Number of items to skip"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Success 200 {object} listing.Page{data=[]models.UserResponse}
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/users [get]
+func getUsers(c *gin.Context, db *gorm.DB) {
+	options, ok := parseListOptions(c, userListSpec)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	var users []models.User
+	total, err := options.Find(db.Model(&models.User{}), &users)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, options.Page(models.NewUserResponses(users), total, c.Request.URL))
 }
 
 // deleteUser godoc
-// @Summary Delete an existing user
-// @Description Delete an existing user by username
-// @Tags users
+// @Summary Delete an admin account
+// @Description Delete a platform admin account by ID. Admins cannot delete their own account.
+// @Tags admin
 // @Accept json
 // @Produce json
-// @Param username path string true "Username"
-// @Success 200 {object} models.User
-// @Router /users/delete/{username} [delete]
+// @Param id path uint true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/users/{id} [delete]
 func deleteUser(c *gin.Context, db *gorm.DB) {
-	username := c.Param("username")
+	id := c.Param("id")
+	var user models.User
+
+	if err := db.Where("id = ?", id).First(&user).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	if principal, ok := middleware.CurrentPrincipal(c); ok && principal.ID == user.ID {
+		middleware.RespondProblem(c, http.StatusBadRequest, "You cannot delete your own account")
+		return
+	}
 
-	if err := db.Where("username = ?", username).Delete(&models.User{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := revokeRefreshTokens(tx, user.ID, auth.RoleAdmin); err != nil {
+			return err
+		}
+		return tx.Delete(&user).Error
+	}); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -65,70 +130,114 @@ func deleteUser(c *gin.Context, db *gorm.DB) {
 }
 
 // updateUser godoc
-// @Summary Update an existing user
-// @Description Update an existing user with the provided details
-// @Tags users
+// @Summary Update an admin account
+// @Description Update a platform admin account with the provided details
+// @Tags admin
 // @Accept json
 // @Produce json
-// @Param username path string true "Username"
-// @Param user body models.User true "User data"
-// @Success 200 {object} models.User
-// @Router /users/update/{username} [put]
-func updateUser(c *gin.Context, db *gorm.DB) {
-	username := c.Param("username")
+// @Param id path uint true "User ID"
+// @Param user body models.UserUpdateRequest true "User data"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/users/{id} [put]
+func updateUser(c *gin.Context, db *gorm.DB, bcryptCost int) {
+	id := c.Param("id")
 	var user models.User
 
-	if err := db.Where("username = ?", username).First(&user).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	if err := db.Where("id = ?", id).First(&user).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "User not found")
 		return
 	}
 
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var request models.UserUpdateRequest
+	if !bindJSON(c, &request) {
 		return
 	}
 
-	// Hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password_Hash), bcrypt.DefaultCost)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
-		return
+	if request.Email != nil {
+		user.Email = normalizeEmail(*request.Email)
+	}
+	if request.Full_Name != nil {
+		user.Full_Name = *request.Full_Name
 	}
-	user.Password_Hash = string(hashedPassword)
+	if request.Password != nil && *request.Password != "" {
+		// Hash the password
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*request.Password), bcryptCost)
+		if err != nil {
+			middleware.RespondProblem(c, http.StatusInternalServerError, "Failed to hash password")
+			return
+		}
+		user.Password_Hash = string(hashedPassword)
+	}
+	user.Updated_At = time.Now()
 
 	if err := db.Save(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "User data updated successfully"})
+	c.JSON(http.StatusOK, models.NewUserResponse(user))
 }
 
 // getUser godoc
-// @Summary Get user data
-// @Description Get user data with the provided username
-// @Tags users
+// @Summary Get an admin account
+// @Description Get a platform admin account by ID
+// @Tags admin
 // @Accept json
 // @Produce json
-// @Param username path string true "Username"
-// @Success 200 {object} models.User
-// @Router /users/get/{username} [get]
+// @Param id path uint true "User ID"
+// @Success 200 {object} models.UserResponse
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
+// @Router /admin/users/{id} [get]
 func getUser(c *gin.Context, db *gorm.DB) {
-	username := c.Param("username")
+	id := c.Param("id")
 	var user models.User
 
-	if err := db.Where("username = ?", username).First(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := db.Where("id = ?", id).First(&user).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "User not found")
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "User Data Sent.", "data": user})
+
+	c.JSON(http.StatusOK, models.NewUserResponse(user))
 }
 
-// func SetupRoutes(router *gin.Engine, db *gorm.DB) {
-// 	// Routes for user management
-// 	userRouter := router.Group("/users")
-// 	userRouter.POST("/create", func(c *gin.Context) { createUser(c, db) })
-// 	userRouter.DELETE("/delete/:username", func(c *gin.Context) { deleteUser(c, db) })
-// 	userRouter.PUT("/update/:username", func(c *gin.Context) { updateUser(c, db) })
-// 	userRouter.GET("/get/:username", func(c *gin.Context) { getUser(c, db) })
-// }
+// loginAdmin godoc
+// @Summary Login an admin
+// @Description Login a platform admin with the provided credentials and issue an access token and a refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body models.LoginRequest true "Login credentials"
+// @Success 200 {object} models.LoginResponse
+// @Router /login/admin [post]
+func loginAdmin(c *gin.Context, db *gorm.DB, tokens *auth.TokenManager) {
+	var credentials models.LoginRequest
+	if !bindJSON(c, &credentials) {
+		return
+	}
+
+	var user models.User
+	if err := db.Where("email = ? AND role = ?", normalizeEmail(credentials.Email), auth.RoleAdmin).First(&user).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusUnauthorized, "Invalid email")
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password_Hash), []byte(credentials.Password)); err != nil {
+		middleware.RespondProblem(c, http.StatusUnauthorized, "Invalid password")
+		return
+	}
+
+	session, err := issueSession(db, tokens, auth.Principal{ID: user.ID, Email: user.Email, Role: auth.RoleAdmin})
+	if err != nil {
+		middleware.RespondProblem(c, http.StatusInternalServerError, "Failed to create session")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LoginResponse{SessionResponse: session, User: models.NewUserResponse(user)})
+}
@@ -0,0 +1,100 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/internal/validation"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"github.com/prathamrao021/HelperHub/models"
+)
+
+// Request bodies are checked against the binding tags of the request types, with the custom
+// rules of the validation package, when they are bound
+func init() {
+	if err := validation.Register(binding.Validator.Engine().(*validator.Validate)); err != nil {
+		panic("Failed to register validation rules: " + err.Error())
+	}
+}
+
+// bindJSON binds the JSON request body to the request and validates it. It writes the error
+// response itself and reports false when the body cannot be used: 400 when it is not JSON or a
+// field has the wrong type, and 422 listing every field that failed validation.
+func bindJSON(c *gin.Context, request interface{}) bool {
+	fields, ok := decodeJSON(c, request)
+	return ok && !respondInvalidFields(c, fields)
+}
+
+// decodeJSON binds the JSON request body to the request like bindJSON, but returns the fields
+// that failed validation instead of responding with them, so that handlers can add the
+// failures of their own checks before they respond. It reports false after responding to a
+// body that cannot be decoded.
+func decodeJSON(c *gin.Context, request interface{}) ([]validation.FieldError, bool) {
+	err := c.ShouldBindJSON(request)
+	if err == nil {
+		return nil, true
+	}
+
+	var validationErrors validator.ValidationErrors
+	var typeError *json.UnmarshalTypeError
+	fields, ok := validation.FieldErrors(err)
+	switch {
+	case ok && errors.As(err, &validationErrors):
+		return fields, true
+	case ok:
+		middleware.RespondFieldProblem(c, http.StatusBadRequest, "The request body has a field of the wrong type", fields)
+	case errors.As(err, &typeError):
+		middleware.RespondProblem(c, http.StatusBadRequest, "A value in the request body "+validation.TypeMessage(typeError.Type))
+	default:
+		middleware.RespondProblem(c, http.StatusBadRequest, "The request body is not valid JSON")
+	}
+	return nil, false
+}
+
+// respondInvalidFields responds with the fields of the request body that failed validation,
+// and reports whether there were any
+func respondInvalidFields(c *gin.Context, fields []validation.FieldError) bool {
+	if len(fields) == 0 {
+		return false
+	}
+	middleware.RespondFieldProblem(c, http.StatusUnprocessableEntity, "The request body failed validation", fields)
+	return true
+}
+
+// normalizeEmail returns an email the way accounts are stored with it, in lower case, so that
+// emails that only differ in case name the same account
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// unknownCategory returns the error of a field that names a category that is not in the
+// categories table, if it does
+func unknownCategory(ctx context.Context, categories store.CategoryStore, field, name string) ([]validation.FieldError, error) {
+	_, err := categories.GetByName(ctx, name)
+	if errors.Is(err, store.ErrNotFound) {
+		return []validation.FieldError{{Field: field, Message: fmt.Sprintf("%q is not a category", name)}}, nil
+	}
+	return nil, err
+}
+
+// unknownCategories returns an error for each category in the list that is not in the
+// categories table
+func unknownCategories(ctx context.Context, categories store.CategoryStore, field string, names models.StringList) ([]validation.FieldError, error) {
+	var fields []validation.FieldError
+	for i, name := range names {
+		unknown, err := unknownCategory(ctx, categories, fmt.Sprintf("%s[%d]", field, i), name)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, unknown...)
+	}
+	return fields, nil
+}
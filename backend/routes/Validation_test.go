@@ -0,0 +1,114 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/prathamrao021/HelperHub/internal/validation"
+	"github.com/prathamrao021/HelperHub/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// problemFields returns the code of a problem response and the fields it lists
+func problemFields(t *testing.T, body []byte) (string, []validation.FieldError) {
+	var problem middleware.Problem
+	require.NoError(t, json.Unmarshal(body, &problem))
+	return problem.Code, problem.Errors
+}
+
+func TestCreateVolunteerValidation(t *testing.T) {
+	db := setupTestDBForVolunteer()
+	router := setupRouterForVolunteer(db)
+	defer cleanupTestVolunteers(db)
+
+	// Every invalid field is reported at once, along with the categories that do not exist
+	w := sendJSON(router, "POST", "/volunteers/create", map[string]interface{}{
+		"email":         "not-an-email",
+		"phone":         "call me",
+		"category_list": []string{"Education", "Juggling"},
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	code, fields := problemFields(t, w.Body.Bytes())
+	assert.Equal(t, middleware.CodeValidationFailed, code)
+	assert.Equal(t, []validation.FieldError{
+		{Field: "email", Message: "must be a valid email address"},
+		{Field: "password", Message: "is required"},
+		{Field: "phone", Message: "must be a phone number of 7 to 15 digits"},
+		{Field: "category_list[1]", Message: `"Juggling" is not a category`},
+	}, fields)
+
+	// Fields of the wrong type are named too
+	w = sendJSON(router, "POST", "/volunteers/create", map[string]interface{}{"email": "new@volunteer.com", "available_hours": "ten"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	code, fields = problemFields(t, w.Body.Bytes())
+	assert.Equal(t, middleware.CodeBadRequest, code)
+	assert.Equal(t, []validation.FieldError{{Field: "available_hours", Message: "must be a whole number that is not negative"}}, fields)
+
+	var count int64
+	db.Table("volunteers").Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	// Categories are checked on updates as well
+	volunteer := createTestVolunteer(db)
+	w = sendJSON(router, "PUT", "/volunteers/update/"+volunteer.Email, map[string]interface{}{"category_list": []string{"Juggling"}})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	_, fields = problemFields(t, w.Body.Bytes())
+	assert.Equal(t, []validation.FieldError{{Field: "category_list[0]", Message: `"Juggling" is not a category`}}, fields)
+}
+
+func TestCreateOrganizationValidation(t *testing.T) {
+	db := setupTestDBForOrganization()
+	router := setupRouterForOrganization(db)
+	defer cleanupTestOrganizations(db)
+
+	w := sendJSON(router, "POST", "/organizations/create", map[string]interface{}{
+		"email":       "new@org.com",
+		"password":    "password123",
+		"phone":       "+1 (555) 123-4567",
+		"website_url": "www.example.com",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	_, fields := problemFields(t, w.Body.Bytes())
+	assert.Equal(t, []validation.FieldError{{Field: "website_url", Message: "must be a valid http or https URL"}}, fields)
+}
+
+func TestCreateOpportunityValidation(t *testing.T) {
+	db := setupTestDBOpportunity()
+	router := setupRouterOpportunity(db)
+	defer cleanupTestOpportunities(db)
+
+	w := sendJSON(router, "POST", "/opportunities/create", map[string]interface{}{
+		"organization_mail": "test@org.com",
+		"category":          "Juggling",
+		"title":             "Backwards Opportunity",
+		"start_date":        "2025-03-10",
+		"end_date":          "2025-03-01",
+	})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	_, fields := problemFields(t, w.Body.Bytes())
+	assert.Equal(t, []validation.FieldError{
+		{Field: "hours_required", Message: "must be at least 1"},
+		{Field: "end_date", Message: "must not be before start_date"},
+		{Field: "category", Message: `"Juggling" is not a category`},
+	}, fields)
+
+	// Dates that are not dates cannot be decoded
+	w = sendJSON(router, "POST", "/opportunities/create", map[string]interface{}{"start_date": "March 1"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// An update cannot move the end before the start it keeps
+	opportunity := createTestOpportunity(db)
+	path := fmt.Sprintf("/opportunities/update/%d", opportunity.ID)
+	w = sendJSON(router, "PUT", path, map[string]interface{}{"end_date": opportunity.Start_Date.ToTime().AddDate(0, 0, -1).Format("2006-01-02")})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	_, fields = problemFields(t, w.Body.Bytes())
+	assert.Equal(t, []validation.FieldError{{Field: "end_date", Message: "must not be before start_date"}}, fields)
+
+	w = sendJSON(router, "PUT", path, map[string]interface{}{"hours_required": 0, "category": "Health"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	_, fields = problemFields(t, w.Body.Bytes())
+	assert.Equal(t, []validation.FieldError{{Field: "hours_required", Message: "must be at least 1"}}, fields)
+}
@@ -1,10 +1,15 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/internal/geo"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/middleware"
 	"github.com/prathamrao021/HelperHub/models"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -16,32 +21,56 @@ import (
 // @Tags volunteers
 // @Accept json
 // @Produce json
-// @Param volunteer body models.Volunteer true "Volunteer data"
-// @Success 200 {object} models.Volunteer
+// @Param volunteer body models.VolunteerCreateRequest true "Volunteer data"
+// @Success 200 {object} models.VolunteerResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
 // @Router /volunteers/create [post]
-func createVolunteer(c *gin.Context, db *gorm.DB) {
-	var volunteer models.Volunteer
-	if err := c.ShouldBindJSON(&volunteer); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func createVolunteer(c *gin.Context, volunteers store.VolunteerStore, categories store.CategoryStore, geocoder geo.Geocoder, bcryptCost int) {
+	var request models.VolunteerCreateRequest
+	fields, ok := decodeJSON(c, &request)
+	if !ok {
+		return
+	}
+	unknown, err := unknownCategories(c.Request.Context(), categories, "category_list", request.Category_List)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if respondInvalidFields(c, append(fields, unknown...)) {
 		return
 	}
 
 	// Hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(volunteer.Password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcryptCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		middleware.RespondProblem(c, http.StatusInternalServerError, "Failed to hash password")
 		return
 	}
-	volunteer.Password = string(hashedPassword)
-	volunteer.Created_At = time.Now()
-	volunteer.Updated_At = time.Now()
 
-	if err := db.Create(&volunteer).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	volunteer := models.Volunteer{
+		Email:           normalizeEmail(request.Email),
+		Password:        string(hashedPassword),
+		Name:            request.Name,
+		Phone:           request.Phone,
+		Location:        request.Location,
+		Bio_Data:        request.Bio_Data,
+		Category_List:   request.Category_List,
+		Available_Hours: request.Available_Hours,
+		Created_At:      time.Now(),
+		Updated_At:      time.Now(),
+	}
+	if volunteer.Category_List == nil {
+		volunteer.Category_List = models.StringList{}
+	}
+	volunteer.Latitude, volunteer.Longitude = geocodeLocation(c.Request.Context(), geocoder, volunteer.Location)
+
+	if err := volunteers.Create(c.Request.Context(), &volunteer); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, volunteer)
+	c.JSON(http.StatusOK, models.NewVolunteerResponse(volunteer))
 }
 
 // deleteVolunteer godoc
@@ -51,25 +80,29 @@ func createVolunteer(c *gin.Context, db *gorm.DB) {
 // @Accept json
 // @Produce json
 // @Param volunteer_mail path string true "Email"
-// @Success 200 {object} models.Volunteer
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} middleware.Problem
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /volunteers/delete/{volunteer_mail} [delete]
-func deleteVolunteer(c *gin.Context, db *gorm.DB) {
-	mail := c.Param("volunteer_mail")
-	var volunteer models.Volunteer
+func deleteVolunteer(c *gin.Context, volunteers store.VolunteerStore) {
+	mail := normalizeEmail(c.Param("volunteer_mail"))
 
-	if err := db.Where("email = ?", mail).First(&volunteer).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	volunteer, err := volunteers.GetByEmail(c.Request.Context(), mail)
+	if errors.Is(err, store.ErrNotFound) {
+		middleware.RespondProblem(c, http.StatusNotFound, "Volunteer not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
 		return
-	} else {
-		if err := db.Where("email = ?", mail).Delete(&volunteer).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{"message": "Volunteer data deleted successfully"})
 	}
 
-	c.JSON(http.StatusBadRequest, gin.H{"message": "Volunteer data not found"})
-
+	if err := volunteers.Delete(c.Request.Context(), volunteer.ID); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Volunteer data deleted successfully"})
 }
 
 // updateVolunteer godoc
@@ -79,41 +112,78 @@ func deleteVolunteer(c *gin.Context, db *gorm.DB) {
 // @Accept json
 // @Produce json
 // @Param volunteer_mail path string true "Email"
-// @Param volunteer body models.Volunteer true "Volunteer data"
-// @Success 200 {object} models.Volunteer
+// @Param volunteer body models.VolunteerUpdateRequest true "Volunteer data"
+// @Success 200 {object} models.VolunteerResponse
+// @Failure 400 {object} middleware.Problem
+// @Failure 403 {object} middleware.Problem
+// @Failure 422 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /volunteers/update/{volunteer_mail} [put]
-func updateVolunteer(c *gin.Context, db *gorm.DB) {
-	mail := c.Param("volunteer_mail")
-	var volunteer models.Volunteer
+func updateVolunteer(c *gin.Context, volunteers store.VolunteerStore, categories store.CategoryStore, geocoder geo.Geocoder, bcryptCost int) {
+	mail := normalizeEmail(c.Param("volunteer_mail"))
 
-	if err := db.Where("email = ?", mail).First(&volunteer).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Volunteer not found"})
+	volunteer, err := volunteers.GetByEmail(c.Request.Context(), mail)
+	if err != nil {
+		middleware.RespondProblem(c, http.StatusNotFound, "Volunteer not found")
 		return
 	}
 
-	var updatedData map[string]interface{}
-	if err := c.ShouldBindJSON(&updatedData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var request models.VolunteerUpdateRequest
+	fields, ok := decodeJSON(c, &request)
+	if !ok {
+		return
+	}
+	if request.Category_List != nil {
+		unknown, err := unknownCategories(c.Request.Context(), categories, "category_list", *request.Category_List)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		fields = append(fields, unknown...)
+	}
+	if respondInvalidFields(c, fields) {
 		return
 	}
 
-	if password, exists := updatedData["password"]; exists && password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password.(string)), bcrypt.DefaultCost)
+	if request.Email != nil {
+		volunteer.Email = normalizeEmail(*request.Email)
+	}
+	if request.Name != nil {
+		volunteer.Name = *request.Name
+	}
+	if request.Phone != nil {
+		volunteer.Phone = *request.Phone
+	}
+	if request.Location != nil {
+		volunteer.Location = *request.Location
+		volunteer.Latitude, volunteer.Longitude = geocodeLocation(c.Request.Context(), geocoder, *request.Location)
+	}
+	if request.Bio_Data != nil {
+		volunteer.Bio_Data = *request.Bio_Data
+	}
+	if request.Category_List != nil {
+		volunteer.Category_List = *request.Category_List
+	}
+	if request.Available_Hours != nil {
+		volunteer.Available_Hours = *request.Available_Hours
+	}
+	if request.Password != nil && *request.Password != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*request.Password), bcryptCost)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			middleware.RespondProblem(c, http.StatusInternalServerError, "Failed to hash password")
 			return
 		}
-		updatedData["password"] = string(hashedPassword)
+		volunteer.Password = string(hashedPassword)
 	}
 
-	updatedData["updated_at"] = time.Now()
+	volunteer.Updated_At = time.Now()
 
-	if err := db.Model(&volunteer).Updates(updatedData).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := volunteers.Update(c.Request.Context(), &volunteer); err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, volunteer)
+	c.JSON(http.StatusOK, models.NewVolunteerResponse(volunteer))
 }
 
 // getVolunteer godoc
@@ -123,88 +193,133 @@ func updateVolunteer(c *gin.Context, db *gorm.DB) {
 // @Accept json
 // @Produce json
 // @Param volunteer_mail path string true "Email"
-// @Success 200 {object} models.Volunteer
+// @Success 200 {object} models.VolunteerResponse
+// @Failure 404 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /volunteers/get/{volunteer_mail} [get]
-func getVolunteer(c *gin.Context, db *gorm.DB) {
-	mail := c.Param("volunteer_mail")
-	var volunteer models.Volunteer
+func getVolunteer(c *gin.Context, volunteers store.VolunteerStore) {
+	mail := normalizeEmail(c.Param("volunteer_mail"))
 
-	if err := db.Where("email = ?", mail).First(&volunteer).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	volunteer, err := volunteers.GetByEmail(c.Request.Context(), mail)
+	if errors.Is(err, store.ErrNotFound) {
+		middleware.RespondProblem(c, http.StatusNotFound, "Volunteer not found")
+		return
+	}
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, volunteer)
+	c.JSON(http.StatusOK, models.NewVolunteerResponse(volunteer))
 }
 
 // loginVolunteer godoc
 // @Summary Login a volunteer
-// @Description Login a volunteer with the provided credentials
+// @Description Login a volunteer with the provided credentials and issue an access token and a refresh token
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param credentials body models.LoginRequest true "Login credentials"
-// @Success 200 {object} models.Volunteer
+// @Success 200 {object} models.LoginResponse
+// @Failure 403 {object} middleware.Problem
 // @Router /login/volunteer [post]
-func loginVolunteer(c *gin.Context, db *gorm.DB) {
+func loginVolunteer(c *gin.Context, db *gorm.DB, tokens *auth.TokenManager) {
 	var credentials models.LoginRequest
-	if err := c.ShouldBindJSON(&credentials); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &credentials) {
 		return
 	}
 
 	var volunteer models.Volunteer
-	if err := db.Where("email = ?", credentials.Email).First(&volunteer).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email"})
+	if err := db.Where("email = ?", normalizeEmail(credentials.Email)).First(&volunteer).Error; err != nil {
+		middleware.RespondProblem(c, http.StatusUnauthorized, "Invalid email")
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(volunteer.Password), []byte(credentials.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+		middleware.RespondProblem(c, http.StatusUnauthorized, "Invalid password")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": volunteer})
+	if volunteer.Suspended_At != nil {
+		middleware.RespondProblem(c, http.StatusForbidden, "Account suspended")
+		return
+	}
+
+	session, err := issueSession(db, tokens, auth.Principal{ID: volunteer.ID, Email: volunteer.Email, Role: auth.RoleVolunteer})
+	if err != nil {
+		middleware.RespondProblem(c, http.StatusInternalServerError, "Failed to create session")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LoginResponse{SessionResponse: session, User: models.NewVolunteerResponse(volunteer)})
 }
 
 // getVolunteerStats godoc
 // @Summary Retrieve the total number of jobs and hours worked for a volunteer
-// @Description Retrieve the total number of jobs and hours worked for a volunteer based on accepted applications
+// @Description Retrieve the number of jobs and the hours worked by a volunteer. Jobs are the applications that were accepted, whether the work
+// @Description is still to come (Accepted) or done (Completed). Verified hours were approved by the organization, pending and disputed hours
+// @Description are logged but not approved, and estimated hours add up the hours required by the opportunities of those jobs. Only the volunteer
+// @Description and admins can see them.
 // @Tags volunteers
 // @Accept json
 // @Produce json
 // @Param volunteer_id path uint true "Volunteer ID"
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} models.VolunteerStatsResponse
+// @Failure 403 {object} middleware.Problem
+// @Security BearerAuth
 // @Router /volunteers/{volunteer_id}/stats [get]
 func getVolunteerStats(c *gin.Context, db *gorm.DB) {
 	volunteerID := c.Param("volunteer_id")
 
 	var totalJobs int64
-	var totalHoursWorked int64
+	var estimatedHours int64
 
 	// Query to count the total number of jobs and sum the hours worked
 	if err := db.Table("applications").
 		Joins("join opportunities on applications.opportunity_id = opportunities.id").
-		Where("applications.volunteer_id = ? AND applications.status = ?", volunteerID, "Accepted").
+		Where("applications.volunteer_id = ? AND applications.status IN ?", volunteerID, models.ApprovedApplicationStatuses).
 		Count(&totalJobs).
 		Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	if err := db.Table("applications").
-		Select("SUM(opportunities.hours_required)").
+		Select("COALESCE(SUM(opportunities.hours_required), 0)").
 		Joins("join opportunities on applications.opportunity_id = opportunities.id").
-		Where("applications.volunteer_id = ? AND applications.status = ?", volunteerID, "Accepted").
-		Scan(&totalHoursWorked).
+		Where("applications.volunteer_id = ? AND applications.status IN ?", volunteerID, models.ApprovedApplicationStatuses).
+		Scan(&estimatedHours).
 		Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	// Sum the logged hours by review status
+	var logged []struct {
+		Status string
+		Hours  float64
+	}
+	if err := db.Table("time_entries").
+		Select("time_entries.status, SUM(time_entries.hours) AS hours").
+		Joins("join applications on time_entries.application_id = applications.id").
+		Where("applications.volunteer_id = ?", volunteerID).
+		Group("time_entries.status").
+		Scan(&logged).
+		Error; err != nil {
+		respondError(c, err)
+		return
+	}
+	hoursByStatus := map[string]float64{}
+	for _, l := range logged {
+		hoursByStatus[l.Status] = l.Hours
+	}
+
 	// Return the stats
-	c.JSON(http.StatusOK, gin.H{
-		"total_jobs":         totalJobs,
-		"total_hours_worked": totalHoursWorked,
+	c.JSON(http.StatusOK, models.VolunteerStatsResponse{
+		Total_Jobs:      totalJobs,
+		Verified_Hours:  hoursByStatus[models.TimeEntryApproved],
+		Pending_Hours:   hoursByStatus[models.TimeEntryPending],
+		Disputed_Hours:  hoursByStatus[models.TimeEntryDisputed],
+		Estimated_Hours: estimatedHours,
 	})
 }
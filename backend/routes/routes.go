@@ -2,63 +2,126 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/prathamrao021/HelperHub/internal/auth"
+	"github.com/prathamrao021/HelperHub/internal/config"
+	"github.com/prathamrao021/HelperHub/internal/geo"
+	"github.com/prathamrao021/HelperHub/internal/store"
+	"github.com/prathamrao021/HelperHub/middleware"
 	"gorm.io/gorm"
 )
 
-func SetupRoutes(router *gin.Engine, db *gorm.DB) {
+// SetupRoutes registers all API routes. Registration, login and token refresh are public;
+// every other route requires a valid access token. Mutating routes and the routes that read
+// applications additionally check that the caller owns the record (or is an admin).
+// The profile, category and opportunity CRUD routes, the admin lists, application reads and
+// the ownership checks go through stores. Applications are only written, and time entries only
+// read, with db, like every other route: application writes, opportunity updates, shifts,
+// attendance, hours and stats. Locations are geocoded with the given geocoder, and passwords
+// are hashed with the configured bcrypt cost.
+func SetupRoutes(router *gin.Engine, db *gorm.DB, stores store.Stores, cfg *config.Config, tokens *auth.TokenManager, geocoder geo.Geocoder) {
+	bcryptCost := cfg.Auth.BcryptCost
+	requireAuth := middleware.RequireAuth(tokens)
+	requireAdmin := middleware.RequireRole(auth.RoleAdmin)
 
-	// Routes for user management
-	userRouter := router.Group("/users")
-	userRouter.POST("/create", func(c *gin.Context) { createUser(c, db) })
-	userRouter.DELETE("/delete/:username", func(c *gin.Context) { deleteUser(c, db) })
-	userRouter.PUT("/update/:username", func(c *gin.Context) { updateUser(c, db) })
-	userRouter.GET("/get/:username", func(c *gin.Context) { getUser(c, db) })
+	// Routes for session management
+	authRouter := router.Group("/auth")
+	authRouter.POST("/refresh", func(c *gin.Context) { refreshSession(c, requestDB(c, db), tokens) })
+	authRouter.POST("/logout", func(c *gin.Context) { logoutSession(c, requestDB(c, db)) })
+
+	// Routes for platform administration
+	adminRouter := router.Group("/admin", requireAuth, requireAdmin)
+	adminRouter.POST("/users", func(c *gin.Context) { createUser(c, requestDB(c, db), bcryptCost) })
+	adminRouter.GET("/users", func(c *gin.Context) { getUsers(c, requestDB(c, db)) })
+	adminRouter.GET("/users/:id", func(c *gin.Context) { getUser(c, requestDB(c, db)) })
+	adminRouter.PUT("/users/:id", func(c *gin.Context) { updateUser(c, requestDB(c, db), bcryptCost) })
+	adminRouter.DELETE("/users/:id", func(c *gin.Context) { deleteUser(c, requestDB(c, db)) })
+	adminRouter.GET("/volunteers", func(c *gin.Context) { adminGetVolunteers(c, stores.Volunteers) })
+	adminRouter.POST("/volunteers/:id/suspend", func(c *gin.Context) { adminSuspendVolunteer(c, requestDB(c, db)) })
+	adminRouter.POST("/volunteers/:id/reinstate", func(c *gin.Context) { adminReinstateVolunteer(c, requestDB(c, db)) })
+	adminRouter.GET("/organizations", func(c *gin.Context) { adminGetOrganizations(c, stores.Organizations) })
+	adminRouter.POST("/organizations/:id/suspend", func(c *gin.Context) { adminSuspendOrganization(c, requestDB(c, db)) })
+	adminRouter.POST("/organizations/:id/reinstate", func(c *gin.Context) { adminReinstateOrganization(c, requestDB(c, db)) })
+	adminRouter.GET("/opportunities", func(c *gin.Context) { adminGetOpportunities(c, stores.Opportunities) })
+	adminRouter.POST("/opportunities/:id/hide", func(c *gin.Context) { adminHideOpportunity(c, stores.Opportunities) })
+	adminRouter.POST("/opportunities/:id/restore", func(c *gin.Context) { adminRestoreOpportunity(c, stores.Opportunities) })
+	adminRouter.GET("/applications", func(c *gin.Context) { getAllApplications(c, stores.Applications) })
+	router.POST("/login/admin", countLogins(auth.RoleAdmin), func(c *gin.Context) { loginAdmin(c, requestDB(c, db), tokens) })
 
 	// Routes for volunteer management
 	volunteerRouter := router.Group("/volunteers")
-	volunteerRouter.POST("/create", func(c *gin.Context) { createVolunteer(c, db) })
-	volunteerRouter.DELETE("/delete/:volunteer_mail", func(c *gin.Context) { deleteVolunteer(c, db) })
-	volunteerRouter.PUT("/update/:volunteer_mail", func(c *gin.Context) { updateVolunteer(c, db) })
-	volunteerRouter.GET("/get/:volunteer_mail", func(c *gin.Context) { getVolunteer(c, db) })
-	volunteerRouter.GET("/:volunteer_id/stats", func(c *gin.Context) { getVolunteerStats(c, db) })
-	router.POST("/login/volunteer", func(c *gin.Context) { loginVolunteer(c, db) })
+	volunteerRouter.POST("/create", func(c *gin.Context) { createVolunteer(c, stores.Volunteers, stores.Categories, geocoder, bcryptCost) })
+	volunteerRouter.DELETE("/delete/:volunteer_mail", requireAuth, requireSelf(auth.RoleVolunteer, "volunteer_mail", volunteerAccount(stores.Volunteers)), func(c *gin.Context) { deleteVolunteer(c, stores.Volunteers) })
+	volunteerRouter.PUT("/update/:volunteer_mail", requireAuth, requireSelf(auth.RoleVolunteer, "volunteer_mail", volunteerAccount(stores.Volunteers)), func(c *gin.Context) { updateVolunteer(c, stores.Volunteers, stores.Categories, geocoder, bcryptCost) })
+	volunteerRouter.GET("/get/:volunteer_mail", requireAuth, func(c *gin.Context) { getVolunteer(c, stores.Volunteers) })
+	volunteerRouter.GET("/:volunteer_id/stats", requireAuth, requireSelf(auth.RoleVolunteer, "volunteer_id", accountByID), func(c *gin.Context) { getVolunteerStats(c, requestDB(c, db)) })
+	volunteerRouter.GET("/:volunteer_id/recommendations", requireAuth, requireSelf(auth.RoleVolunteer, "volunteer_id", accountByID), func(c *gin.Context) { getVolunteerRecommendations(c, requestDB(c, db)) })
+	router.POST("/login/volunteer", countLogins(auth.RoleVolunteer), func(c *gin.Context) { loginVolunteer(c, requestDB(c, db), tokens) })
 
 	// Routes for organization management
 	organizationRouter := router.Group("/organizations")
-	organizationRouter.POST("/create", func(c *gin.Context) { createOrganization(c, db) })
-	organizationRouter.DELETE("/delete/:organization_mail", func(c *gin.Context) { deleteOrganization(c, db) })
-	organizationRouter.PUT("/update/:organization_mail", func(c *gin.Context) { updateOrganization(c, db) })
-	organizationRouter.GET("/get/:organization_mail", func(c *gin.Context) { getOrganization(c, db) })
-	router.POST("/login/organization", func(c *gin.Context) { loginOrganization(c, db) })
+	organizationRouter.POST("/create", func(c *gin.Context) { createOrganization(c, stores.Organizations, geocoder, bcryptCost) })
+	organizationRouter.DELETE("/delete/:organization_mail", requireAuth, requireSelf(auth.RoleOrganization, "organization_mail", organizationAccount(stores.Organizations)), func(c *gin.Context) { deleteOrganization(c, stores.Organizations) })
+	organizationRouter.PUT("/update/:organization_mail", requireAuth, requireSelf(auth.RoleOrganization, "organization_mail", organizationAccount(stores.Organizations)), func(c *gin.Context) { updateOrganization(c, stores.Organizations, geocoder, bcryptCost) })
+	organizationRouter.GET("/get/:organization_mail", requireAuth, func(c *gin.Context) { getOrganization(c, stores.Organizations) })
+	router.POST("/login/organization", countLogins(auth.RoleOrganization), func(c *gin.Context) { loginOrganization(c, requestDB(c, db), tokens) })
 
 	// Routes for category management
 	categoryRouter := router.Group("/categories")
-	categoryRouter.POST("/create", func(c *gin.Context) { CreateCategory(c, db) })
-	categoryRouter.GET("/get", func(c *gin.Context) { getCategories(c, db) })
+	categoryRouter.POST("/create", requireAuth, requireAdmin, func(c *gin.Context) { CreateCategory(c, stores.Categories) })
+	categoryRouter.GET("/get", func(c *gin.Context) { getCategories(c, stores.Categories) })
 
 	// Routes for opportunity management
 	opportunityRouter := router.Group("/opportunities")
-	opportunityRouter.POST("/create", func(c *gin.Context) { createOpportunity(c, db) })
-	opportunityRouter.DELETE("/delete/:id", func(c *gin.Context) { deleteOpportunity(c, db) })
-	opportunityRouter.PUT("/update/:id", func(c *gin.Context) { updateOpportunity(c, db) })
-	opportunityRouter.GET("/get/:id", func(c *gin.Context) { getOpportunity(c, db) })
-	opportunityRouter.GET("/organization/:organization_mail/expired", func(c *gin.Context) { getLastNExpiredOpportunitiesByOrganization(c, db) })
-	opportunityRouter.GET("/", func(c *gin.Context) { getOpportunitiesByOrganization(c, db) })
-	opportunityRouter.GET("/available", func(c *gin.Context) { getAvailableOpportunities(c, db) })
-	opportunityRouter.GET("/:opportunity_id", func(c *gin.Context) { getOpportunityWithStats(c, db) })
+	opportunityRouter.POST("/create", requireAuth, middleware.RequireRole(auth.RoleOrganization), func(c *gin.Context) {
+		createOpportunity(c, stores.Opportunities, stores.Organizations, stores.Categories, geocoder)
+	})
+	opportunityRouter.DELETE("/delete/:id", requireAuth, requireOpportunityOwner(stores.Opportunities, stores.Organizations, "id"), func(c *gin.Context) { deleteOpportunity(c, stores.Opportunities) })
+	opportunityRouter.PUT("/update/:id", requireAuth, requireOpportunityOwner(stores.Opportunities, stores.Organizations, "id"), func(c *gin.Context) { updateOpportunity(c, requestDB(c, db), stores.Categories, geocoder) })
+	opportunityRouter.GET("/get/:id", requireAuth, func(c *gin.Context) { getOpportunity(c, stores.Opportunities) })
+	opportunityRouter.GET("/organization/:organization_mail/expired", requireAuth, func(c *gin.Context) { getLastNExpiredOpportunitiesByOrganization(c, requestDB(c, db)) })
+	opportunityRouter.GET("/", requireAuth, func(c *gin.Context) { getOpportunitiesByOrganization(c, requestDB(c, db)) })
+	opportunityRouter.GET("/available", requireAuth, func(c *gin.Context) { getAvailableOpportunities(c, requestDB(c, db)) })
+	opportunityRouter.GET("/search", requireAuth, func(c *gin.Context) { searchOpportunities(c, requestDB(c, db)) })
+	opportunityRouter.GET("/:opportunity_id", requireAuth, func(c *gin.Context) { getOpportunityWithStats(c, requestDB(c, db), stores.Organizations) })
+	opportunityRouter.POST("/:opportunity_id/shifts", requireAuth, requireOpportunityOwner(stores.Opportunities, stores.Organizations, "opportunity_id"), func(c *gin.Context) { createShift(c, requestDB(c, db)) })
+	opportunityRouter.GET("/:opportunity_id/shifts", requireAuth, func(c *gin.Context) { getShifts(c, requestDB(c, db)) })
+	opportunityRouter.PUT("/:opportunity_id/shifts/:shift_id", requireAuth, requireOpportunityOwner(stores.Opportunities, stores.Organizations, "opportunity_id"), func(c *gin.Context) { updateShift(c, requestDB(c, db)) })
+	opportunityRouter.DELETE("/:opportunity_id/shifts/:shift_id", requireAuth, requireOpportunityOwner(stores.Opportunities, stores.Organizations, "opportunity_id"), func(c *gin.Context) { deleteShift(c, requestDB(c, db)) })
+	opportunityRouter.POST("/:opportunity_id/attendance/codes", requireAuth, requireOpportunityOwner(stores.Opportunities, stores.Organizations, "opportunity_id"), func(c *gin.Context) { issueAttendanceCode(c, requestDB(c, db)) })
+	opportunityRouter.POST("/:opportunity_id/attendance/close", requireAuth, requireOpportunityOwner(stores.Opportunities, stores.Organizations, "opportunity_id"), func(c *gin.Context) { closeAttendance(c, requestDB(c, db)) })
+	opportunityRouter.GET("/:opportunity_id/attendance", requireAuth, requireOpportunityOwner(stores.Opportunities, stores.Organizations, "opportunity_id"), func(c *gin.Context) { getAttendance(c, requestDB(c, db)) })
 
 	// Routes for application management
 	applicationRouter := router.Group("/applications")
-	applicationRouter.POST("/", func(c *gin.Context) { createApplication(c, db) })
-	applicationRouter.GET("/", func(c *gin.Context) { getAllApplications(c, db) })
-	applicationRouter.GET("/:id", func(c *gin.Context) { getApplicationByID(c, db) })
-	// applicationRouter.GET("/volunteer/:volunteer_id", func(c *gin.Context) { getApplicationsByVolunteerID(c, db) })
-	// applicationRouter.GET("/opportunity/:opportunity_id", func(c *gin.Context) { getApplicationsByOpportunityID(c, db) })
-	applicationRouter.GET("/status/:status", func(c *gin.Context) { getApplicationsByStatus(c, db) })
-	applicationRouter.PUT("/:id", func(c *gin.Context) { updateApplication(c, db) })
-	applicationRouter.DELETE("/:id", func(c *gin.Context) { deleteApplication(c, db) })
-	applicationRouter.GET("/volunteer/:volunteer_id/approved", func(c *gin.Context) { getLastNApprovedApplications(c, db) })
-	applicationRouter.GET("/volunteer/:volunteer_id/completed", func(c *gin.Context) { getLastNAcceptedOpportunitiesForVolunteer(c, db) })
-	applicationRouter.GET("/volunteer/:volunteer_id", func(c *gin.Context) { getApplicationsByVolunteerWithDetails(c, db) })
-	applicationRouter.GET("/opportunity/:opportunity_id", func(c *gin.Context) { getApplicationsByOpportunityWithVolunteerDetails(c, db) })
+	applicationRouter.POST("/", requireAuth, middleware.RequireRole(auth.RoleVolunteer), func(c *gin.Context) { createApplication(c, requestDB(c, db)) })
+	applicationRouter.GET("/:id", requireAuth, requireApplicationAccess(stores.Applications, stores.Opportunities, stores.Organizations, false), func(c *gin.Context) { getApplicationByID(c, stores.Applications) })
+	applicationRouter.GET("/:id/history", requireAuth, requireApplicationAccess(stores.Applications, stores.Opportunities, stores.Organizations, false), func(c *gin.Context) { getApplicationStatusHistory(c, requestDB(c, db)) })
+	applicationRouter.POST("/:id/hours", requireAuth, requireApplicationAccess(stores.Applications, stores.Opportunities, stores.Organizations, true), func(c *gin.Context) { logHours(c, requestDB(c, db)) })
+	applicationRouter.GET("/:id/hours", requireAuth, requireApplicationAccess(stores.Applications, stores.Opportunities, stores.Organizations, false), func(c *gin.Context) { getApplicationHours(c, requestDB(c, db)) })
+	// applicationRouter.GET("/volunteer/:volunteer_id", func(c *gin.Context) { getApplicationsByVolunteerID(c, requestDB(c, db)) })
+	// applicationRouter.GET("/opportunity/:opportunity_id", func(c *gin.Context) { getApplicationsByOpportunityID(c, requestDB(c, db)) })
+	applicationRouter.GET("/status/:status", requireAuth, requireAdmin, func(c *gin.Context) { getApplicationsByStatus(c, stores.Applications) })
+	applicationRouter.PUT("/:id", requireAuth, requireApplicationAccess(stores.Applications, stores.Opportunities, stores.Organizations, false), func(c *gin.Context) { updateApplication(c, requestDB(c, db)) })
+	applicationRouter.DELETE("/:id", requireAuth, requireApplicationAccess(stores.Applications, stores.Opportunities, stores.Organizations, true), func(c *gin.Context) { deleteApplication(c, requestDB(c, db)) })
+	applicationRouter.GET("/volunteer/:volunteer_id/approved", requireAuth, requireSelf(auth.RoleVolunteer, "volunteer_id", accountByID), func(c *gin.Context) { getLastNApprovedApplications(c, requestDB(c, db)) })
+	applicationRouter.GET("/volunteer/:volunteer_id/completed", requireAuth, requireSelf(auth.RoleVolunteer, "volunteer_id", accountByID), func(c *gin.Context) { getLastNAcceptedOpportunitiesForVolunteer(c, requestDB(c, db)) })
+	applicationRouter.GET("/volunteer/:volunteer_id", requireAuth, requireSelf(auth.RoleVolunteer, "volunteer_id", accountByID), func(c *gin.Context) { getApplicationsByVolunteerWithDetails(c, requestDB(c, db)) })
+	applicationRouter.GET("/opportunity/:opportunity_id", requireAuth, requireOpportunityOwner(stores.Opportunities, stores.Organizations, "opportunity_id"), func(c *gin.Context) { getApplicationsByOpportunityWithVolunteerDetails(c, requestDB(c, db)) })
+
+	// Routes for volunteer attendance
+	attendanceRouter := router.Group("/attendance", requireAuth, middleware.RequireRole(auth.RoleVolunteer))
+	attendanceRouter.POST("/check-in", func(c *gin.Context) { checkIn(c, requestDB(c, db)) })
+	attendanceRouter.POST("/check-out", func(c *gin.Context) { checkOut(c, requestDB(c, db)) })
+
+	// Routes for reviewing logged hours
+	hoursRouter := router.Group("/hours", requireAuth)
+	hoursRouter.PUT("/:id", requireTimeEntryAccess(db, stores.Applications, stores.Opportunities, stores.Organizations, auth.RoleVolunteer), func(c *gin.Context) { updateTimeEntry(c, requestDB(c, db)) })
+	hoursRouter.POST("/:id/approve", requireTimeEntryAccess(db, stores.Applications, stores.Opportunities, stores.Organizations, auth.RoleOrganization), func(c *gin.Context) { approveTimeEntry(c, requestDB(c, db)) })
+	hoursRouter.POST("/:id/dispute", requireTimeEntryAccess(db, stores.Applications, stores.Opportunities, stores.Organizations, auth.RoleOrganization), func(c *gin.Context) { disputeTimeEntry(c, requestDB(c, db)) })
+}
+
+// requestDB returns db bound to the context of the request, so that queries are traced as part
+// of the request and stop when it is cancelled
+func requestDB(c *gin.Context, db *gorm.DB) *gorm.DB {
+	return db.WithContext(c.Request.Context())
 }
@@ -0,0 +1,131 @@
+package routes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prathamrao021/HelperHub/internal/config"
+	"github.com/prathamrao021/HelperHub/internal/database"
+	"github.com/prathamrao021/HelperHub/internal/migrate"
+	"github.com/prathamrao021/HelperHub/migrations"
+	"github.com/prathamrao021/HelperHub/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testBcryptCost is the cheapest bcrypt cost, to keep the tests fast
+const testBcryptCost = bcrypt.MinCost
+
+// testConfig returns the default settings with testBcryptCost
+func testConfig() *config.Config {
+	cfg := config.Default()
+	cfg.Auth.BcryptCost = testBcryptCost
+	return &cfg
+}
+
+// testSQLitePath is the file of the test database when the tests run on SQLite
+var testSQLitePath string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "helperhub")
+	if err != nil {
+		panic(err)
+	}
+	testSQLitePath = filepath.Join(dir, "helperhub_test.db")
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// testDatabase returns the database the server is configured with, with TEST_DATABASE_URL as
+// its connection string when it is set. Otherwise PostgreSQL tests use the configured database
+// with _test appended to its name, and SQLite tests a temporary file.
+func testDatabase() (config.Database, error) {
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return config.Database{}, err
+	}
+	database := cfg.Database
+	database.URL = os.Getenv("TEST_DATABASE_URL")
+	if database.Driver == config.DriverSQLite {
+		database.Path = testSQLitePath
+	} else {
+		database.Name += "_test"
+	}
+	return database, nil
+}
+
+// openTestDB connects to the test database with minimal logging
+func openTestDB() (*gorm.DB, error) {
+	cfg, err := testDatabase()
+	if err != nil {
+		return nil, err
+	}
+	return gorm.Open(database.Dialector(cfg), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	})
+}
+
+// resetTestDB empties the test database and migrates it to the latest schema version, the same
+// way "go run . migrate up" does for the real one
+func resetTestDB(db *gorm.DB) {
+	if database.IsSQLite(db) {
+		dropSQLiteTables(db)
+	} else {
+		db.Exec("DROP SCHEMA public CASCADE")
+		db.Exec("CREATE SCHEMA public")
+	}
+
+	migrator, err := migrate.New(db, migrations.For(db.Dialector.Name()))
+	if err != nil {
+		panic("Failed to load migrations: " + err.Error())
+	}
+	if _, err := migrator.Up(); err != nil {
+		panic("Failed to migrate test database: " + err.Error())
+	}
+}
+
+// testCategories are the categories the test volunteers and opportunities are in
+var testCategories = []string{"Education", "Environment", "Health", "Technology"}
+
+// createTestCategories adds testCategories to the database, since volunteers and opportunities
+// can only be put in categories that exist
+func createTestCategories(db *gorm.DB) {
+	for _, name := range testCategories {
+		if err := db.Create(&models.Category{Category: name, Created_At: time.Now()}).Error; err != nil {
+			panic("Failed to create test category: " + err.Error())
+		}
+	}
+}
+
+// dropSQLiteTables drops every table of a SQLite database. Foreign keys are turned off while
+// it does, on the one connection used for it, so that the tables can be dropped in any order.
+func dropSQLiteTables(db *gorm.DB) {
+	err := db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("PRAGMA foreign_keys = ON")
+
+		// Dropping a full-text table drops the tables it keeps its index in
+		var tables []string
+		if err := conn.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' " +
+			"ORDER BY sql LIKE 'CREATE VIRTUAL%' DESC").Scan(&tables).Error; err != nil {
+			return err
+		}
+		for _, table := range tables {
+			if err := conn.Exec(`DROP TABLE IF EXISTS "` + table + `"`).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		panic("Failed to empty test database: " + err.Error())
+	}
+}
@@ -63,6 +63,7 @@ export function AuthProvider({ children }: { children: ReactNode }) {
               ...response.data.user,  // Spread the nested user object
               userRole: "VOLUNTEER",
             };
+            storeSession(response.data);
           } else {
             const response = await api.post("/login/organization", {
               email,
@@ -74,6 +75,7 @@ export function AuthProvider({ children }: { children: ReactNode }) {
               ...response.data.user,  // Spread the nested user object
               userRole: "ORGANIZATION_ADMIN",
             };
+            storeSession(response.data);
           }
           console.log("User data which we get back from the server", userData);
           setUser(userData);
@@ -86,6 +88,15 @@ export function AuthProvider({ children }: { children: ReactNode }) {
         }
       };
     
+  const storeSession = (session: { access_token?: string; refresh_token?: string }) => {
+    if (session.access_token) {
+      localStorage.setItem("token", session.access_token);
+    }
+    if (session.refresh_token) {
+      localStorage.setItem("refresh_token", session.refresh_token);
+    }
+  };
+
   const updateUser = (user: any) => {
     setUser(user)
     localStorage.setItem("user", JSON.stringify(user))
@@ -139,8 +150,14 @@ export function AuthProvider({ children }: { children: ReactNode }) {
   }
 
   const logout = () => {
+    const refreshToken = localStorage.getItem("refresh_token")
+    if (refreshToken) {
+      api.post("/auth/logout", { refresh_token: refreshToken }).catch(() => {})
+    }
     setUser(null)
     localStorage.removeItem("user")
+    localStorage.removeItem("token")
+    localStorage.removeItem("refresh_token")
     // Redirect to home page
     window.location.href = "/"
   }
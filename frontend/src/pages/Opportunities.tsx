@@ -89,11 +89,11 @@ export function OpportunitiesPage() {
     //   setOpportunities(mockOpportunities)
     //   setLoading(false)
     // }, 800)
-    const response = api.get(`/opportunities/available`)
+    const response = api.get(`/opportunities/available?limit=100`)
     response
       .then(res => {
-        // Assuming the API returns an array of opportunities
-        setOpportunities(res.data)
+        // The API returns a page of opportunities in data
+        setOpportunities(res.data.data)
         setLoading(false)
       })
       .catch(error => {